@@ -0,0 +1,30 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"netiwash/pkg/payment"
+)
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func main() {
+	port := getEnv("FAKE_PAYMENT_PORT", "9090")
+	publicURL := getEnv("FAKE_PAYMENT_PUBLIC_URL", "http://localhost:"+port)
+	webhookURL := getEnv("FAKE_PAYMENT_WEBHOOK_URL", "http://localhost:8080/api/payments/webhook")
+	secret := getEnv("PAYMENT_WEBHOOK_SECRET", "dev-webhook-secret")
+
+	server := payment.NewFakeServer(publicURL, webhookURL, secret)
+
+	log.Printf("💳 Fake payment provider on :%s (API: %s/v3, webhooks -> %s)", port, publicURL, webhookURL)
+	if err := http.ListenAndServe(":"+port, server); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"netiwash/pkg/telegram"
+)
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func main() {
+	port := getEnv("FAKE_TELEGRAM_PORT", "9091")
+	token := getEnv("TELEGRAM_BOT_TOKEN", "dev-bot-token")
+
+	server := telegram.NewFakeServer(token)
+
+	log.Printf("✈️ Fake Telegram Bot API on :%s (set TELEGRAM_API_URL=http://localhost:%s)", port, port)
+	log.Printf("✈️ Write as a user: curl -d '{\"chat_id\":1,\"text\":\"/machines\"}' localhost:%s/fake/updates", port)
+	if err := http.ListenAndServe(":"+port, server); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	mqtt "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+)
+
+// Локальный MQTT-брокер для разработки телеметрии. С флагом -simulate
+// публикует в указанный топик показания мощности одного цикла стирки.
+func main() {
+	addr := flag.String("addr", ":1883", "адрес TCP-листенера")
+	topic := flag.String("simulate", "", "топик розетки для имитации цикла стирки (например plugs/washer1/power)")
+	watts := flag.Float64("watts", 1800, "мощность во время стирки, Вт")
+	cycle := flag.Duration("cycle", 2*time.Minute, "длительность имитируемого цикла")
+	interval := flag.Duration("interval", 5*time.Second, "период публикации показаний")
+	flag.Parse()
+
+	server := mqtt.New(&mqtt.Options{InlineClient: true})
+	if err := server.AddHook(new(auth.AllowHook), nil); err != nil {
+		log.Fatal(err)
+	}
+	if err := server.AddListener(listeners.NewTCP(listeners.Config{ID: "tcp", Address: *addr})); err != nil {
+		log.Fatal(err)
+	}
+
+	go func() {
+		if err := server.Serve(); err != nil {
+			log.Fatal(err)
+		}
+	}()
+	log.Printf("📡 Dev MQTT broker listening on %s", *addr)
+
+	if *topic != "" {
+		go simulate(server, *topic, *watts, *cycle, *interval)
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	<-sig
+	server.Close()
+}
+
+func simulate(server *mqtt.Server, topic string, watts float64, cycle, interval time.Duration) {
+	publish := func(w float64) {
+		payload := fmt.Sprintf(`{"power": %.1f}`, w)
+		if err := server.Publish(topic, []byte(payload), false, 0); err != nil {
+			log.Printf("publish error: %v", err)
+		}
+	}
+
+	log.Printf("🧺 Simulating wash cycle on %s: %.0f W for %s", topic, watts, cycle)
+	for end := time.Now().Add(cycle); time.Now().Before(end); time.Sleep(interval) {
+		publish(watts)
+	}
+	log.Printf("🧺 Cycle finished, publishing idle power")
+	for {
+		publish(0.5)
+		time.Sleep(interval)
+	}
+}
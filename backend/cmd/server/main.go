@@ -2,18 +2,28 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
 	"net/http"
 	"netiwash/internal/config"
+	"netiwash/internal/eventbus"
 	"netiwash/internal/handlers"
+	"netiwash/internal/leader"
 	"netiwash/internal/middleware"
+	"netiwash/internal/models"
+	"netiwash/internal/realtime"
 	"netiwash/internal/repository"
 	"netiwash/internal/service"
+	"netiwash/internal/telemetry"
 	"netiwash/pkg/database"
+	"netiwash/pkg/payment"
+	"netiwash/pkg/storage"
+	"netiwash/pkg/telegram"
 	"netiwash/pkg/utils"
 	"os"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 func main() {
@@ -28,9 +38,13 @@ func main() {
 		log.Printf("⚠️ Migration warning: %v", err)
 	}
 
-	emailService := utils.NewEmailService()
+	emailService, err := utils.NewEmailService()
+	if err != nil {
+		log.Fatalf("Email templates: %v", err)
+	}
 	userRepo := repository.NewUserRepository(dbPool)
-	authService := service.NewAuthService(userRepo, cfg.JWTSecret, emailService)
+	notificationPrefsRepo := repository.NewNotificationPreferencesRepository(dbPool)
+	authService := service.NewAuthService(userRepo, notificationPrefsRepo, cfg.JWTSecret, emailService)
 	authHandler := handlers.NewAuthHandler(authService)
 	emailHandler := handlers.NewEmailHandler(authService, emailService)
 	r := gin.Default()
@@ -38,7 +52,7 @@ func main() {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
 			return
@@ -50,20 +64,158 @@ func main() {
 		c.JSON(http.StatusOK, gin.H{
 			"status": "ok",
 			"db":     "connected",
+			"node":   cfg.NodeID,
 		})
 	})
 
+	var bus eventbus.Bus = eventbus.NewLocalBus()
+	if cfg.EventBus == "postgres" {
+		pgBus := eventbus.NewPostgresBus(dbPool)
+		pgBus.Start(context.Background())
+		bus = pgBus
+	}
+	hub := realtime.NewHub()
+	bus.Subscribe(hub.Publish)
+
 	machineRepo := repository.NewMachineRepository(dbPool)
-	machineHandler := handlers.NewMachineHandler(machineRepo)
 	bookingRepo := repository.NewBookingRepository(dbPool)
-	bookingService := service.NewBookingService(bookingRepo)
-	bookingHandler := handlers.NewBookingHandler(bookingService)
+
+	recommendationService := service.NewRecommendationService(bookingRepo, machineRepo)
+	recommendationHandler := handlers.NewRecommendationHandler(recommendationService)
 
 	pushRepo := repository.NewPushRepository(dbPool)
-	notificationService := service.NewNotificationService(pushRepo, bookingRepo)
+	deliveryRepo := repository.NewNotificationDeliveryRepository(dbPool)
+	inboxRepo := repository.NewInboxRepository(dbPool)
+	vapidKeys, err := loadVAPIDKeys(cfg, dbPool)
+	if err != nil {
+		log.Fatalf("VAPID keys: %v", err)
+	}
+	notificationService := service.NewNotificationService(pushRepo, bookingRepo, notificationPrefsRepo, deliveryRepo, inboxRepo, bus, vapidKeys, cfg.ReminderOffsets)
+	// До учёта ключей сервер подписывал пуши парой из VAPID_PUBLIC_KEY, а без
+	// неё — встроенной парой для разработки.
+	legacyVAPIDKey := cfg.VAPIDPublicKey
+	if legacyVAPIDKey == "" {
+		legacyVAPIDKey = service.DevVAPIDPublicKey
+	}
+	if err := notificationService.AssignLegacySubscriptions(context.Background(), legacyVAPIDKey); err != nil {
+		log.Printf("⚠️ %v", err)
+	}
+	notificationService.RegisterChannel(models.ChannelEmail, service.NewEmailChannel(userRepo, notificationPrefsRepo, emailService))
+	bus.SubscribeLocal(notificationService.HandleEvent)
 	notificationHandler := handlers.NewNotificationHandler(notificationService)
 
-	notificationService.StartWorker(context.Background())
+	var fileStorage storage.Storage = storage.NewLocalStorage(cfg.UploadDir)
+	if cfg.StorageBackend == "s3" {
+		fileStorage, err = storage.NewS3Storage(storage.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			PathStyle: cfg.S3PathStyle,
+		})
+		if err != nil {
+			log.Fatalf("Invalid S3 storage config: %v", err)
+		}
+	}
+
+	machineService := service.NewMachineService(machineRepo, bookingRepo, fileStorage, bus)
+	machineHandler := handlers.NewMachineHandler(machineService)
+
+	bookingService := service.NewBookingService(bookingRepo, machineRepo, machineService, bus)
+	bookingHandler := handlers.NewBookingHandler(bookingService)
+	bookingMailer := service.NewBookingMailer(emailService, userRepo, machineRepo, notificationService, cfg.PublicURL)
+	bus.SubscribeLocal(bookingMailer.HandleEvent)
+	eventHandler := handlers.NewEventHandler(hub)
+
+	var telegramClient *telegram.Client
+	if cfg.TelegramBotToken != "" {
+		telegramClient = telegram.NewClient(cfg.TelegramAPIURL, cfg.TelegramBotToken)
+	}
+	telegramRepo := repository.NewTelegramRepository(dbPool)
+	telegramService := service.NewTelegramService(telegramClient, telegramRepo, machineService, bookingService, cfg.TelegramBotUsername)
+	telegramHandler := handlers.NewTelegramHandler(telegramService)
+	notificationService.RegisterChannel(models.ChannelTelegram, telegramService)
+
+	announcementRepo := repository.NewAnnouncementRepository(dbPool)
+	announcementService := service.NewAnnouncementService(announcementRepo, notificationService)
+	announcementHandler := handlers.NewAnnouncementHandler(announcementService)
+
+	ticketRepo := repository.NewTicketRepository(dbPool)
+	ticketService := service.NewTicketService(ticketRepo, machineService, notificationService, fileStorage)
+	ticketHandler := handlers.NewTicketHandler(ticketService)
+
+	qrKeys, err := utils.ParseSigningKeys(cfg.QRSigningKeys)
+	if err != nil {
+		log.Fatalf("Invalid QR_SIGNING_KEYS: %v", err)
+	}
+	if len(qrKeys) == 0 {
+		if !cfg.DevMode() {
+			log.Fatalf("QR_SIGNING_KEYS is empty, set a key of its own (id:secret) or APP_ENV=development")
+		}
+		log.Println("⚠️ No QR_SIGNING_KEYS, using the development key")
+		qrKeys = []utils.SigningKey{{ID: "dev", Secret: "netiwash-dev-qr-key"}}
+	}
+	linkSigner, err := utils.NewLinkSigner(qrKeys)
+	if err != nil {
+		log.Fatalf("Failed to init QR signer: %v", err)
+	}
+	notificationService.EnableActions(linkSigner, bookingService)
+	checkInService := service.NewCheckInService(linkSigner, machineRepo, bookingRepo, machineService, bookingService, cfg.PublicURL)
+	checkInHandler := handlers.NewCheckInHandler(checkInService)
+
+	paymentRepo := repository.NewPaymentRepository(dbPool)
+	walletRepo := repository.NewWalletRepository(dbPool)
+	paymentProvider := payment.NewYooKassaProvider(cfg.PaymentAPIURL, cfg.PaymentShopID, cfg.PaymentSecretKey)
+	paymentService := service.NewPaymentService(paymentRepo, walletRepo, userRepo, paymentProvider, cfg.PaymentWebhookSecret, cfg.PaymentReturnURL, cfg.CreditPrice)
+	paymentHandler := handlers.NewPaymentHandler(paymentService)
+
+	telemetryService := service.NewTelemetryService(machineRepo, machineService, notificationService, models.StatusSourceTelemetry)
+
+	deviceRepo := repository.NewDeviceRepository(dbPool)
+	deviceTelemetryService := service.NewTelemetryService(machineRepo, machineService, notificationService, models.StatusSourceDevice)
+	deviceService := service.NewDeviceService(deviceRepo, machineRepo, machineService, deviceTelemetryService, ticketService)
+	deviceHandler := handlers.NewDeviceHandler(deviceService)
+	deviceAuthMiddleware := middleware.NewDeviceAuthMiddleware(deviceService)
+
+	maintenanceRepo := repository.NewMaintenanceRepository(dbPool)
+	maintenanceService := service.NewMaintenanceService(maintenanceRepo, machineRepo, bookingRepo, bus)
+	maintenanceHandler := handlers.NewMaintenanceHandler(maintenanceService)
+
+	// Фоновые задачи выполняет только экземпляр-лидер каждой из них.
+	elector := leader.NewElector(dbPool, cfg.NodeID)
+	elector.Register("notifications", notificationService.RunWorker)
+	elector.Register("maintenance", maintenanceService.RunWorker)
+	elector.Register("announcements", announcementService.RunWorker)
+	if telegramClient != nil {
+		elector.Register("telegram", telegramService.RunPolling)
+	}
+	if cfg.MQTTBrokerURL != "" {
+		topics, err := telemetry.ParseTopicMap(cfg.MQTTTopicMap)
+		if err != nil {
+			log.Fatalf("Invalid MQTT_TOPIC_MAP: %v", err)
+		}
+		if len(topics) == 0 {
+			log.Fatalf("MQTT_TOPIC_MAP is empty, telemetry has no topics to subscribe to")
+		}
+		detector := telemetry.NewDetector(telemetry.Thresholds{
+			StartWatts: cfg.TelemetryStartWatts,
+			StopWatts:  cfg.TelemetryStopWatts,
+			StartDelay: cfg.TelemetryStartDelay,
+			StopDelay:  cfg.TelemetryStopDelay,
+		})
+		ingestor := telemetry.NewIngestor(telemetry.MQTTConfig{
+			BrokerURL: cfg.MQTTBrokerURL,
+			ClientID:  cfg.MQTTClientID,
+			Username:  cfg.MQTTUsername,
+			Password:  cfg.MQTTPassword,
+			Topics:    topics,
+		}, detector, telemetryService)
+		elector.Register("telemetry", ingestor.Run)
+	}
+	elector.Start(context.Background())
+	clusterHandler := handlers.NewClusterHandler(elector)
+
 	authMiddleware := middleware.NewAuthMiddleware(cfg.JWTSecret)
 
 	api := r.Group("/api")
@@ -75,20 +227,89 @@ func main() {
 		}
 
 		api.GET("/machines", machineHandler.GetAll)
+		api.GET("/machines/:id/photos/:photoId", machineHandler.GetPhoto)
+
+		api.GET("/events/stream", authMiddleware.RequireStreamAuth, eventHandler.Stream)
 
 		protected := api.Group("/")
 		protected.Use(authMiddleware.RequireAuth)
 		{
 			protected.GET("/bookings", bookingHandler.GetAll)
 			protected.POST("/bookings", bookingHandler.Create)
+			protected.PATCH("/bookings/:id", bookingHandler.Reschedule)
 			protected.DELETE("/bookings/:id", bookingHandler.Cancel)
+
+			protected.POST("/events/ticket", authHandler.StreamTicket)
+
+			protected.POST("/machines/:id/reports", ticketHandler.Report)
+			protected.GET("/me/reports", ticketHandler.GetMine)
+
+			protected.GET("/me/notification-preferences", notificationHandler.GetPreferences)
+			protected.PUT("/me/notification-preferences", notificationHandler.UpdatePreferences)
+
+			protected.GET("/notifications", notificationHandler.GetInbox)
+			protected.GET("/notifications/unread-count", notificationHandler.GetUnreadCount)
+			protected.POST("/notifications/read-all", notificationHandler.MarkAllRead)
+			protected.POST("/notifications/:id/read", notificationHandler.MarkRead)
+			protected.DELETE("/notifications/:id", notificationHandler.DeleteInboxNotification)
+			protected.DELETE("/notifications", notificationHandler.ClearInbox)
+
+			protected.GET("/me/devices", notificationHandler.ListDevices)
+			protected.DELETE("/me/devices/:id", notificationHandler.RevokeDevice)
+
+			protected.GET("/announcements", announcementHandler.GetMine)
+
+			protected.GET("/me/telegram", telegramHandler.GetLink)
+			protected.POST("/me/telegram/link-code", telegramHandler.CreateLinkCode)
+			protected.DELETE("/me/telegram", telegramHandler.Unlink)
+
+			protected.GET("/scan", checkInHandler.Resolve)
+			protected.POST("/scan/check-in", checkInHandler.CheckIn)
+
+			protected.GET("/stats/occupancy", recommendationHandler.GetOccupancy)
+			protected.GET("/recommendations/slots", recommendationHandler.GetQuietSlots)
+
+			protected.GET("/wallet", paymentHandler.GetWallet)
+			protected.GET("/payments", paymentHandler.GetPayments)
+			protected.POST("/payments/checkout", paymentHandler.CreateCheckout)
 		}
 
 		admin := api.Group("/")
 		admin.Use(authMiddleware.RequireAuth, authMiddleware.RequireRole("admin", "superadmin"))
 		{
-			admin.PUT("/machines/:id", machineHandler.UpdateStatus)
+			admin.POST("/machines", machineHandler.Create)
+			admin.PUT("/machines/:id", machineHandler.Update)
+			admin.DELETE("/machines/:id", machineHandler.Decommission)
+			admin.GET("/machines/:id/history", machineHandler.GetHistory)
+			admin.POST("/admin/machines/:id/photos", machineHandler.UploadPhoto)
+			admin.DELETE("/admin/machines/:id/photos/:photoId", machineHandler.DeletePhoto)
 			admin.PATCH("/bookings/:id/complete", bookingHandler.CompleteBooking)
+
+			admin.GET("/admin/tickets", ticketHandler.List)
+			admin.PATCH("/admin/tickets/:id", ticketHandler.UpdateStatus)
+			admin.GET("/admin/tickets/:id/photo", ticketHandler.GetPhoto)
+
+			admin.GET("/admin/machines/:id/qr.png", checkInHandler.GetQRCode)
+			admin.GET("/admin/rooms/:room/qr-sheet", checkInHandler.GetRoomSheet)
+
+			admin.GET("/admin/maintenance/plans", maintenanceHandler.ListPlans)
+			admin.POST("/admin/maintenance/plans", maintenanceHandler.CreatePlan)
+			admin.PUT("/admin/maintenance/plans/:id", maintenanceHandler.UpdatePlan)
+			admin.DELETE("/admin/maintenance/plans/:id", maintenanceHandler.DeactivatePlan)
+			admin.GET("/admin/maintenance/tasks", maintenanceHandler.ListTasks)
+			admin.POST("/admin/maintenance/tasks/:id/complete", maintenanceHandler.CompleteTask)
+
+			admin.POST("/admin/devices", deviceHandler.Create)
+			admin.GET("/admin/devices", deviceHandler.List)
+			admin.DELETE("/admin/devices/:id", deviceHandler.Revoke)
+			admin.GET("/admin/users/:id/devices", notificationHandler.ListUserDevices)
+			admin.DELETE("/admin/users/:id/devices/:deviceId", notificationHandler.RevokeUserDevice)
+			admin.GET("/admin/announcements", announcementHandler.List)
+			admin.POST("/admin/announcements", announcementHandler.Create)
+			admin.GET("/admin/notifications/deliveries", notificationHandler.ListDeliveries)
+			admin.POST("/admin/notifications/deliveries/:id/retry", notificationHandler.RetryDelivery)
+			admin.GET("/machines/:id/device-events", deviceHandler.GetMachineEvents)
+			admin.GET("/admin/cluster", clusterHandler.GetLeaders)
 		}
 		api.GET("/verify-email", emailHandler.VerifyEmail)
 		api.POST("/forgot-password", emailHandler.ForgotPassword)
@@ -96,8 +317,13 @@ func main() {
 
 		api.GET("/vapid-key", notificationHandler.GetVAPIDKey)
 		api.POST("/subscribe", authMiddleware.RequireAuth, notificationHandler.Subscribe)
+		api.DELETE("/subscribe", authMiddleware.RequireAuth, notificationHandler.Unsubscribe)
+		api.POST("/notifications/actions", notificationHandler.PerformAction)
+
+		api.POST("/payments/webhook", paymentHandler.Webhook)
+
+		api.POST("/devices/events", deviceAuthMiddleware.RequireDevice, deviceHandler.PostEvent)
 
-		api.POST("/machines", authMiddleware.RequireAuth, machineHandler.Create)
 		api.POST("/register", authHandler.Register)
 		api.POST("/login", authHandler.Login)
 	}
@@ -123,6 +349,7 @@ func main() {
 	r.StaticFile("/main.html", "./frontend/main.html")
 	r.StaticFile("/profile.html", "./frontend/profile.html")
 	r.StaticFile("/bookings.html", "./frontend/bookings.html")
+	r.StaticFile("/scan.html", "./frontend/scan.html")
 	r.StaticFile("/register.html", "./frontend/register.html")
 	r.StaticFile("/forgot-password.html", "./frontend/forgot-password.html")
 	r.StaticFile("/reset-password.html", "./frontend/reset-password.html")
@@ -138,3 +365,39 @@ func main() {
 		log.Fatalf("Failed to run server: %v", err)
 	}
 }
+
+// loadVAPIDKeys выбирает ключи для пушей: пара из VAPID_PUBLIC_KEY и
+// VAPID_PRIVATE_KEY, иначе файл VAPID_KEYS_FILE, иначе таблица vapid_keys
+// (ключи создаёт go run ./cmd/vapid_keys generate). Встроенная пара для
+// разработки допускается только при APP_ENV=development.
+func loadVAPIDKeys(cfg *config.Config, db *pgxpool.Pool) (*service.VAPIDKeyring, error) {
+	if cfg.VAPIDPublicKey != "" || cfg.VAPIDPrivateKey != "" {
+		if cfg.VAPIDPublicKey == "" || cfg.VAPIDPrivateKey == "" {
+			return nil, errors.New("set both VAPID_PUBLIC_KEY and VAPID_PRIVATE_KEY")
+		}
+		if cfg.VAPIDPrivateKey == service.DevVAPIDPrivateKey && !cfg.DevMode() {
+			return nil, errors.New("VAPID_PRIVATE_KEY is the public development key, generate your own with go run ./cmd/vapid_keys generate")
+		}
+		return service.NewStaticVAPIDKeyring(cfg.VAPIDSubject, cfg.VAPIDPublicKey, cfg.VAPIDPrivateKey), nil
+	}
+
+	var store service.VAPIDKeyStore = repository.NewVAPIDKeyRepository(db)
+	if cfg.VAPIDKeysFile != "" {
+		store = repository.NewVAPIDKeyFile(cfg.VAPIDKeysFile)
+	}
+	keys, err := service.LoadVAPIDKeyring(context.Background(), store, cfg.VAPIDSubject)
+	if errors.Is(err, service.ErrNoVAPIDKeys) && cfg.DevMode() {
+		log.Println("⚠️ No VAPID keys, using the development key pair")
+		return service.NewStaticVAPIDKeyring(cfg.VAPIDSubject, service.DevVAPIDPublicKey, service.DevVAPIDPrivateKey), nil
+	}
+	if errors.Is(err, service.ErrNoVAPIDKeys) {
+		return nil, errors.New("no VAPID keys configured, run go run ./cmd/vapid_keys generate or set APP_ENV=development")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if keys.Current(context.Background()).PrivateKey == service.DevVAPIDPrivateKey && !cfg.DevMode() {
+		return nil, errors.New("the active VAPID key is the public development key, run go run ./cmd/vapid_keys rotate")
+	}
+	return keys, nil
+}
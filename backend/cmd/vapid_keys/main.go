@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"netiwash/internal/config"
+	"netiwash/internal/repository"
+	"netiwash/internal/service"
+	"netiwash/pkg/database"
+	"netiwash/pkg/utils"
+)
+
+// Управление ключами VAPID для пушей. Ключи хранятся в таблице vapid_keys
+// или, с -file (по умолчанию VAPID_KEYS_FILE), в JSON-файле.
+//
+//	generate            создать первый ключ
+//	-print generate     только вывести пару для VAPID_PUBLIC_KEY/VAPID_PRIVATE_KEY
+//	rotate              создать новый активный ключ, прежний вывести из оборота
+//	list                показать ключи без приватной части
+//	prune               удалить ключи, выведенные из оборота раньше -older-than
+func main() {
+	cfg := config.LoadConfig()
+	file := flag.String("file", cfg.VAPIDKeysFile, "JSON-файл с ключами вместо таблицы vapid_keys")
+	printOnly := flag.Bool("print", false, "generate: вывести VAPID_PUBLIC_KEY и VAPID_PRIVATE_KEY, ничего не сохраняя")
+	olderThan := flag.Duration("older-than", 30*24*time.Hour, "prune: сколько хранить выведенные ключи")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: vapid_keys [flags] generate|rotate|list|prune")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	command := flag.Arg(0)
+
+	if command == "generate" && *printOnly {
+		key, err := service.GenerateVAPIDKey()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("VAPID_PUBLIC_KEY=%s\nVAPID_PRIVATE_KEY=%s\n", key.PublicKey, key.PrivateKey)
+		return
+	}
+
+	ctx := context.Background()
+	var store service.VAPIDKeyStore
+	if *file != "" {
+		store = repository.NewVAPIDKeyFile(*file)
+	} else {
+		db, err := database.ConnectDB(cfg.DBUrl)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer db.Close()
+		if err := database.RunMigrations(db); err != nil {
+			log.Printf("⚠️ Migration warning: %v", err)
+		}
+		store = repository.NewVAPIDKeyRepository(db)
+	}
+
+	keys, err := store.List(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var active bool
+	for _, k := range keys {
+		active = active || k.Active
+	}
+
+	switch command {
+	case "generate", "rotate":
+		if command == "generate" && active {
+			log.Fatal("an active VAPID key already exists, use rotate to replace it")
+		}
+		key, err := service.GenerateVAPIDKey()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := store.Add(ctx, key); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("✅ New active VAPID key: %s", key.PublicKey)
+		if command == "rotate" && active {
+			log.Println("Servers switch to it within a minute; browsers resubscribe the next time they open the app.")
+			log.Println("Run prune once the old key is no longer needed.")
+		}
+
+	case "list":
+		for _, k := range keys {
+			status := "retired " + formatTime(k.RetiredAt)
+			if k.Active {
+				status = "active"
+			}
+			fmt.Printf("%s\tcreated %s\t%s\n", k.PublicKey, utils.InLaundryLocation(k.CreatedAt).Format(time.DateTime), status)
+		}
+
+	case "prune":
+		before := time.Now().In(utils.LaundryLocation()).Add(-*olderThan)
+		n, err := store.DeleteRetired(ctx, before)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("✅ Deleted %d retired VAPID keys; their remaining subscriptions are dropped on the next push", n)
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return utils.InLaundryLocation(*t).Format(time.DateTime)
+}
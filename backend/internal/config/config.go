@@ -2,6 +2,9 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -10,6 +13,51 @@ type Config struct {
 	Port      string
 	DBUrl     string
 	JWTSecret string
+	AppEnv    string // development разрешает встроенные ключи для разработки
+
+	PaymentAPIURL        string
+	PaymentShopID        string
+	PaymentSecretKey     string
+	PaymentWebhookSecret string
+	PaymentReturnURL     string
+	CreditPrice          int // цена одной стирки в копейках
+
+	UploadDir string
+
+	StorageBackend string // local | s3
+	S3Endpoint     string
+	S3Region       string
+	S3Bucket       string
+	S3AccessKey    string
+	S3SecretKey    string
+	S3PathStyle    bool
+
+	EventBus string // local | postgres (несколько экземпляров за балансировщиком)
+	NodeID   string // имя экземпляра в отчёте о лидерах фоновых задач
+
+	ReminderOffsets []time.Duration // за сколько до начала брони напоминать
+
+	VAPIDPublicKey  string // пара из окружения, без ротации
+	VAPIDPrivateKey string
+	VAPIDSubject    string
+	VAPIDKeysFile   string // пусто — ключи в таблице vapid_keys
+
+	PublicURL     string // адрес фронтенда, на который ведут QR-коды
+	QRSigningKeys string // "v2:secret2,v1:secret1", первым — текущий ключ
+
+	MQTTBrokerURL       string // пусто — телеметрия выключена
+	MQTTClientID        string
+	MQTTUsername        string
+	MQTTPassword        string
+	MQTTTopicMap        string // "plugs/washer1/power=1,plugs/washer2/power=2"
+	TelemetryStartWatts float64
+	TelemetryStopWatts  float64
+	TelemetryStartDelay time.Duration
+	TelemetryStopDelay  time.Duration
+
+	TelegramBotToken    string // пусто — бот выключен
+	TelegramAPIURL      string // Bot API или локальная заглушка cmd/fake_telegram
+	TelegramBotUsername string // для ссылки t.me/<bot>?start=<код>
 }
 
 func LoadConfig() *Config {
@@ -33,5 +81,116 @@ func LoadConfig() *Config {
 		Port:      port,
 		DBUrl:     dbUrl,
 		JWTSecret: jwtSecret,
+		AppEnv:    getEnv("APP_ENV", "production"),
+
+		PaymentAPIURL:        getEnv("PAYMENT_API_URL", "https://api.yookassa.ru/v3"),
+		PaymentShopID:        getEnv("PAYMENT_SHOP_ID", ""),
+		PaymentSecretKey:     getEnv("PAYMENT_SECRET_KEY", ""),
+		PaymentWebhookSecret: getEnv("PAYMENT_WEBHOOK_SECRET", ""),
+		PaymentReturnURL:     getEnv("PAYMENT_RETURN_URL", "http://localhost:3000/profile.html"),
+		CreditPrice:          getEnvInt("CREDIT_PRICE", 10000),
+
+		UploadDir: getEnv("UPLOAD_DIR", "./uploads"),
+
+		StorageBackend: getEnv("STORAGE_BACKEND", "local"),
+		S3Endpoint:     getEnv("S3_ENDPOINT", ""),
+		S3Region:       getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:       getEnv("S3_BUCKET", ""),
+		S3AccessKey:    getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:    getEnv("S3_SECRET_KEY", ""),
+		S3PathStyle:    getEnv("S3_PATH_STYLE", "true") == "true",
+
+		EventBus: getEnv("EVENT_BUS", "local"),
+		NodeID:   getEnv("NODE_ID", hostname()),
+
+		ReminderOffsets: getEnvDurations("REMINDER_OFFSETS", time.Minute, []time.Duration{15 * time.Minute, 5 * time.Minute}),
+
+		VAPIDPublicKey:  getEnv("VAPID_PUBLIC_KEY", ""),
+		VAPIDPrivateKey: getEnv("VAPID_PRIVATE_KEY", ""),
+		VAPIDSubject:    getEnv("VAPID_EMAIL", "mailto:admin@neti.ru"),
+		VAPIDKeysFile:   getEnv("VAPID_KEYS_FILE", ""),
+
+		PublicURL:     getEnv("PUBLIC_URL", "http://localhost:3000"),
+		QRSigningKeys: getEnv("QR_SIGNING_KEYS", ""),
+
+		MQTTBrokerURL:       getEnv("MQTT_BROKER_URL", ""),
+		MQTTClientID:        getEnv("MQTT_CLIENT_ID", "netiwash-backend"),
+		MQTTUsername:        getEnv("MQTT_USERNAME", ""),
+		MQTTPassword:        getEnv("MQTT_PASSWORD", ""),
+		MQTTTopicMap:        getEnv("MQTT_TOPIC_MAP", ""),
+		TelemetryStartWatts: getEnvFloat("TELEMETRY_START_WATTS", 30),
+		TelemetryStopWatts:  getEnvFloat("TELEMETRY_STOP_WATTS", 5),
+		TelemetryStartDelay: getEnvDuration("TELEMETRY_START_DELAY", 30*time.Second),
+		TelemetryStopDelay:  getEnvDuration("TELEMETRY_STOP_DELAY", 3*time.Minute),
+
+		TelegramBotToken:    getEnv("TELEGRAM_BOT_TOKEN", ""),
+		TelegramAPIURL:      getEnv("TELEGRAM_API_URL", "https://api.telegram.org"),
+		TelegramBotUsername: getEnv("TELEGRAM_BOT_USERNAME", ""),
+	}
+}
+
+// DevMode — локальная разработка: допускаются небезопасные значения по
+// умолчанию.
+func (c *Config) DevMode() bool {
+	return c.AppEnv == "development"
+}
+
+func hostname() string {
+	if name, err := os.Hostname(); err == nil && name != "" {
+		return name
+	}
+	return "netiwash"
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
+
+// getEnvDurations разбирает список через запятую ("15m,5m"), каждый элемент
+// должен быть кратен unit. Пустое значение или ошибка в любом элементе —
+// значение по умолчанию.
+func getEnvDurations(key string, unit time.Duration, defaultValue []time.Duration) []time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	var out []time.Duration
+	for _, part := range strings.Split(value, ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil || d <= 0 || d%unit != 0 {
+			return defaultValue
+		}
+		out = append(out, d)
 	}
+	return out
 }
@@ -0,0 +1,66 @@
+// Package eventbus доставляет доменные события от сервисов подписчикам:
+// клиентам реального времени и уведомлениям.
+package eventbus
+
+import (
+	"context"
+	"sync"
+
+	"netiwash/internal/models"
+)
+
+// Handler обрабатывает событие. Вызывается синхронно, поэтому не должен
+// надолго блокироваться.
+type Handler func(ctx context.Context, e models.Event)
+
+// Bus — шина событий. Subscribe получает события со всех экземпляров
+// бэкенда; SubscribeLocal — только поднятые в этом процессе, для побочных
+// эффектов, которые должны выполниться ровно один раз (например, пуш).
+type Bus interface {
+	Publish(ctx context.Context, e models.Event)
+	Subscribe(h Handler)
+	SubscribeLocal(h Handler)
+}
+
+// LocalBus — шина внутри одного процесса.
+type LocalBus struct {
+	mu       sync.RWMutex
+	handlers []Handler
+	local    []Handler
+}
+
+func NewLocalBus() *LocalBus {
+	return &LocalBus{}
+}
+
+func (b *LocalBus) Publish(ctx context.Context, e models.Event) {
+	b.dispatch(ctx, e, true)
+}
+
+func (b *LocalBus) Subscribe(h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, h)
+}
+
+func (b *LocalBus) SubscribeLocal(h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.local = append(b.local, h)
+}
+
+func (b *LocalBus) dispatch(ctx context.Context, e models.Event, local bool) {
+	b.mu.RLock()
+	handlers := b.handlers
+	localHandlers := b.local
+	b.mu.RUnlock()
+
+	for _, h := range handlers {
+		h(ctx, e)
+	}
+	if local {
+		for _, h := range localHandlers {
+			h(ctx, e)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package eventbus
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"time"
+
+	"netiwash/internal/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// Channel — канал LISTEN/NOTIFY, общий для всех экземпляров.
+	Channel = "netiwash_events"
+	// maxPayload — предел NOTIFY в PostgreSQL (8000 байт) с запасом.
+	maxPayload     = 7900
+	reconnectDelay = 5 * time.Second
+	publishTimeout = 5 * time.Second
+)
+
+// envelope — событие на проводе. UserID не попадает в JSON события для
+// клиентов, поэтому передаётся отдельно.
+type envelope struct {
+	Origin    string          `json:"origin"`
+	Type      string          `json:"type"`
+	UserID    *int            `json:"user_id,omitempty"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// PostgresBus рассылает события между экземплярами через LISTEN/NOTIFY.
+// Свои события доставляются сразу, без круга через базу; их эхо из канала
+// отбрасывается по origin.
+type PostgresBus struct {
+	*LocalBus
+	db     *pgxpool.Pool
+	origin string
+}
+
+func NewPostgresBus(db *pgxpool.Pool) *PostgresBus {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	return &PostgresBus{
+		LocalBus: NewLocalBus(),
+		db:       db,
+		origin:   hex.EncodeToString(buf),
+	}
+}
+
+// Start запускает прослушивание канала. Соединение для LISTEN берётся из
+// пула и удерживается; при обрыве слушатель переподключается.
+func (b *PostgresBus) Start(ctx context.Context) {
+	go func() {
+		for {
+			if err := b.listen(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("📣 [EVENTS] Listener error: %v, reconnecting in %s", err, reconnectDelay)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(reconnectDelay):
+			}
+		}
+	}()
+	log.Printf("📣 [EVENTS] PostgreSQL event bus started (instance %s)", b.origin)
+}
+
+func (b *PostgresBus) listen(ctx context.Context) error {
+	conn, err := b.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	// Соединение с активным LISTEN не должно вернуться в пул.
+	defer conn.Conn().Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+Channel); err != nil {
+		return err
+	}
+
+	for {
+		n, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var env envelope
+		if err := json.Unmarshal([]byte(n.Payload), &env); err != nil {
+			log.Printf("📣 [EVENTS] Bad payload: %v", err)
+			continue
+		}
+		if env.Origin == b.origin {
+			continue
+		}
+
+		b.dispatch(ctx, models.Event{
+			Type:      env.Type,
+			UserID:    env.UserID,
+			Data:      env.Data,
+			CreatedAt: env.CreatedAt,
+		}, false)
+	}
+}
+
+func (b *PostgresBus) Publish(ctx context.Context, e models.Event) {
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	b.dispatch(ctx, e, true)
+
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		log.Printf("📣 [EVENTS] Failed to encode %s: %v", e.Type, err)
+		return
+	}
+	payload, err := json.Marshal(envelope{
+		Origin:    b.origin,
+		Type:      e.Type,
+		UserID:    e.UserID,
+		Data:      data,
+		CreatedAt: e.CreatedAt,
+	})
+	if err != nil {
+		log.Printf("📣 [EVENTS] Failed to encode %s: %v", e.Type, err)
+		return
+	}
+	if len(payload) > maxPayload {
+		log.Printf("📣 [EVENTS] %s is too large for NOTIFY (%d bytes), delivered locally only", e.Type, len(payload))
+		return
+	}
+
+	// Событие публикуется после ответа базы; отмена запроса клиентом не
+	// должна помешать разослать его остальным экземплярам.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), publishTimeout)
+	defer cancel()
+	if _, err := b.db.Exec(ctx, "SELECT pg_notify($1, $2)", Channel, string(payload)); err != nil {
+		log.Printf("📣 [EVENTS] Failed to publish %s: %v", e.Type, err)
+	}
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"netiwash/internal/models"
+	"netiwash/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AnnouncementHandler struct {
+	service *service.AnnouncementService
+}
+
+func NewAnnouncementHandler(service *service.AnnouncementService) *AnnouncementHandler {
+	return &AnnouncementHandler{service: service}
+}
+
+// GetMine — объявления, адресованные текущему пользователю.
+func (h *AnnouncementHandler) GetMine(c *gin.Context) {
+	announcements, err := h.service.GetForUser(c.Request.Context(), c.GetInt("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if announcements == nil {
+		announcements = []models.Announcement{}
+	}
+	c.JSON(http.StatusOK, announcements)
+}
+
+func (h *AnnouncementHandler) List(c *gin.Context) {
+	announcements, err := h.service.GetRecent(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if announcements == nil {
+		announcements = []models.Announcement{}
+	}
+	c.JSON(http.StatusOK, announcements)
+}
+
+func (h *AnnouncementHandler) Create(c *gin.Context) {
+	var req models.CreateAnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Укажите заголовок, текст и получателей (all, building, room или bookings)"})
+		return
+	}
+
+	announcement, err := h.service.Create(c.Request.Context(), c.GetInt("userID"), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidAnnouncement) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, announcement)
+}
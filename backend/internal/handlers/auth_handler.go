@@ -5,6 +5,7 @@ import (
 
 	"netiwash/internal/models"
 	"netiwash/internal/service"
+	"netiwash/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -48,3 +49,17 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	c.JSON(http.StatusOK, res)
 }
+
+// StreamTicket выдаёт билет для GET /api/events/stream?ticket=.
+func (h *AuthHandler) StreamTicket(c *gin.Context) {
+	ticket, err := h.service.IssueStreamTicket(c.GetInt("userID"), c.GetString("role"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"ticket":     ticket,
+		"expires_in": int(utils.StreamTicketTTL.Seconds()),
+	})
+}
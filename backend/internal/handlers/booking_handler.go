@@ -1,12 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
 	"netiwash/internal/models"
 	"netiwash/internal/service"
+	"netiwash/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -55,12 +57,7 @@ func (h *BookingHandler) Create(c *gin.Context) {
 
 	fullTimeStr := req.Date + "T" + req.Time + ":00"
 
-	loc, err := time.LoadLocation("Asia/Novosibirsk")
-	if err != nil {
-		loc = time.FixedZone("UTC+7", 7*60*60)
-	}
-
-	startTime, err := time.ParseInLocation("2006-01-02T15:04:05", fullTimeStr, loc)
+	startTime, err := time.ParseInLocation("2006-01-02T15:04:05", fullTimeStr, utils.LaundryLocation())
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date/time format"})
 		return
@@ -74,10 +71,22 @@ func (h *BookingHandler) Create(c *gin.Context) {
 
 	booking, err := h.service.Create(c.Request.Context(), userID, req.MachineID, startTime)
 	if err != nil {
-		if err.Error() == "time slot is busy" {
+		if errors.Is(err, service.ErrTimeSlotBusy) {
 			c.JSON(http.StatusConflict, gin.H{"error": "Время уже занято"})
 			return
 		}
+		if errors.Is(err, service.ErrBookingInPast) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Нельзя забронировать время в прошлом"})
+			return
+		}
+		if errors.Is(err, service.ErrTooManyBookings) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Максимум 5 активных бронирований"})
+			return
+		}
+		if errors.Is(err, service.ErrMachineNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Машинка не найдена или выведена из эксплуатации"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -101,6 +110,10 @@ func (h *BookingHandler) Cancel(c *gin.Context) {
 	isAdmin := (role == "admin" || role == "superadmin")
 
 	if err := h.service.Cancel(c.Request.Context(), id, userID, isAdmin); err != nil {
+		if errors.Is(err, service.ErrBookingNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -108,6 +121,53 @@ func (h *BookingHandler) Cancel(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Booking cancelled"})
 }
 
+// Reschedule переносит бронь на другое время (`date`, `time` — как при создании).
+func (h *BookingHandler) Reschedule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking ID"})
+		return
+	}
+
+	var req struct {
+		Date string `json:"date"`
+		Time string `json:"time"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid data"})
+		return
+	}
+
+	startTime, err := time.ParseInLocation("2006-01-02T15:04:05", req.Date+"T"+req.Time+":00", utils.LaundryLocation())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date/time format"})
+		return
+	}
+
+	roleVal, _ := c.Get("role")
+	role, _ := roleVal.(string)
+	isAdmin := (role == "admin" || role == "superadmin")
+
+	booking, err := h.service.Reschedule(c.Request.Context(), id, c.GetInt("userID"), isAdmin, startTime)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrBookingNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		case errors.Is(err, service.ErrBookingNotActive):
+			c.JSON(http.StatusConflict, gin.H{"error": "Перенести можно только активную бронь"})
+		case errors.Is(err, service.ErrTimeSlotBusy):
+			c.JSON(http.StatusConflict, gin.H{"error": "Время уже занято"})
+		case errors.Is(err, service.ErrBookingInPast):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Нельзя перенести бронь в прошлое"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, booking)
+}
+
 func (h *BookingHandler) CompleteBooking(c *gin.Context) {
 	id := c.Param("id")
 	bookingID, err := strconv.Atoi(id)
@@ -125,10 +185,14 @@ func (h *BookingHandler) CompleteBooking(c *gin.Context) {
 	}
 
 	booking, err := h.service.GetByID(c.Request.Context(), bookingID)
-	if err != nil {
+	if errors.Is(err, service.ErrBookingNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
 		return
 	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
 	if err := h.service.CompleteBooking(c.Request.Context(), bookingID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"netiwash/internal/models"
+	"netiwash/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	qrDefaultSize = 512
+	qrSheetSize   = 320
+)
+
+var qrSheetTemplate = template.Must(template.New("sheet").Funcs(template.FuncMap{
+	"dataURI": func(png []byte) template.URL {
+		return template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(png))
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="UTF-8">
+<title>NETI WASH — QR-коды, {{.Room}}</title>
+<style>
+body { font-family: sans-serif; margin: 0; }
+.grid { display: flex; flex-wrap: wrap; }
+.card { width: 50%; box-sizing: border-box; padding: 24px; text-align: center; page-break-inside: avoid; }
+.card img { width: 70%; }
+.card h2 { margin: 8px 0 4px; }
+.card p { margin: 0; color: #676767; font-size: 12px; }
+</style>
+</head>
+<body>
+<div class="grid">
+{{range .Machines}}<div class="card">
+<img src="{{dataURI .PNG}}" alt="QR {{.Machine.Name}}">
+<h2>{{.Machine.Name}}</h2>
+<p>Отсканируйте, чтобы отметиться или занять машину</p>
+</div>
+{{else}}<p>В комнате нет машин</p>
+{{end}}</div>
+</body>
+</html>
+`))
+
+type CheckInHandler struct {
+	service *service.CheckInService
+}
+
+func NewCheckInHandler(service *service.CheckInService) *CheckInHandler {
+	return &CheckInHandler{service: service}
+}
+
+func (h *CheckInHandler) GetQRCode(c *gin.Context) {
+	machineID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid machine ID"})
+		return
+	}
+
+	size, err := strconv.Atoi(c.DefaultQuery("size", strconv.Itoa(qrDefaultSize)))
+	if err != nil || size < 128 || size > 2048 {
+		size = qrDefaultSize
+	}
+
+	png, err := h.service.QRCode(c.Request.Context(), machineID, size)
+	if err != nil {
+		respondCheckInError(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+// GetRoomSheet отдаёт HTML-страницу для печати с QR-кодами всех машин комнаты.
+func (h *CheckInHandler) GetRoomSheet(c *gin.Context) {
+	room := c.Param("room")
+	machines, err := h.service.RoomSheet(c.Request.Context(), room, qrSheetSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := qrSheetTemplate.Execute(c.Writer, gin.H{"Room": room, "Machines": machines}); err != nil {
+		c.Error(err)
+	}
+}
+
+func (h *CheckInHandler) Resolve(c *gin.Context) {
+	var link models.MachineLink
+	if err := c.ShouldBindQuery(&link); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректная ссылка"})
+		return
+	}
+
+	result, err := h.service.Resolve(c.Request.Context(), c.GetInt("userID"), &link)
+	if err != nil {
+		respondCheckInError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *CheckInHandler) CheckIn(c *gin.Context) {
+	var link models.MachineLink
+	if err := c.ShouldBindJSON(&link); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректная ссылка"})
+		return
+	}
+
+	result, err := h.service.CheckIn(c.Request.Context(), c.GetInt("userID"), &link)
+	if err != nil {
+		respondCheckInError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func respondCheckInError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidMachineLink):
+		c.JSON(http.StatusForbidden, gin.H{"error": "QR-код недействителен"})
+	case errors.Is(err, service.ErrMachineNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Машинка не найдена или выведена из эксплуатации"})
+	case errors.Is(err, service.ErrMachineUnavailable), errors.Is(err, service.ErrTimeSlotBusy):
+		c.JSON(http.StatusConflict, gin.H{"error": "Машинка сейчас занята"})
+	case errors.Is(err, service.ErrTooManyBookings):
+		c.JSON(http.StatusConflict, gin.H{"error": "Максимум 5 активных бронирований"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
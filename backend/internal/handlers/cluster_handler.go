@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+
+	"netiwash/internal/leader"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ClusterHandler struct {
+	elector *leader.Elector
+}
+
+func NewClusterHandler(elector *leader.Elector) *ClusterHandler {
+	return &ClusterHandler{elector: elector}
+}
+
+// GetLeaders — какой экземпляр ведёт каждую фоновую задачу. Отвечает любой
+// экземпляр; node — тот, что обработал запрос.
+func (h *ClusterHandler) GetLeaders(c *gin.Context) {
+	jobs, err := h.elector.Leaders(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"node":    h.elector.NodeID(),
+		"leading": h.elector.Leading(),
+		"jobs":    jobs,
+	})
+}
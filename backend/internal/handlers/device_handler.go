@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"netiwash/internal/models"
+	"netiwash/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DeviceHandler struct {
+	service *service.DeviceService
+}
+
+func NewDeviceHandler(service *service.DeviceService) *DeviceHandler {
+	return &DeviceHandler{service: service}
+}
+
+func (h *DeviceHandler) Create(c *gin.Context) {
+	var req models.CreateDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.service.Issue(c.Request.Context(), c.GetInt("userID"), &req)
+	if err != nil {
+		respondDeviceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (h *DeviceHandler) List(c *gin.Context) {
+	devices, err := h.service.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if devices == nil {
+		devices = []models.Device{}
+	}
+	c.JSON(http.StatusOK, devices)
+}
+
+func (h *DeviceHandler) Revoke(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid device ID"})
+		return
+	}
+
+	if err := h.service.Revoke(c.Request.Context(), id); err != nil {
+		respondDeviceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Device revoked"})
+}
+
+func (h *DeviceHandler) GetMachineEvents(c *gin.Context) {
+	machineID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid machine ID"})
+		return
+	}
+
+	events, err := h.service.GetEvents(c.Request.Context(), machineID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if events == nil {
+		events = []models.DeviceEvent{}
+	}
+	c.JSON(http.StatusOK, events)
+}
+
+// PostEvent принимает событие от контроллера машины (авторизация ключом устройства).
+func (h *DeviceHandler) PostEvent(c *gin.Context) {
+	var req models.DeviceEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	device := c.MustGet("device").(*models.Device)
+	event, err := h.service.HandleEvent(c.Request.Context(), device, &req)
+	if err != nil {
+		respondDeviceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, event)
+}
+
+func respondDeviceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrDeviceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Device not found"})
+	case errors.Is(err, service.ErrMachineNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Machine not found"})
+	case errors.Is(err, service.ErrDeviceMachineForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"netiwash/internal/models"
+	"netiwash/internal/realtime"
+
+	"github.com/gin-gonic/gin"
+)
+
+// heartbeatInterval меньше типичных таймаутов простоя у прокси (60 с).
+const heartbeatInterval = 25 * time.Second
+
+type EventHandler struct {
+	hub *realtime.Hub
+}
+
+func NewEventHandler(hub *realtime.Hub) *EventHandler {
+	return &EventHandler{hub: hub}
+}
+
+// Stream отдаёт события в формате Server-Sent Events. После переподключения
+// браузер сам присылает Last-Event-ID, и пропущенные события досылаются.
+func (h *EventHandler) Stream(c *gin.Context) {
+	userIDVal, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	userID := userIDVal.(int)
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("lastEventId")
+	}
+
+	sub, missed := h.hub.Subscribe(userID, lastEventID)
+	defer h.hub.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// Клиенту, который отвалился, стоит переподключаться быстро.
+	fmt.Fprint(c.Writer, "retry: 3000\n\n")
+	for _, e := range missed {
+		if err := writeEvent(c, e); err != nil {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case e, ok := <-sub.Events():
+			if !ok {
+				// Хаб отключил медленного клиента — пусть переподключится.
+				log.Printf("📡 [SSE] Dropping slow subscriber (user %d)", userID)
+				return
+			}
+			if err := writeEvent(c, e); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+func writeEvent(c *gin.Context, e models.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(c.Writer, "id: %s\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
@@ -1,74 +1,191 @@
 package handlers
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 
 	"netiwash/internal/models"
-	"netiwash/internal/repository"
+	"netiwash/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
 type MachineHandler struct {
-	repo *repository.MachineRepository
+	service *service.MachineService
 }
 
-func NewMachineHandler(repo *repository.MachineRepository) *MachineHandler {
-	return &MachineHandler{repo: repo}
+func NewMachineHandler(service *service.MachineService) *MachineHandler {
+	return &MachineHandler{service: service}
 }
 
 func (h *MachineHandler) GetAll(c *gin.Context) {
-	machines, err := h.repo.GetAll(c.Request.Context())
+	machines, err := h.service.GetLive(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	if machines == nil {
-		machines = []models.Machine{}
-	}
-
 	c.JSON(http.StatusOK, machines)
 }
 
-func (h *MachineHandler) UpdateStatus(c *gin.Context) {
-	id := c.Param("id")
+func (h *MachineHandler) Update(c *gin.Context) {
+	machineID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid machine ID"})
+		return
+	}
 
-	var req struct {
-		Status string `json:"status" binding:"required,oneof=free busy repair"`
+	var req models.UpdateMachineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid data. Status must be: free, busy, or repair; type: washing or drying"})
+		return
 	}
 
+	machine, err := h.service.Update(c.Request.Context(), machineID, c.GetInt("userID"), &req)
+	if err != nil {
+		respondMachineError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, machine)
+}
+
+func (h *MachineHandler) Create(c *gin.Context) {
+	var req models.CreateMachineRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status. Must be: free, busy, or repair"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid data. Name is required; status must be: free, busy, or repair; type: washing or drying"})
 		return
 	}
 
-	machineID, err := strconv.Atoi(id)
+	machine, err := h.service.Create(c.Request.Context(), c.GetInt("userID"), &req)
+	if err != nil {
+		respondMachineError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, machine)
+}
+
+func (h *MachineHandler) Decommission(c *gin.Context) {
+	machineID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid machine ID"})
 		return
 	}
 
-	if err := h.repo.UpdateStatus(c.Request.Context(), machineID, req.Status); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	cancelled, err := h.service.Decommission(c.Request.Context(), machineID)
+	if err != nil {
+		respondMachineError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Machine status updated successfully"})
+	c.JSON(http.StatusOK, gin.H{
+		"message":            "Machine decommissioned",
+		"machine_id":         machineID,
+		"cancelled_bookings": len(cancelled),
+	})
 }
 
-func (h *MachineHandler) Create(c *gin.Context) {
-	var req models.Machine
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+func (h *MachineHandler) GetHistory(c *gin.Context) {
+	machineID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid machine ID"})
 		return
 	}
 
-	if err := h.repo.Create(c.Request.Context(), &req); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	history, err := h.service.GetStatusHistory(c.Request.Context(), machineID)
+	if err != nil {
+		respondMachineError(c, err)
+		return
+	}
+	if history == nil {
+		history = []models.MachineStatusChange{}
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+func (h *MachineHandler) UploadPhoto(c *gin.Context) {
+	machineID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid machine ID"})
 		return
 	}
 
-	c.JSON(http.StatusCreated, req)
+	fh, err := c.FormFile("photo")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Прикрепите файл photo"})
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Не удалось прочитать фото"})
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, 5<<20+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Не удалось прочитать фото"})
+		return
+	}
+
+	photo, err := h.service.AddPhoto(c.Request.Context(), machineID, data)
+	if err != nil {
+		respondMachineError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, photo)
+}
+
+func (h *MachineHandler) GetPhoto(c *gin.Context) {
+	machineID, err1 := strconv.Atoi(c.Param("id"))
+	photoID, err2 := strconv.Atoi(c.Param("photoId"))
+	if err1 != nil || err2 != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid photo ID"})
+		return
+	}
+
+	photo, contentType, err := h.service.OpenPhoto(c.Request.Context(), machineID, photoID)
+	if err != nil {
+		respondMachineError(c, err)
+		return
+	}
+	defer photo.Close()
+
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.DataFromReader(http.StatusOK, -1, contentType, photo, nil)
+}
+
+func (h *MachineHandler) DeletePhoto(c *gin.Context) {
+	machineID, err1 := strconv.Atoi(c.Param("id"))
+	photoID, err2 := strconv.Atoi(c.Param("photoId"))
+	if err1 != nil || err2 != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid photo ID"})
+		return
+	}
+
+	if err := h.service.DeletePhoto(c.Request.Context(), machineID, photoID); err != nil {
+		respondMachineError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Photo deleted"})
+}
+
+func respondMachineError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrMachineNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Machine not found"})
+	case errors.Is(err, service.ErrInvalidStatusTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrUnsupportedPhoto), errors.Is(err, service.ErrTooManyPhotos):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrStatusReasonRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Укажите причину перевода в ремонт"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
 }
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"netiwash/internal/models"
+	"netiwash/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MaintenanceHandler struct {
+	service *service.MaintenanceService
+}
+
+func NewMaintenanceHandler(service *service.MaintenanceService) *MaintenanceHandler {
+	return &MaintenanceHandler{service: service}
+}
+
+func (h *MaintenanceHandler) ListPlans(c *gin.Context) {
+	plans, err := h.service.ListPlans(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if plans == nil {
+		plans = []models.MaintenancePlan{}
+	}
+	c.JSON(http.StatusOK, plans)
+}
+
+func (h *MaintenanceHandler) CreatePlan(c *gin.Context) {
+	var req models.MaintenancePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan, err := h.service.CreatePlan(c.Request.Context(), &req)
+	if err != nil {
+		respondMaintenanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, plan)
+}
+
+func (h *MaintenanceHandler) UpdatePlan(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid plan ID"})
+		return
+	}
+
+	var req models.MaintenancePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan, err := h.service.UpdatePlan(c.Request.Context(), id, &req)
+	if err != nil {
+		respondMaintenanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, plan)
+}
+
+func (h *MaintenanceHandler) DeactivatePlan(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid plan ID"})
+		return
+	}
+
+	if err := h.service.DeactivatePlan(c.Request.Context(), id); err != nil {
+		respondMaintenanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Plan deactivated"})
+}
+
+func (h *MaintenanceHandler) ListTasks(c *gin.Context) {
+	tasks, err := h.service.ListTasks(c.Request.Context(), c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if tasks == nil {
+		tasks = []models.MaintenanceTask{}
+	}
+	c.JSON(http.StatusOK, tasks)
+}
+
+func (h *MaintenanceHandler) CompleteTask(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	if err := h.service.CompleteTask(c.Request.Context(), id, c.GetInt("userID")); err != nil {
+		respondMaintenanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Task completed"})
+}
+
+func respondMaintenanceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrMaintenanceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+	case errors.Is(err, service.ErrMaintenancePlanEmpty):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Укажите every_cycles и/или every_days"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -1,7 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+
+	"netiwash/internal/models"
 	"netiwash/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -17,7 +21,7 @@ func NewNotificationHandler(service *service.NotificationService) *NotificationH
 
 func (h *NotificationHandler) GetVAPIDKey(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
-		"publicKey": h.service.GetPublicKey(),
+		"publicKey": h.service.GetPublicKey(c.Request.Context()),
 	})
 }
 
@@ -28,6 +32,8 @@ func (h *NotificationHandler) Subscribe(c *gin.Context) {
 			P256dh string `json:"p256dh"`
 			Auth   string `json:"auth"`
 		} `json:"keys"`
+		// ApplicationServerKey — ключ VAPID, с которым подписался браузер.
+		ApplicationServerKey string `json:"applicationServerKey"`
 	}
 
 	if err := c.BindJSON(&req); err != nil {
@@ -42,11 +48,284 @@ func (h *NotificationHandler) Subscribe(c *gin.Context) {
 	}
 	userID := userIDVal.(int)
 
-	err := h.service.Subscribe(c.Request.Context(), userID, req.Endpoint, req.Keys.P256dh, req.Keys.Auth)
+	err := h.service.Subscribe(c.Request.Context(), userID, req.Endpoint, req.Keys.P256dh, req.Keys.Auth, c.Request.UserAgent(), req.ApplicationServerKey)
 	if err != nil {
+		if errors.Is(err, service.ErrStaleVAPIDKey) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":     "VAPID key rotated, resubscribe",
+				"publicKey": h.service.GetPublicKey(c.Request.Context()),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{"status": "subscribed"})
 }
+
+// Unsubscribe удаляет подписку текущего браузера по её endpoint.
+func (h *NotificationHandler) Unsubscribe(c *gin.Context) {
+	var req struct {
+		Endpoint string `json:"endpoint" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	if err := h.service.Unsubscribe(c.Request.Context(), c.GetInt("userID"), req.Endpoint); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "unsubscribed"})
+}
+
+func (h *NotificationHandler) ListDevices(c *gin.Context) {
+	h.listDevices(c, c.GetInt("userID"))
+}
+
+func (h *NotificationHandler) RevokeDevice(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid device ID"})
+		return
+	}
+	h.revokeDevice(c, c.GetInt("userID"), id)
+}
+
+// ListUserDevices — устройства пользователя :id для админа.
+func (h *NotificationHandler) ListUserDevices(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	h.listDevices(c, userID)
+}
+
+func (h *NotificationHandler) RevokeUserDevice(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	id, err := strconv.Atoi(c.Param("deviceId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid device ID"})
+		return
+	}
+	h.revokeDevice(c, userID, id)
+}
+
+func (h *NotificationHandler) listDevices(c *gin.Context, userID int) {
+	devices, err := h.service.ListDevices(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, devices)
+}
+
+func (h *NotificationHandler) revokeDevice(c *gin.Context, userID, id int) {
+	if err := h.service.RevokeDevice(c.Request.Context(), userID, id); err != nil {
+		if errors.Is(err, service.ErrPushDeviceNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Device not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Device revoked"})
+}
+
+// ListDeliveries — журнал недоставленных уведомлений для админа.
+func (h *NotificationHandler) ListDeliveries(c *gin.Context) {
+	userID, _ := strconv.Atoi(c.Query("user_id"))
+
+	deliveries, err := h.service.ListDeliveries(c.Request.Context(), c.Query("status"), userID)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDeliveryStatus) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if deliveries == nil {
+		deliveries = []models.NotificationDelivery{}
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+func (h *NotificationHandler) RetryDelivery(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID"})
+		return
+	}
+
+	if err := h.service.RetryDelivery(c.Request.Context(), id); err != nil {
+		if errors.Is(err, service.ErrDeliveryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Dead delivery not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Delivery requeued"})
+}
+
+// PerformAction выполняет нажатие кнопки в пуше. Авторизация — подписанный
+// токен из самого уведомления: service worker не знает JWT пользователя.
+func (h *NotificationHandler) PerformAction(c *gin.Context) {
+	var req struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	action, err := h.service.PerformAction(c.Request.Context(), req.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidActionToken):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Invalid or expired action"})
+		case errors.Is(err, service.ErrBookingNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		case errors.Is(err, service.ErrBookingNotActive):
+			c.JSON(http.StatusConflict, gin.H{"error": "Бронь уже не активна"})
+		case errors.Is(err, service.ErrTimeSlotBusy):
+			c.JSON(http.StatusConflict, gin.H{"error": "Следующее время уже занято"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"action": action, "status": "ok"})
+}
+
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userIDVal, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	userID := userIDVal.(int)
+
+	prefs, err := h.service.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	userIDVal, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	userID := userIDVal.(int)
+
+	var req models.NotificationPreferences
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	prefs, err := h.service.UpdatePreferences(c.Request.Context(), userID, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPreferences) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
+// GetInbox — входящие уведомления: ?unread=true — только непрочитанные,
+// ?before=<id> — следующая страница, ?limit= — размер страницы (до 200).
+func (h *NotificationHandler) GetInbox(c *gin.Context) {
+	beforeID, _ := strconv.Atoi(c.Query("before"))
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	page, err := h.service.GetInbox(c.Request.Context(), c.GetInt("userID"), c.Query("unread") == "true", beforeID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
+	unread, err := h.service.CountUnread(c.Request.Context(), c.GetInt("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"unread": unread})
+}
+
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	if err := h.service.MarkInboxRead(c.Request.Context(), c.GetInt("userID"), id); err != nil {
+		if errors.Is(err, service.ErrInboxNotificationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+}
+
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	updated, err := h.service.MarkInboxAllRead(c.Request.Context(), c.GetInt("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"updated": updated})
+}
+
+func (h *NotificationHandler) DeleteInboxNotification(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	if err := h.service.DeleteInboxNotification(c.Request.Context(), c.GetInt("userID"), id); err != nil {
+		if errors.Is(err, service.ErrInboxNotificationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Notification deleted"})
+}
+
+// ClearInbox удаляет все уведомления, с ?read=true — только прочитанные.
+func (h *NotificationHandler) ClearInbox(c *gin.Context) {
+	deleted, err := h.service.ClearInbox(c.Request.Context(), c.GetInt("userID"), c.Query("read") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"deleted": deleted})
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"netiwash/internal/models"
+	"netiwash/internal/service"
+	"netiwash/pkg/payment"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PaymentHandler struct {
+	service *service.PaymentService
+}
+
+func NewPaymentHandler(service *service.PaymentService) *PaymentHandler {
+	return &PaymentHandler{service: service}
+}
+
+func (h *PaymentHandler) CreateCheckout(c *gin.Context) {
+	var req models.CheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Укажите количество стирок от 1 до 100"})
+		return
+	}
+
+	userID := c.GetInt("userID")
+
+	p, err := h.service.CreateCheckout(c.Request.Context(), userID, req.Credits)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusCreated, p)
+	case errors.Is(err, service.ErrInvalidCredits):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Укажите количество стирок от 1 до 100"})
+	case errors.Is(err, service.ErrPaymentUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Пользователь не найден"})
+	case errors.Is(err, service.ErrPaymentProvider):
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Платёжный сервис недоступен, попробуйте позже"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (h *PaymentHandler) GetWallet(c *gin.Context) {
+	userID := c.GetInt("userID")
+
+	wallet, txs, err := h.service.GetWallet(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if txs == nil {
+		txs = []models.WalletTransaction{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"balance":      wallet.Balance,
+		"transactions": txs,
+	})
+}
+
+func (h *PaymentHandler) GetPayments(c *gin.Context) {
+	userID := c.GetInt("userID")
+
+	payments, err := h.service.GetPayments(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if payments == nil {
+		payments = []models.Payment{}
+	}
+
+	c.JSON(http.StatusOK, payments)
+}
+
+func (h *PaymentHandler) Webhook(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<20))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	err = h.service.HandleWebhook(c.Request.Context(), body, c.GetHeader(payment.SignatureHeader))
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	case errors.Is(err, payment.ErrInvalidSignature):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrPaymentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrPaymentAmountMismatch):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		// 5xx — провайдер повторит доставку уведомления
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
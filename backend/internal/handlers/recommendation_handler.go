@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"netiwash/internal/models"
+	"netiwash/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RecommendationHandler struct {
+	service *service.RecommendationService
+}
+
+func NewRecommendationHandler(service *service.RecommendationService) *RecommendationHandler {
+	return &RecommendationHandler{service: service}
+}
+
+func (h *RecommendationHandler) GetOccupancy(c *gin.Context) {
+	cells, err := h.service.GetOccupancy(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	room := c.Query("room")
+	filtered := make([]models.OccupancyCell, 0, len(cells))
+	for _, cell := range cells {
+		if room == "" || cell.Room == room {
+			filtered = append(filtered, cell)
+		}
+	}
+
+	c.JSON(http.StatusOK, filtered)
+}
+
+func (h *RecommendationHandler) GetQuietSlots(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "5"))
+	if err != nil || limit < 1 || limit > 50 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 50"})
+		return
+	}
+
+	userID := c.GetInt("userID")
+
+	slots, err := h.service.Recommend(c.Request.Context(), userID, c.Query("room"), c.Query("type"), limit)
+	if err != nil {
+		if errors.Is(err, service.ErrBookingLimitReached) {
+			c.JSON(http.StatusOK, gin.H{
+				"slots":         []models.SlotRecommendation{},
+				"quota_reached": true,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"slots":         slots,
+		"quota_reached": false,
+	})
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"netiwash/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TelegramHandler struct {
+	service *service.TelegramService
+}
+
+func NewTelegramHandler(service *service.TelegramService) *TelegramHandler {
+	return &TelegramHandler{service: service}
+}
+
+// GetLink показывает, привязан ли Telegram к аккаунту.
+func (h *TelegramHandler) GetLink(c *gin.Context) {
+	userID := c.GetInt("userID")
+
+	account, err := h.service.GetAccount(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if account == nil {
+		c.JSON(http.StatusOK, gin.H{"linked": false})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"linked": true, "account": account})
+}
+
+// CreateLinkCode выдаёт одноразовый код для команды /start боту.
+func (h *TelegramHandler) CreateLinkCode(c *gin.Context) {
+	userID := c.GetInt("userID")
+
+	code, err := h.service.CreateLinkCode(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, service.ErrTelegramDisabled) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Telegram-бот не настроен"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, code)
+}
+
+func (h *TelegramHandler) Unlink(c *gin.Context) {
+	userID := c.GetInt("userID")
+
+	if err := h.service.Unlink(c.Request.Context(), userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Telegram unlinked"})
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"netiwash/internal/models"
+	"netiwash/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TicketHandler struct {
+	service *service.TicketService
+}
+
+func NewTicketHandler(service *service.TicketService) *TicketHandler {
+	return &TicketHandler{service: service}
+}
+
+func (h *TicketHandler) Report(c *gin.Context) {
+	machineID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid machine ID"})
+		return
+	}
+
+	var req models.FaultReportRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Укажите категорию и описание проблемы"})
+		return
+	}
+
+	var photo []byte
+	if fh, err := c.FormFile("photo"); err == nil {
+		f, err := fh.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Не удалось прочитать фото"})
+			return
+		}
+		defer f.Close()
+		photo, err = io.ReadAll(io.LimitReader(f, 5<<20+1))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Не удалось прочитать фото"})
+			return
+		}
+	}
+
+	ticket, err := h.service.Report(c.Request.Context(), c.GetInt("userID"), machineID, &req, photo)
+	if err != nil {
+		respondTicketError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, ticket)
+}
+
+func (h *TicketHandler) GetMine(c *gin.Context) {
+	tickets, err := h.service.GetByReporter(c.Request.Context(), c.GetInt("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if tickets == nil {
+		tickets = []models.RepairTicket{}
+	}
+	c.JSON(http.StatusOK, tickets)
+}
+
+func (h *TicketHandler) List(c *gin.Context) {
+	machineID, _ := strconv.Atoi(c.Query("machine_id"))
+
+	tickets, err := h.service.List(c.Request.Context(), c.Query("status"), machineID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if tickets == nil {
+		tickets = []models.RepairTicket{}
+	}
+	c.JSON(http.StatusOK, tickets)
+}
+
+func (h *TicketHandler) UpdateStatus(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+
+	var req models.UpdateTicketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status. Must be: acknowledged, in_repair, or resolved"})
+		return
+	}
+
+	ticket, err := h.service.UpdateStatus(c.Request.Context(), id, c.GetInt("userID"), &req)
+	if err != nil {
+		respondTicketError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ticket)
+}
+
+func (h *TicketHandler) GetPhoto(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+
+	photo, contentType, err := h.service.OpenPhoto(c.Request.Context(), id)
+	if err != nil {
+		respondTicketError(c, err)
+		return
+	}
+	defer photo.Close()
+
+	c.DataFromReader(http.StatusOK, -1, contentType, photo, nil)
+}
+
+func respondTicketError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrTicketNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+	case errors.Is(err, service.ErrInvalidTicketTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrUnsupportedPhoto):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		respondMachineError(c, err)
+	}
+}
@@ -0,0 +1,228 @@
+// Package leader выбирает, какой экземпляр бэкенда выполняет фоновые
+// задачи. Лидерство в задаче — сессионная advisory-блокировка PostgreSQL:
+// её держит ровно одно соединение, и она снимается сама, когда процесс или
+// соединение падает, поэтому задачу подхватывает другой экземпляр.
+package leader
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"sync"
+	"time"
+
+	"netiwash/pkg/utils"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// checkInterval — как часто экземпляры пытаются занять свободные задачи,
+	// а лидер продлевает отметку в job_leaders.
+	checkInterval = 10 * time.Second
+	// staleAfter — отметка старше этого срока значит, что лидер пропал и
+	// задачу вот-вот займёт другой экземпляр.
+	staleAfter = 3 * checkInterval
+)
+
+// Job — фоновая задача. Запускается, когда экземпляр становится лидером,
+// работает, пока ctx не отменён, и возвращается, закончив текущую работу.
+type Job func(ctx context.Context)
+
+type job struct {
+	name   string
+	key    int64
+	run    Job
+	cancel context.CancelFunc // не nil, пока этот экземпляр лидер
+	done   chan struct{}      // закрывается, когда run вернулась
+	since  time.Time
+}
+
+// Status — кто ведёт задачу по данным job_leaders.
+type Status struct {
+	Job         string     `json:"job"`
+	NodeID      string     `json:"node_id,omitempty"`
+	AcquiredAt  *time.Time `json:"acquired_at,omitempty"`
+	HeartbeatAt *time.Time `json:"heartbeat_at,omitempty"`
+	Self        bool       `json:"self"`  // лидер — этот экземпляр
+	Stale       bool       `json:"stale"` // лидер давно не отмечался
+}
+
+type Elector struct {
+	db   *pgxpool.Pool
+	node string
+
+	mu   sync.Mutex
+	jobs []*job
+	conn *pgxpool.Conn // держит блокировки всех задач этого экземпляра
+}
+
+func NewElector(db *pgxpool.Pool, nodeID string) *Elector {
+	return &Elector{db: db, node: nodeID}
+}
+
+// Register добавляет задачу. Вызывается до Start.
+func (e *Elector) Register(name string, run Job) {
+	h := fnv.New64a()
+	h.Write([]byte("netiwash:" + name))
+	e.jobs = append(e.jobs, &job{name: name, key: int64(h.Sum64()), run: run})
+}
+
+func (e *Elector) NodeID() string {
+	return e.node
+}
+
+// Start запускает борьбу за лидерство. Первая попытка — сразу, чтобы
+// единственный экземпляр не ждал интервала.
+func (e *Elector) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+		for {
+			e.tick(ctx)
+			select {
+			case <-ctx.Done():
+				e.mu.Lock()
+				e.resign()
+				e.mu.Unlock()
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	log.Printf("👑 [LEADER] Leader election started (node %s, %d jobs)", e.node, len(e.jobs))
+}
+
+func (e *Elector) tick(ctx context.Context) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.campaign(ctx); err != nil && ctx.Err() == nil {
+		log.Printf("👑 [LEADER] Database session lost: %v, stepping down", err)
+		e.resign()
+	}
+}
+
+// campaign занимает свободные задачи и продлевает отметки по своим. Любая
+// ошибка значит, что сессия с блокировками может быть потеряна.
+func (e *Elector) campaign(ctx context.Context) error {
+	if e.conn == nil {
+		conn, err := e.db.Acquire(ctx)
+		if err != nil {
+			return err
+		}
+		e.conn = conn
+	}
+
+	now := time.Now().In(utils.LaundryLocation())
+	for _, j := range e.jobs {
+		if j.cancel == nil {
+			var acquired bool
+			if err := e.conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, j.key).Scan(&acquired); err != nil {
+				return fmt.Errorf("failed to lock %s: %w", j.name, err)
+			}
+			if !acquired {
+				continue
+			}
+			j.since = now
+		}
+
+		_, err := e.conn.Exec(ctx, `
+			INSERT INTO job_leaders (job, node_id, acquired_at, heartbeat_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (job) DO UPDATE
+			SET node_id = EXCLUDED.node_id, acquired_at = EXCLUDED.acquired_at, heartbeat_at = EXCLUDED.heartbeat_at
+		`, j.name, e.node, j.since, now)
+		if err != nil {
+			return fmt.Errorf("failed to record leader of %s: %w", j.name, err)
+		}
+
+		if j.cancel == nil {
+			jobCtx, cancel := context.WithCancel(ctx)
+			done := make(chan struct{})
+			j.cancel, j.done = cancel, done
+			log.Printf("👑 [LEADER] Node %s is now the leader of %s", e.node, j.name)
+			go func(run Job) {
+				defer close(done)
+				run(jobCtx)
+			}(j.run)
+		}
+	}
+	return nil
+}
+
+// resign останавливает свои задачи, дожидается, пока они вернутся, и только
+// потом закрывает соединение, а с ним и блокировки: иначе новый лидер
+// начнёт работу, пока прежний ещё доделывает свою. Вызывается под e.mu.
+func (e *Elector) resign() {
+	for _, j := range e.jobs {
+		if j.cancel != nil {
+			j.cancel()
+		}
+	}
+	for _, j := range e.jobs {
+		if j.cancel != nil {
+			<-j.done
+			j.cancel, j.done = nil, nil
+			log.Printf("👑 [LEADER] Node %s stopped leading %s", e.node, j.name)
+		}
+	}
+	if e.conn != nil {
+		// Соединение с блокировками не должно вернуться в пул.
+		e.conn.Conn().Close(context.Background())
+		e.conn.Release()
+		e.conn = nil
+	}
+}
+
+// Leading возвращает задачи, которые ведёт этот экземпляр.
+func (e *Elector) Leading() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := []string{}
+	for _, j := range e.jobs {
+		if j.cancel != nil {
+			out = append(out, j.name)
+		}
+	}
+	return out
+}
+
+// Leaders возвращает лидеров всех зарегистрированных задач. Ответ одинаков
+// на любом экземпляре, кроме поля Self.
+func (e *Elector) Leaders(ctx context.Context) ([]Status, error) {
+	rows, err := e.db.Query(ctx, `SELECT job, node_id, acquired_at, heartbeat_at FROM job_leaders`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job leaders: %w", err)
+	}
+	defer rows.Close()
+
+	recorded := map[string]Status{}
+	for rows.Next() {
+		var s Status
+		var acquiredAt, heartbeatAt time.Time
+		if err := rows.Scan(&s.Job, &s.NodeID, &acquiredAt, &heartbeatAt); err != nil {
+			return nil, err
+		}
+		acquiredAt, heartbeatAt = utils.InLaundryLocation(acquiredAt), utils.InLaundryLocation(heartbeatAt)
+		s.AcquiredAt, s.HeartbeatAt = &acquiredAt, &heartbeatAt
+		recorded[s.Job] = s
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().In(utils.LaundryLocation())
+	out := make([]Status, 0, len(e.jobs))
+	for _, j := range e.jobs {
+		s, ok := recorded[j.name]
+		if !ok {
+			out = append(out, Status{Job: j.name, Stale: true})
+			continue
+		}
+		s.Self = s.NodeID == e.node
+		s.Stale = now.Sub(*s.HeartbeatAt) > staleAfter
+		out = append(out, s)
+	}
+	return out, nil
+}
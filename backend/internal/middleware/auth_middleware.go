@@ -38,6 +38,27 @@ func (m *AuthMiddleware) RequireAuth(c *gin.Context) {
 	c.Next()
 }
 
+// RequireStreamAuth — RequireAuth для EventSource: браузер не умеет
+// передавать заголовки, поэтому вместо токена можно прислать короткоживущий
+// билет в параметре ?ticket= (его выдаёт POST /api/events/ticket).
+func (m *AuthMiddleware) RequireStreamAuth(c *gin.Context) {
+	ticket := c.Query("ticket")
+	if c.GetHeader("Authorization") != "" || ticket == "" {
+		m.RequireAuth(c)
+		return
+	}
+
+	claims, err := utils.ParseStreamTicket(ticket, m.jwtSecret)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid ticket"})
+		return
+	}
+
+	c.Set("userID", claims.UserID)
+	c.Set("role", claims.Role)
+	c.Next()
+}
+
 func (m *AuthMiddleware) RequireRole(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRole, exists := c.Get("role")
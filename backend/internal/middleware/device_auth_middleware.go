@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"netiwash/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeviceKeyHeader — заголовок с ключом устройства. Также принимается
+// "Authorization: Device <key>".
+const DeviceKeyHeader = "X-Device-Key"
+
+// DeviceAuthMiddleware проверяет ключи контроллеров машин. Пользовательские
+// JWT здесь не принимаются, а ключи устройств не работают в RequireAuth.
+type DeviceAuthMiddleware struct {
+	service *service.DeviceService
+}
+
+func NewDeviceAuthMiddleware(service *service.DeviceService) *DeviceAuthMiddleware {
+	return &DeviceAuthMiddleware{service: service}
+}
+
+func (m *DeviceAuthMiddleware) RequireDevice(c *gin.Context) {
+	key := extractDeviceKey(c)
+	if key == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	device, err := m.service.Authenticate(c.Request.Context(), key)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDeviceKey) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid device key"})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Set("deviceID", device.ID)
+	c.Set("device", device)
+	c.Next()
+}
+
+func extractDeviceKey(c *gin.Context) string {
+	if key := c.GetHeader(DeviceKeyHeader); key != "" {
+		return key
+	}
+
+	parts := strings.Split(c.GetHeader("Authorization"), " ")
+	if len(parts) == 2 && parts[0] == "Device" {
+		return parts[1]
+	}
+	return ""
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Кому адресовано объявление. building и room — пользователи, которые
+// стирали в этом корпусе или комнате за последний месяц либо у которых
+// там есть будущие брони: где живёт пользователь, сервер не знает, поэтому
+// жильцы, не бронировавшие машины там, объявление не получат; bookings —
+// пользователи с бронями в интервале.
+const (
+	AnnouncementTargetAll      = "all"
+	AnnouncementTargetBuilding = "building"
+	AnnouncementTargetRoom     = "room"
+	AnnouncementTargetBookings = "bookings"
+)
+
+type Announcement struct {
+	ID             int        `json:"id"`
+	Title          string     `json:"title"`
+	Body           string     `json:"body"`
+	Target         string     `json:"target"`
+	TargetValue    string     `json:"target_value,omitempty"` // корпус или комната
+	RangeStart     *time.Time `json:"range_start,omitempty"`
+	RangeEnd       *time.Time `json:"range_end,omitempty"`
+	AuthorID       *int       `json:"author_id,omitempty"`
+	RecipientCount int        `json:"recipient_count"`
+	// Audience — кому на деле ушло объявление, словами (для админки).
+	Audience  string    `json:"audience,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AnnouncementRecipient — получатель, которому воркер ещё не разослал
+// объявление.
+type AnnouncementRecipient struct {
+	AnnouncementID int
+	UserID         int
+	Title          string
+	Body           string
+}
+
+type CreateAnnouncementRequest struct {
+	Title  string `json:"title" binding:"required,min=1,max=200"`
+	Body   string `json:"body" binding:"required,min=1,max=2000"`
+	Target string `json:"target" binding:"required,oneof=all building room bookings"`
+	// Building или Room — для соответствующих целей.
+	Building string `json:"building" binding:"max=100"`
+	Room     string `json:"room" binding:"max=100"`
+	// From и To — интервал для цели bookings.
+	From *time.Time `json:"from"`
+	To   *time.Time `json:"to"`
+}
@@ -11,3 +11,9 @@ type Booking struct {
 	Status    string    `json:"status" db:"status"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
+
+// UpcomingBooking — предстоящая бронь с названием машины для напоминаний.
+type UpcomingBooking struct {
+	Booking
+	MachineName string
+}
@@ -0,0 +1,28 @@
+package models
+
+// Что студент может сделать, отсканировав QR-код машины.
+const (
+	ScanActionCheckIn     = "check_in"
+	ScanActionBook        = "book"
+	ScanActionUnavailable = "unavailable"
+)
+
+// MachineLink — параметры подписанной ссылки из QR-кода.
+type MachineLink struct {
+	MachineID int    `json:"machine_id" form:"m" binding:"required,min=1"`
+	KeyID     string `json:"key_id" form:"k" binding:"required"`
+	Signature string `json:"signature" form:"s" binding:"required"`
+}
+
+type ScanResult struct {
+	Machine MachineState `json:"machine"`
+	Action  string       `json:"action"`
+	Booking *Booking     `json:"booking,omitempty"`
+}
+
+// MachineQR — строка печатного листа с QR-кодами комнаты.
+type MachineQR struct {
+	Machine Machine
+	URL     string
+	PNG     []byte
+}
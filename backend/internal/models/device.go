@@ -0,0 +1,54 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	DeviceEventDoorOpen   = "door_open"
+	DeviceEventCycleStart = "cycle_start"
+	DeviceEventCycleEnd   = "cycle_end"
+	DeviceEventError      = "error"
+)
+
+type Device struct {
+	ID         int        `json:"id" db:"id"`
+	Name       string     `json:"name" db:"name"`
+	MachineID  *int       `json:"machine_id" db:"machine_id"`
+	KeyPrefix  string     `json:"key_prefix" db:"key_prefix"`
+	KeyHash    string     `json:"-" db:"key_hash"`
+	CreatedBy  *int       `json:"created_by" db:"created_by"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	LastSeenAt *time.Time `json:"last_seen_at" db:"last_seen_at"`
+	RevokedAt  *time.Time `json:"revoked_at" db:"revoked_at"`
+}
+
+type DeviceEvent struct {
+	ID         int             `json:"id" db:"id"`
+	DeviceID   int             `json:"device_id" db:"device_id"`
+	MachineID  int             `json:"machine_id" db:"machine_id"`
+	Type       string          `json:"type" db:"event_type"`
+	Data       json.RawMessage `json:"data,omitempty" db:"data"`
+	OccurredAt time.Time       `json:"occurred_at" db:"occurred_at"`
+	ReceivedAt time.Time       `json:"received_at" db:"received_at"`
+}
+
+type CreateDeviceRequest struct {
+	Name      string `json:"name" binding:"required,min=1,max=255"`
+	MachineID *int   `json:"machine_id" binding:"omitempty,min=1"`
+}
+
+// CreateDeviceResponse содержит ключ в открытом виде — он показывается
+// только один раз при выпуске.
+type CreateDeviceResponse struct {
+	Device
+	APIKey string `json:"api_key"`
+}
+
+type DeviceEventRequest struct {
+	MachineID  int             `json:"machine_id" binding:"required,min=1"`
+	Type       string          `json:"type" binding:"required,oneof=door_open cycle_start cycle_end error"`
+	OccurredAt *time.Time      `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
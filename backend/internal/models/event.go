@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// Типы событий реального времени.
+const (
+	EventMachineUpdated     = "machine.updated"
+	EventMachineRemoved     = "machine.removed"
+	EventBookingCreated     = "booking.created"
+	EventBookingRescheduled = "booking.rescheduled"
+	EventBookingExtended    = "booking.extended"
+	EventBookingCancelled   = "booking.cancelled"
+	EventBookingCompleted   = "booking.completed"
+	// EventBookingStatus уходит только владельцу брони.
+	EventBookingStatus = "booking.status"
+	// EventNotificationCreated уходит только получателю уведомления.
+	EventNotificationCreated = "notification.created"
+	// EventReset говорит клиенту, что пропущенные события восстановить нельзя
+	// и состояние нужно перезапросить целиком.
+	EventReset = "reset"
+)
+
+// Event — доменное событие для клиентов. UserID != nil — событие видит
+// только этот пользователь, иначе все.
+type Event struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	UserID    *int        `json:"-"`
+	Data      interface{} `json:"data"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// BookingSlotEvent — публичная часть брони: какой слот какой машины занят
+// или освободился, без данных владельца.
+type BookingSlotEvent struct {
+	BookingID int       `json:"booking_id"`
+	MachineID int       `json:"machine_id"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+// BookingStatusEvent — изменение брони для её владельца. Event — публичное
+// событие, которое сопровождает это изменение (booking.created, ...).
+type BookingStatusEvent struct {
+	BookingID    int                  `json:"booking_id"`
+	MachineID    int                  `json:"machine_id"`
+	Event        string               `json:"event"`
+	StartTime    time.Time            `json:"start_time"`
+	EndTime      time.Time            `json:"end_time"`
+	Status       string               `json:"status"`
+	Cancellation *BookingCancellation `json:"cancellation,omitempty"`
+}
+
+// Причины отмены брони не владельцем.
+const (
+	CancelCauseAdmin          = "admin"
+	CancelCauseMachineRemoved = "machine_removed"
+	CancelCauseMaintenance    = "maintenance"
+)
+
+// BookingCancellation — кто и почему отменил бронь, если не сам владелец.
+type BookingCancellation struct {
+	Cause  string `json:"cause"`
+	Reason string `json:"reason"`
+}
@@ -1,9 +1,116 @@
 package models
 
+import "time"
+
+const (
+	MachineStatusFree   = "free"
+	MachineStatusBusy   = "busy"
+	MachineStatusRepair = "repair"
+)
+
+const (
+	StatusSourceAdmin     = "admin"
+	StatusSourceSystem    = "system"
+	StatusSourceTelemetry = "telemetry"
+	StatusSourceDevice    = "device"
+)
+
 type Machine struct {
 	ID       int    `json:"id" db:"id"`
 	Name     string `json:"name" db:"name"`
 	Type     string `json:"type" db:"type"`
 	Status   string `json:"status" db:"status"`
 	IsActive bool   `json:"is_active" db:"is_active"`
+	Room     string `json:"room" db:"room"`
+	Building string `json:"building" db:"building"` // корпус общежития, для объявлений по корпусу
+
+	IsRunning          bool       `json:"is_running" db:"is_running"`
+	LastCycleStartedAt *time.Time `json:"last_cycle_started_at" db:"last_cycle_started_at"`
+	LastCycleEndedAt   *time.Time `json:"last_cycle_ended_at" db:"last_cycle_ended_at"`
+	LastDoorOpenedAt   *time.Time `json:"last_door_opened_at" db:"last_door_opened_at"`
+	CycleCount         int        `json:"cycle_count" db:"cycle_count"`
+
+	CapacityKg *float64          `json:"capacity_kg" db:"capacity_kg"`
+	Brand      string            `json:"brand" db:"brand"`
+	Floor      *int              `json:"floor" db:"floor"`
+	Position   string            `json:"position" db:"position"` // где именно стоит: «у окна, слева»
+	Notes      string            `json:"notes" db:"notes"`
+	Attributes map[string]string `json:"attributes" db:"attributes"` // прочие характеристики без отдельной колонки
+	Photos     []MachinePhoto    `json:"photos"`
+}
+
+type MachinePhoto struct {
+	ID          int       `json:"id" db:"id"`
+	MachineID   int       `json:"machine_id" db:"machine_id"`
+	StorageKey  string    `json:"-" db:"storage_key"`
+	ContentType string    `json:"content_type" db:"content_type"`
+	URL         string    `json:"url"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+type MachineStatusChange struct {
+	ID         int       `json:"id" db:"id"`
+	MachineID  int       `json:"machine_id" db:"machine_id"`
+	FromStatus string    `json:"from_status" db:"from_status"`
+	ToStatus   string    `json:"to_status" db:"to_status"`
+	Reason     string    `json:"reason" db:"reason"`
+	ActorID    *int      `json:"actor_id" db:"actor_id"`
+	ActorLogin string    `json:"actor_login,omitempty" db:"actor_login"`
+	Source     string    `json:"source" db:"source"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+type CreateMachineRequest struct {
+	Name     string `json:"name" binding:"required,min=1,max=255"`
+	Type     string `json:"type" binding:"omitempty,oneof=washing drying"`
+	Status   string `json:"status" binding:"omitempty,oneof=free busy repair"`
+	Room     string `json:"room" binding:"omitempty,max=100"`
+	Building string `json:"building" binding:"max=100"`
+	Reason   string `json:"reason" binding:"max=500"`
+
+	CapacityKg *float64          `json:"capacity_kg" binding:"omitempty,gt=0,lte=50"`
+	Brand      string            `json:"brand" binding:"max=100"`
+	Floor      *int              `json:"floor" binding:"omitempty,min=-5,max=50"`
+	Position   string            `json:"position" binding:"max=255"`
+	Notes      string            `json:"notes" binding:"max=2000"`
+	Attributes map[string]string `json:"attributes" binding:"omitempty,max=20,dive,keys,min=1,max=50,endkeys,max=255"`
+}
+
+// UpdateMachineRequest — частичное обновление: nil-поля не меняются.
+// Attributes заменяется целиком, пустой объект очищает его.
+type UpdateMachineRequest struct {
+	Name     *string `json:"name" binding:"omitempty,min=1,max=255"`
+	Type     *string `json:"type" binding:"omitempty,oneof=washing drying"`
+	Status   *string `json:"status" binding:"omitempty,oneof=free busy repair"`
+	Room     *string `json:"room" binding:"omitempty,min=1,max=100"`
+	Building *string `json:"building" binding:"omitempty,max=100"`
+	Reason   string  `json:"reason" binding:"max=500"`
+
+	CapacityKg *float64          `json:"capacity_kg" binding:"omitempty,gt=0,lte=50"`
+	Brand      *string           `json:"brand" binding:"omitempty,max=100"`
+	Floor      *int              `json:"floor" binding:"omitempty,min=-5,max=50"`
+	Position   *string           `json:"position" binding:"omitempty,max=255"`
+	Notes      *string           `json:"notes" binding:"omitempty,max=2000"`
+	Attributes map[string]string `json:"attributes" binding:"omitempty,max=20,dive,keys,min=1,max=50,endkeys,max=255"`
+}
+
+// Живой статус машины, который видят пользователи. Он вычисляется из брони,
+// телеметрии и ремонта; колонка status остаётся ручным статусом админа.
+const (
+	LiveStatusFree           = "free"
+	LiveStatusReserved       = "reserved"
+	LiveStatusInUse          = "in_use"
+	LiveStatusAwaitingPickup = "awaiting_pickup"
+	LiveStatusRepair         = "repair"
+)
+
+// MachineState — машина в ответе GET /api/machines. Status перекрывает
+// поле встроенной Machine живым статусом, ручной статус отдаётся в
+// operational_status.
+type MachineState struct {
+	Machine
+	Status               string     `json:"status"`
+	OperationalStatus    string     `json:"operational_status"`
+	CurrentBookingEndsAt *time.Time `json:"current_booking_ends_at"`
+	NextFreeAt           *time.Time `json:"next_free_at"`
 }
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+const (
+	MaintenanceTaskOpen = "open"
+	MaintenanceTaskDone = "done"
+)
+
+// MaintenancePlan — регламент обслуживания для машин одного типа: каждые
+// EveryCycles стирок и/или каждые EveryDays дней. Если WindowMinutes > 0,
+// при наступлении срока на машине резервируется окно под работы.
+type MaintenancePlan struct {
+	ID            int       `json:"id" db:"id"`
+	MachineType   string    `json:"machine_type" db:"machine_type"`
+	Name          string    `json:"name" db:"name"`
+	EveryCycles   *int      `json:"every_cycles" db:"every_cycles"`
+	EveryDays     *int      `json:"every_days" db:"every_days"`
+	WindowMinutes int       `json:"window_minutes" db:"window_minutes"`
+	IsActive      bool      `json:"is_active" db:"is_active"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+type MaintenanceTask struct {
+	ID          int        `json:"id" db:"id"`
+	PlanID      int        `json:"plan_id" db:"plan_id"`
+	PlanName    string     `json:"plan_name" db:"plan_name"`
+	MachineID   int        `json:"machine_id" db:"machine_id"`
+	MachineName string     `json:"machine_name" db:"machine_name"`
+	Status      string     `json:"status" db:"status"`
+	CycleCount  int        `json:"cycle_count" db:"cycle_count"`
+	WindowStart *time.Time `json:"window_start" db:"window_start"`
+	WindowEnd   *time.Time `json:"window_end" db:"window_end"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	CompletedAt *time.Time `json:"completed_at" db:"completed_at"`
+	CompletedBy *int       `json:"completed_by" db:"completed_by"`
+}
+
+type MaintenancePlanRequest struct {
+	MachineType   string `json:"machine_type" binding:"required,oneof=washing drying"`
+	Name          string `json:"name" binding:"required,min=1,max=255"`
+	EveryCycles   *int   `json:"every_cycles" binding:"omitempty,min=1"`
+	EveryDays     *int   `json:"every_days" binding:"omitempty,min=1"`
+	WindowMinutes int    `json:"window_minutes" binding:"min=0,max=480"`
+}
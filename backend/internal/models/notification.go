@@ -0,0 +1,133 @@
+package models
+
+import "time"
+
+// Виды уведомлений. booking — письма о брони (подтверждение, перенос,
+// отмена). Для system (отмена брони не по инициативе владельца, закрытие
+// заявки) настройки не действуют — оно всегда уходит пушем.
+const (
+	NotificationKindBooking      = "booking"
+	NotificationKindReminder     = "reminder"
+	NotificationKindCompletion   = "completion"
+	NotificationKindWaitlist     = "waitlist"
+	NotificationKindAnnouncement = "announcement"
+	NotificationKindSystem       = "system"
+)
+
+// Каналы доставки.
+const (
+	ChannelWebPush  = "web_push"
+	ChannelEmail    = "email"
+	ChannelTelegram = "telegram"
+)
+
+// Channels — все каналы, которые можно выбрать в настройках, даже если на
+// этом сервере канал сейчас не подключён.
+var Channels = []string{ChannelWebPush, ChannelEmail, ChannelTelegram}
+
+// NotificationKinds — виды, которые пользователь может настроить.
+var NotificationKinds = []string{
+	NotificationKindBooking,
+	NotificationKindReminder,
+	NotificationKindCompletion,
+	NotificationKindWaitlist,
+	NotificationKindAnnouncement,
+}
+
+// Языки, на которых отрисовываются уведомления.
+const (
+	LocaleRU = "ru"
+	LocaleEN = "en"
+)
+
+var Locales = []string{LocaleRU, LocaleEN}
+
+// NotificationPreferences — куда пользователь хочет получать уведомления.
+// Channels: вид → список каналов; вид, которого нет в карте, идёт по
+// каналам по умолчанию, пустой список отключает вид. Тихие часы задаются
+// как "HH:MM" по времени прачечной и могут переходить через полночь
+// ("23:00"–"07:00"); в это время молчат пуш и Telegram, почта уходит.
+type NotificationPreferences struct {
+	Locale          string              `json:"locale"`
+	Channels        map[string][]string `json:"channels"`
+	QuietHoursStart *string             `json:"quiet_hours_start"`
+	QuietHoursEnd   *string             `json:"quiet_hours_end"`
+	UpdatedAt       *time.Time          `json:"updated_at,omitempty"`
+}
+
+// Статусы доставки уведомления. pending — ещё не пробовали (или процесс
+// упал посреди отправки), failed — ждёт повтора, dead — попытки кончились
+// или ошибка неисправима.
+const (
+	DeliveryStatusPending = "pending"
+	DeliveryStatusSent    = "sent"
+	DeliveryStatusFailed  = "failed"
+	DeliveryStatusDead    = "dead"
+)
+
+// NotificationDelivery — одна доставка уведомления по одному каналу. Для
+// пуша это одна подписка (устройство): SubscriptionID обнуляется, когда
+// подписку удаляют.
+type NotificationDelivery struct {
+	ID             int          `json:"id"`
+	UserID         int          `json:"user_id"`
+	Kind           string       `json:"kind"`
+	Channel        string       `json:"channel"`
+	SubscriptionID *int         `json:"subscription_id,omitempty"`
+	Message        string       `json:"message"`
+	Payload        *PushPayload `json:"payload,omitempty"`
+	Status         string       `json:"status"`
+	Attempts       int          `json:"attempts"`
+	LastError      *string      `json:"last_error,omitempty"`
+	NextAttemptAt  *time.Time   `json:"next_attempt_at,omitempty"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+// InboxNotification — уведомление во входящих пользователя. Сохраняется
+// при каждой отправке, независимо от каналов и тихих часов.
+type InboxNotification struct {
+	ID        int        `json:"id"`
+	Kind      string     `json:"kind"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	URL       string     `json:"url,omitempty"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Действия — кнопки в пуш-уведомлении.
+const (
+	NotificationActionExtend   = "extend"    // продлить бронь
+	NotificationActionPickedUp = "picked_up" // бельё забрали, машина свободна
+)
+
+// Notification — уведомление до отрисовки: шаблон и параметры. Текст
+// собирается на языке получателя в момент отправки.
+type Notification struct {
+	Kind      string
+	Template  string
+	Params    map[string]any
+	URL       string // страница, которую открывает нажатие
+	Tag       string // уведомление с тем же тегом заменяет предыдущее
+	BookingID int    // бронь, над которой выполняются действия
+	Actions   []string
+}
+
+// PushPayload — содержимое пуша, которое разбирает service worker.
+type PushPayload struct {
+	Title   string       `json:"title"`
+	Body    string       `json:"body"`
+	Icon    string       `json:"icon,omitempty"`
+	URL     string       `json:"url,omitempty"`
+	Tag     string       `json:"tag,omitempty"`
+	Actions []PushAction `json:"actions,omitempty"`
+}
+
+// PushAction — кнопка уведомления. Token подписан сервером и передаётся в
+// POST /api/notifications/actions: service worker не знает JWT пользователя.
+type PushAction struct {
+	Action string `json:"action"`
+	Title  string `json:"title"`
+	Token  string `json:"token"`
+}
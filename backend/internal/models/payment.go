@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+const (
+	PaymentStatusPending   = "pending"
+	PaymentStatusSucceeded = "succeeded"
+	PaymentStatusCanceled  = "canceled"
+)
+
+type Payment struct {
+	ID                int        `json:"id" db:"id"`
+	UserID            int        `json:"user_id" db:"user_id"`
+	ProviderPaymentID string     `json:"provider_payment_id" db:"provider_payment_id"`
+	IdempotenceKey    string     `json:"-" db:"idempotence_key"`
+	Credits           int        `json:"credits" db:"credits"`
+	Amount            int        `json:"amount" db:"amount"`
+	Currency          string     `json:"currency" db:"currency"`
+	Status            string     `json:"status" db:"status"`
+	ConfirmationURL   string     `json:"confirmation_url" db:"confirmation_url"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	PaidAt            *time.Time `json:"paid_at" db:"paid_at"`
+}
+
+type Wallet struct {
+	UserID    int       `json:"user_id" db:"user_id"`
+	Balance   int       `json:"balance" db:"balance"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type WalletTransaction struct {
+	ID        int       `json:"id" db:"id"`
+	UserID    int       `json:"user_id" db:"user_id"`
+	Amount    int       `json:"amount" db:"amount"`
+	Reason    string    `json:"reason" db:"reason"`
+	PaymentID *int      `json:"payment_id" db:"payment_id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type CheckoutRequest struct {
+	Credits int `json:"credits" binding:"required,min=1,max=100"`
+}
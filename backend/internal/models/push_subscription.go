@@ -3,10 +3,33 @@ package models
 import "time"
 
 type PushSubscription struct {
-	ID        int       `json:"id" db:"id"`
-	UserID    int       `json:"user_id" db:"user_id"`
-	Endpoint  string    `json:"endpoint" db:"endpoint"`
-	P256dh    string    `json:"keys_p256dh" db:"p256dh"`
-	Auth      string    `json:"keys_auth" db:"auth"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	ID            int        `json:"id" db:"id"`
+	UserID        int        `json:"user_id" db:"user_id"`
+	Endpoint      string     `json:"endpoint" db:"endpoint"`
+	P256dh        string     `json:"keys_p256dh" db:"p256dh"`
+	Auth          string     `json:"keys_auth" db:"auth"`
+	UserAgent     string     `json:"user_agent" db:"user_agent"`
+	VAPIDKey      string     `json:"vapid_public_key" db:"vapid_public_key"` // ключ, с которым браузер подписался
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	LastSuccessAt *time.Time `json:"last_success_at" db:"last_success_at"`
+}
+
+// PushDevice — подписка в списке устройств пользователя, без ключей.
+type PushDevice struct {
+	ID            int        `json:"id"`
+	UserAgent     string     `json:"user_agent"`
+	Service       string     `json:"service"` // хост push-сервиса: fcm.googleapis.com, updates.push.services.mozilla.com, ...
+	CreatedAt     time.Time  `json:"created_at"`
+	LastSuccessAt *time.Time `json:"last_success_at"`
+}
+
+// VAPIDKey — пара ключей, которой сервер подписывает пуши. Активный ключ
+// раздаётся браузерам для новых подписок; выведенные из оборота остаются,
+// пока на них есть подписки, и удаляются командой vapid_keys prune.
+type VAPIDKey struct {
+	PublicKey  string     `json:"public_key"`
+	PrivateKey string     `json:"private_key"`
+	Active     bool       `json:"active"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RetiredAt  *time.Time `json:"retired_at,omitempty"`
 }
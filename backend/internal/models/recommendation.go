@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type OccupancyCell struct {
+	Room      string  `json:"room"`
+	Weekday   int     `json:"weekday"` // 1 = понедельник ... 7 = воскресенье
+	Hour      int     `json:"hour"`
+	Bookings  int     `json:"bookings"`
+	Occupancy float64 `json:"occupancy"` // проценты, 0..100
+}
+
+type SlotRecommendation struct {
+	MachineID   int       `json:"machine_id"`
+	MachineName string    `json:"machine_name"`
+	Room        string    `json:"room"`
+	StartTime   time.Time `json:"start_time"`
+	EndTime     time.Time `json:"end_time"`
+	Occupancy   float64   `json:"occupancy"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+const (
+	TicketStatusOpen         = "open"
+	TicketStatusAcknowledged = "acknowledged"
+	TicketStatusInRepair     = "in_repair"
+	TicketStatusResolved     = "resolved"
+)
+
+type RepairTicket struct {
+	ID          int        `json:"id" db:"id"`
+	MachineID   int        `json:"machine_id" db:"machine_id"`
+	ReporterID  *int       `json:"reporter_id" db:"reporter_id"`
+	Category    string     `json:"category" db:"category"`
+	Description string     `json:"description" db:"description"`
+	PhotoPath   string     `json:"-" db:"photo_path"`
+	HasPhoto    bool       `json:"has_photo" db:"-"`
+	Status      string     `json:"status" db:"status"`
+	AdminNote   string     `json:"admin_note" db:"admin_note"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	ResolvedAt  *time.Time `json:"resolved_at" db:"resolved_at"`
+}
+
+type FaultReportRequest struct {
+	Category    string `form:"category" json:"category" binding:"required,oneof=leak not_spinning not_draining door power noise other"`
+	Description string `form:"description" json:"description" binding:"required,min=3,max=2000"`
+}
+
+type UpdateTicketRequest struct {
+	Status string `json:"status" binding:"required,oneof=acknowledged in_repair resolved"`
+	Note   string `json:"note" binding:"max=2000"`
+}
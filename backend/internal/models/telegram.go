@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// TelegramAccount — привязка пользователя к чату с ботом.
+type TelegramAccount struct {
+	UserID   int       `json:"-"`
+	ChatID   int64     `json:"chat_id"`
+	Username string    `json:"username,omitempty"`
+	LinkedAt time.Time `json:"linked_at"`
+}
+
+// TelegramLinkCode — одноразовый код, который пользователь отправляет боту.
+type TelegramLinkCode struct {
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// DeepLink открывает бота сразу с кодом, если задано имя бота.
+	DeepLink string `json:"deep_link,omitempty"`
+}
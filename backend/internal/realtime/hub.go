@@ -0,0 +1,138 @@
+// Package realtime раздаёт доменные события подключённым клиентам (SSE).
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"netiwash/internal/models"
+)
+
+const (
+	// historySize — сколько последних событий хранится, чтобы дослать их по Last-Event-ID.
+	historySize = 512
+	// subscriberBuffer — очередь одного клиента. Клиент, который не успевает
+	// читать, отключается и переподключается с Last-Event-ID.
+	subscriberBuffer = 64
+)
+
+// Subscription — подписка одного соединения.
+type Subscription struct {
+	userID int
+	ch     chan models.Event
+}
+
+func (s *Subscription) Events() <-chan models.Event {
+	return s.ch
+}
+
+// Hub хранит недавнюю историю событий и рассылает новые подписчикам.
+// ID событий имеют вид "<epoch>-<seq>": epoch меняется при каждом запуске,
+// поэтому Last-Event-ID от прошлого процесса приводит к событию reset.
+type Hub struct {
+	mu      sync.Mutex
+	epoch   string
+	seq     int64
+	history []models.Event
+	subs    map[*Subscription]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		epoch: strconv.FormatInt(time.Now().UnixNano(), 36),
+		subs:  make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish присваивает событию ID и рассылает его.
+func (h *Hub) Publish(ctx context.Context, e models.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	e.ID = fmt.Sprintf("%s-%d", h.epoch, h.seq)
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+
+	h.history = append(h.history, e)
+	if len(h.history) > historySize {
+		h.history = h.history[len(h.history)-historySize:]
+	}
+
+	for sub := range h.subs {
+		if !visibleTo(e, sub.userID) {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			delete(h.subs, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// Subscribe подписывает пользователя. Если передан lastEventID, сначала
+// возвращаются пропущенные события; если их уже нет в истории — одно
+// событие reset.
+func (h *Hub) Subscribe(userID int, lastEventID string) (*Subscription, []models.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub := &Subscription{userID: userID, ch: make(chan models.Event, subscriberBuffer)}
+	h.subs[sub] = struct{}{}
+
+	if lastEventID == "" {
+		return sub, nil
+	}
+	return sub, h.missedLocked(userID, lastEventID)
+}
+
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subs[sub]; ok {
+		delete(h.subs, sub)
+		close(sub.ch)
+	}
+}
+
+func (h *Hub) missedLocked(userID int, lastEventID string) []models.Event {
+	reset := []models.Event{{
+		ID:        fmt.Sprintf("%s-%d", h.epoch, h.seq),
+		Type:      models.EventReset,
+		CreatedAt: time.Now(),
+	}}
+
+	epoch, seqStr, ok := strings.Cut(lastEventID, "-")
+	seq, err := strconv.ParseInt(seqStr, 10, 64)
+	if !ok || err != nil || epoch != h.epoch || seq > h.seq {
+		return reset
+	}
+	if seq == h.seq {
+		return nil
+	}
+	// Первое пропущенное событие должно ещё лежать в истории.
+	oldest := h.seq - int64(len(h.history)) + 1
+	if seq+1 < oldest {
+		return reset
+	}
+
+	var missed []models.Event
+	for _, e := range h.history[seq+1-oldest:] {
+		if visibleTo(e, userID) {
+			missed = append(missed, e)
+		}
+	}
+	return missed
+}
+
+func visibleTo(e models.Event, userID int) bool {
+	return e.UserID == nil || *e.UserID == userID
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"netiwash/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AnnouncementRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAnnouncementRepository(db *pgxpool.Pool) *AnnouncementRepository {
+	return &AnnouncementRepository{db: db}
+}
+
+const announcementColumns = `a.id, a.title, a.body, a.target, COALESCE(a.target_value, ''), a.range_start, a.range_end,
+	a.author_id, a.recipient_count, a.created_at`
+
+func scanAnnouncement(row pgx.Row, a *models.Announcement) error {
+	return row.Scan(&a.ID, &a.Title, &a.Body, &a.Target, &a.TargetValue, &a.RangeStart, &a.RangeEnd,
+		&a.AuthorID, &a.RecipientCount, &a.CreatedAt)
+}
+
+// audience возвращает запрос, выбирающий ID получателей объявления, и его
+// аргументы. $1 в запросе занят ID объявления.
+func audience(a *models.Announcement, activeSince time.Time) (string, []any, error) {
+	switch a.Target {
+	case models.AnnouncementTargetAll:
+		return `SELECT id FROM users`, nil, nil
+	case models.AnnouncementTargetBuilding, models.AnnouncementTargetRoom:
+		column := "m.building"
+		if a.Target == models.AnnouncementTargetRoom {
+			column = "m.room"
+		}
+		query := `
+			SELECT DISTINCT b.user_id FROM bookings b
+			JOIN machines m ON m.id = b.machine_id
+			WHERE ` + column + ` = $2
+			  AND b.status IN ('active', 'completed')
+			  AND b.end_time >= $3`
+		return query, []any{a.TargetValue, activeSince}, nil
+	case models.AnnouncementTargetBookings:
+		query := `
+			SELECT DISTINCT user_id FROM bookings
+			WHERE status IN ('active', 'completed')
+			  AND start_time < $3
+			  AND end_time > $2`
+		return query, []any{a.RangeStart, a.RangeEnd}, nil
+	}
+	return "", nil, fmt.Errorf("unknown announcement target %q", a.Target)
+}
+
+// Create сохраняет объявление вместе со списком получателей и возвращает
+// их ID. activeSince — с какого момента брони в корпусе или комнате делают
+// пользователя получателем.
+func (r *AnnouncementRepository) Create(ctx context.Context, a *models.Announcement, activeSince time.Time) ([]int, error) {
+	audienceQuery, audienceArgs, err := audience(a, activeSince)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO announcements (title, body, target, target_value, range_start, range_end, author_id, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8)
+		RETURNING id
+	`
+	err = tx.QueryRow(ctx, query, a.Title, a.Body, a.Target, a.TargetValue, a.RangeStart, a.RangeEnd, a.AuthorID, a.CreatedAt).
+		Scan(&a.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create announcement: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `
+		INSERT INTO announcement_recipients (announcement_id, user_id)
+		SELECT $1, id FROM (`+audienceQuery+`) AS audience(id)
+		RETURNING user_id`, append([]any{a.ID}, audienceArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select recipients: %w", err)
+	}
+	var recipients []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		recipients = append(recipients, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to select recipients: %w", err)
+	}
+
+	a.RecipientCount = len(recipients)
+	if _, err := tx.Exec(ctx, `UPDATE announcements SET recipient_count = $1 WHERE id = $2`, a.RecipientCount, a.ID); err != nil {
+		return nil, fmt.Errorf("failed to create announcement: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return recipients, nil
+}
+
+// GetPending возвращает до limit получателей, которым объявление ещё не
+// разослано, начиная со старых объявлений.
+func (r *AnnouncementRepository) GetPending(ctx context.Context, limit int) ([]models.AnnouncementRecipient, error) {
+	query := `
+		SELECT ar.announcement_id, ar.user_id, a.title, a.body
+		FROM announcement_recipients ar
+		JOIN announcements a ON a.id = ar.announcement_id
+		WHERE ar.notified_at IS NULL
+		ORDER BY ar.announcement_id, ar.user_id
+		LIMIT $1
+	`
+	rows, err := r.db.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending recipients: %w", err)
+	}
+	defer rows.Close()
+
+	var out []models.AnnouncementRecipient
+	for rows.Next() {
+		var rcp models.AnnouncementRecipient
+		if err := rows.Scan(&rcp.AnnouncementID, &rcp.UserID, &rcp.Title, &rcp.Body); err != nil {
+			return nil, err
+		}
+		out = append(out, rcp)
+	}
+	return out, rows.Err()
+}
+
+// MarkNotified отмечает, что уведомление получателю записано в журнал доставок.
+func (r *AnnouncementRepository) MarkNotified(ctx context.Context, announcementID, userID int, at time.Time) error {
+	query := `UPDATE announcement_recipients SET notified_at = $3 WHERE announcement_id = $1 AND user_id = $2`
+	if _, err := r.db.Exec(ctx, query, announcementID, userID, at); err != nil {
+		return fmt.Errorf("failed to mark recipient notified: %w", err)
+	}
+	return nil
+}
+
+// GetForUser возвращает объявления, адресованные пользователю, новее since.
+func (r *AnnouncementRepository) GetForUser(ctx context.Context, userID int, since time.Time, limit int) ([]models.Announcement, error) {
+	query := `
+		SELECT ` + announcementColumns + `
+		FROM announcements a
+		JOIN announcement_recipients ar ON ar.announcement_id = a.id
+		WHERE ar.user_id = $1 AND a.created_at >= $2
+		ORDER BY a.created_at DESC, a.id DESC
+		LIMIT $3
+	`
+	return r.list(ctx, query, userID, since, limit)
+}
+
+// GetRecent — все объявления для админки.
+func (r *AnnouncementRepository) GetRecent(ctx context.Context, limit int) ([]models.Announcement, error) {
+	query := `
+		SELECT ` + announcementColumns + `
+		FROM announcements a
+		ORDER BY a.created_at DESC, a.id DESC
+		LIMIT $1
+	`
+	return r.list(ctx, query, limit)
+}
+
+func (r *AnnouncementRepository) list(ctx context.Context, query string, args ...any) ([]models.Announcement, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get announcements: %w", err)
+	}
+	defer rows.Close()
+
+	var out []models.Announcement
+	for rows.Next() {
+		var a models.Announcement
+		if err := scanAnnouncement(rows, &a); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
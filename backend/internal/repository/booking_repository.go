@@ -7,6 +7,7 @@ import (
 
 	"netiwash/internal/models"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -84,14 +85,24 @@ func (r *BookingRepository) Cancel(ctx context.Context, id int) error {
 	return err
 }
 
+// CheckAvailability проверяет, что слот не пересекается с активными бронями
+// и открытыми окнами обслуживания машины.
 func (r *BookingRepository) CheckAvailability(ctx context.Context, machineID int, start, end time.Time) (bool, error) {
 	query := `
-		SELECT COUNT(*)
-		FROM bookings
-		WHERE machine_id = $1
-		  AND status = 'active'
-		  AND start_time < $3
-		  AND end_time > $2
+		SELECT
+			(SELECT COUNT(*)
+			 FROM bookings
+			 WHERE machine_id = $1
+			   AND status = 'active'
+			   AND start_time < $3
+			   AND end_time > $2)
+			+
+			(SELECT COUNT(*)
+			 FROM maintenance_tasks
+			 WHERE machine_id = $1
+			   AND status = 'open'
+			   AND window_start < $3
+			   AND window_end > $2)
 	`
 	var count int
 	err := r.db.QueryRow(ctx, query, machineID, start, end).Scan(&count)
@@ -120,17 +131,110 @@ func (r *BookingRepository) GetByID(ctx context.Context, id int) (*models.Bookin
 	var b models.Booking
 	err := r.db.QueryRow(ctx, query, id).Scan(&b.ID, &b.UserID, &b.MachineID, &b.StartTime, &b.EndTime, &b.Status, &b.CreatedAt)
 	if err != nil {
-		return nil, fmt.Errorf("booking not found: %w", err)
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get booking: %w", err)
 	}
 	return &b, nil
 }
 
+// Reschedule переносит активную бронь на [start, end), если слот не занят
+// другими бронями и окнами обслуживания той же машины. Проверка и перенос
+// — один запрос, поэтому два переноса на один слот не пройдут оба.
+// Возвращает false, если слот занят.
+func (r *BookingRepository) Reschedule(ctx context.Context, id int, start, end time.Time) (bool, error) {
+	query := `
+		UPDATE bookings b
+		SET start_time = $2, end_time = $3, checked_in_at = NULL
+		WHERE b.id = $1
+		  AND b.status = 'active'
+		  AND NOT EXISTS (
+			SELECT 1 FROM bookings o
+			WHERE o.machine_id = b.machine_id
+			  AND o.id <> b.id
+			  AND o.status = 'active'
+			  AND o.start_time < $3
+			  AND o.end_time > $2)
+		  AND NOT EXISTS (
+			SELECT 1 FROM maintenance_tasks t
+			WHERE t.machine_id = b.machine_id
+			  AND t.status = 'open'
+			  AND t.window_start < $3
+			  AND t.window_end > $2)
+	`
+	tag, err := r.db.Exec(ctx, query, id, start, end)
+	if err != nil {
+		return false, fmt.Errorf("failed to reschedule booking: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Extend сдвигает конец активной брони на end, если это время не занято
+// другой бронью или обслуживанием.
+func (r *BookingRepository) Extend(ctx context.Context, id int, end time.Time) (bool, error) {
+	query := `
+		UPDATE bookings b
+		SET end_time = $2
+		WHERE b.id = $1
+		  AND b.status = 'active'
+		  AND NOT EXISTS (
+			SELECT 1 FROM bookings o
+			WHERE o.machine_id = b.machine_id
+			  AND o.id <> b.id
+			  AND o.status = 'active'
+			  AND o.start_time < $2
+			  AND o.end_time > b.start_time)
+		  AND NOT EXISTS (
+			SELECT 1 FROM maintenance_tasks t
+			WHERE t.machine_id = b.machine_id
+			  AND t.status = 'open'
+			  AND t.window_start < $2
+			  AND t.window_end > b.start_time)
+	`
+	tag, err := r.db.Exec(ctx, query, id, end)
+	if err != nil {
+		return false, fmt.Errorf("failed to extend booking: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
 func (r *BookingRepository) UpdateStatus(ctx context.Context, id int, status string) error {
 	query := `UPDATE bookings SET status = $1 WHERE id = $2`
 	_, err := r.db.Exec(ctx, query, status, id)
 	return err
 }
 
+// Complete завершает активную бронь. Для машин без телеметрии (ни одного
+// цикла по датчикам) завершение брони считается стиркой и увеличивает
+// cycle_count; машины с телеметрией считают циклы сами.
+func (r *BookingRepository) Complete(ctx context.Context, id int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var machineID *int
+	err = tx.QueryRow(ctx, `UPDATE bookings SET status = 'completed' WHERE id = $1 AND status = 'active' RETURNING machine_id`, id).
+		Scan(&machineID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil
+		}
+		return fmt.Errorf("failed to complete booking: %w", err)
+	}
+
+	if machineID != nil {
+		_, err = tx.Exec(ctx, `UPDATE machines SET cycle_count = cycle_count + 1 WHERE id = $1 AND last_cycle_ended_at IS NULL`, *machineID)
+		if err != nil {
+			return fmt.Errorf("failed to count cycle: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
 func (r *BookingRepository) GetExpiredActiveBookings(ctx context.Context) ([]models.Booking, error) {
 	query := `
 		SELECT id, user_id, machine_id, start_time, end_time, status, created_at
@@ -154,10 +258,27 @@ func (r *BookingRepository) GetExpiredActiveBookings(ctx context.Context) ([]mod
 	return bookings, nil
 }
 
-func (r *BookingRepository) MarkPushSent(ctx context.Context, id int) error {
-	query := `UPDATE bookings SET push_sent = TRUE WHERE id = $1`
-	_, err := r.db.Exec(ctx, query, id)
-	return err
+// ClaimPushSent отмечает уведомление об окончании стирки как отправленное.
+// true — отметку поставил этот вызов и отправлять должен он: воркер на
+// лидере и обработчик телеметрии с устройства могут дойти до брони
+// одновременно.
+func (r *BookingRepository) ClaimPushSent(ctx context.Context, id int) (bool, error) {
+	query := `UPDATE bookings SET push_sent = TRUE WHERE id = $1 AND push_sent = FALSE`
+	tag, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim completion push: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ReleasePushSent снимает отметку, если уведомление так и не удалось
+// поставить в очередь, чтобы его повторил воркер.
+func (r *BookingRepository) ReleasePushSent(ctx context.Context, id int) error {
+	_, err := r.db.Exec(ctx, `UPDATE bookings SET push_sent = FALSE WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to release completion push: %w", err)
+	}
+	return nil
 }
 
 func (r *BookingRepository) GetCompletedUnnotifiedBookings(ctx context.Context) ([]models.Booking, error) {
@@ -182,3 +303,199 @@ func (r *BookingRepository) GetCompletedUnnotifiedBookings(ctx context.Context)
 	}
 	return bookings, nil
 }
+
+// GetHourlyCounts считает брони в интервале [since, until) по комнате,
+// дню недели (ISO) и часу начала.
+func (r *BookingRepository) GetHourlyCounts(ctx context.Context, since, until time.Time) ([]models.OccupancyCell, error) {
+	query := `
+		SELECT m.room,
+		       EXTRACT(ISODOW FROM b.start_time)::int AS weekday,
+		       EXTRACT(HOUR FROM b.start_time)::int AS hour,
+		       COUNT(*)
+		FROM bookings b
+		JOIN machines m ON m.id = b.machine_id
+		WHERE b.start_time >= $1 AND b.start_time < $2
+		  AND b.status IN ('active', 'completed')
+		GROUP BY m.room, weekday, hour
+	`
+	rows, err := r.db.Query(ctx, query, since, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate bookings: %w", err)
+	}
+	defer rows.Close()
+
+	var cells []models.OccupancyCell
+	for rows.Next() {
+		var c models.OccupancyCell
+		if err := rows.Scan(&c.Room, &c.Weekday, &c.Hour, &c.Bookings); err != nil {
+			return nil, fmt.Errorf("row scan error: %w", err)
+		}
+		cells = append(cells, c)
+	}
+	return cells, rows.Err()
+}
+
+func (r *BookingRepository) GetActiveInRange(ctx context.Context, from, to time.Time) ([]models.Booking, error) {
+	query := `
+		SELECT id, user_id, machine_id, start_time, end_time, status, created_at
+		FROM bookings
+		WHERE status = 'active' AND start_time < $2 AND end_time > $1
+		ORDER BY start_time
+	`
+	rows, err := r.db.Query(ctx, query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("repository query error: %w", err)
+	}
+	defer rows.Close()
+
+	var bookings []models.Booking
+	for rows.Next() {
+		var b models.Booking
+		if err := rows.Scan(&b.ID, &b.UserID, &b.MachineID, &b.StartTime, &b.EndTime, &b.Status, &b.CreatedAt); err != nil {
+			return nil, fmt.Errorf("row scan error: %w", err)
+		}
+		bookings = append(bookings, b)
+	}
+	return bookings, rows.Err()
+}
+
+// GetUnnotifiedForCycle ищет бронь машины, которая пересекается с циклом
+// стирки [from, to] и по которой ещё не отправлено уведомление о завершении.
+func (r *BookingRepository) GetUnnotifiedForCycle(ctx context.Context, machineID int, from, to time.Time) (*models.Booking, error) {
+	query := `
+		SELECT id, user_id, machine_id, start_time, end_time, status, created_at
+		FROM bookings
+		WHERE machine_id = $1
+		  AND status IN ('active', 'completed')
+		  AND push_sent = FALSE
+		  AND start_time <= $3 AND end_time >= $2
+		ORDER BY start_time DESC
+		LIMIT 1
+	`
+	var b models.Booking
+	err := r.db.QueryRow(ctx, query, machineID, from, to).Scan(&b.ID, &b.UserID, &b.MachineID, &b.StartTime, &b.EndTime, &b.Status, &b.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find booking for cycle: %w", err)
+	}
+	return &b, nil
+}
+
+// GetCurrentForMachine возвращает активную бронь машины, слот которой идёт в момент at.
+func (r *BookingRepository) GetCurrentForMachine(ctx context.Context, machineID int, at time.Time) (*models.Booking, error) {
+	query := `
+		SELECT id, user_id, machine_id, start_time, end_time, status, created_at
+		FROM bookings
+		WHERE machine_id = $1 AND status = 'active' AND start_time <= $2 AND end_time > $2
+		ORDER BY start_time
+		LIMIT 1
+	`
+	var b models.Booking
+	err := r.db.QueryRow(ctx, query, machineID, at).Scan(&b.ID, &b.UserID, &b.MachineID, &b.StartTime, &b.EndTime, &b.Status, &b.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get current booking: %w", err)
+	}
+	return &b, nil
+}
+
+// MarkCheckedIn отмечает, что владелец брони подошёл к машине. Повторная
+// отметка не перезаписывает время первой.
+func (r *BookingRepository) MarkCheckedIn(ctx context.Context, id int, at time.Time) error {
+	query := `UPDATE bookings SET checked_in_at = COALESCE(checked_in_at, $1) WHERE id = $2`
+	_, err := r.db.Exec(ctx, query, at, id)
+	if err != nil {
+		return fmt.Errorf("failed to check in booking: %w", err)
+	}
+	return nil
+}
+
+// GetUpcomingActive возвращает активные брони, которые начинаются в
+// интервале (from, to].
+func (r *BookingRepository) GetUpcomingActive(ctx context.Context, from, to time.Time) ([]models.UpcomingBooking, error) {
+	query := `
+		SELECT b.id, b.user_id, b.machine_id, b.start_time, b.end_time, b.status, b.created_at, m.name
+		FROM bookings b
+		JOIN machines m ON m.id = b.machine_id
+		WHERE b.status = 'active' AND b.start_time > $1 AND b.start_time <= $2
+		ORDER BY b.start_time
+	`
+	rows, err := r.db.Query(ctx, query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("repository query error: %w", err)
+	}
+	defer rows.Close()
+
+	var bookings []models.UpcomingBooking
+	for rows.Next() {
+		var b models.UpcomingBooking
+		if err := rows.Scan(&b.ID, &b.UserID, &b.MachineID, &b.StartTime, &b.EndTime, &b.Status, &b.CreatedAt, &b.MachineName); err != nil {
+			return nil, fmt.Errorf("row scan error: %w", err)
+		}
+		bookings = append(bookings, b)
+	}
+	return bookings, rows.Err()
+}
+
+// ClaimReminder отмечает напоминание за offsetMinutes до startTime как
+// отправленное. Возвращает false, если его уже отправили (в том числе
+// другой экземпляр). Время начала входит в ключ, поэтому после переноса
+// брони напоминания уходят заново.
+func (r *BookingRepository) ClaimReminder(ctx context.Context, bookingID, offsetMinutes int, startTime time.Time) (bool, error) {
+	query := `
+		INSERT INTO booking_reminders (booking_id, offset_minutes, start_time)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`
+	tag, err := r.db.Exec(ctx, query, bookingID, offsetMinutes, startTime)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim reminder: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// GetEndingActive возвращает идущие брони (начались не позже now), которые
+// заканчиваются в интервале (now, to].
+func (r *BookingRepository) GetEndingActive(ctx context.Context, now, to time.Time) ([]models.UpcomingBooking, error) {
+	query := `
+		SELECT b.id, b.user_id, b.machine_id, b.start_time, b.end_time, b.status, b.created_at, m.name
+		FROM bookings b
+		JOIN machines m ON m.id = b.machine_id
+		WHERE b.status = 'active' AND b.start_time <= $1 AND b.end_time > $1 AND b.end_time <= $2
+		ORDER BY b.end_time
+	`
+	rows, err := r.db.Query(ctx, query, now, to)
+	if err != nil {
+		return nil, fmt.Errorf("repository query error: %w", err)
+	}
+	defer rows.Close()
+
+	var bookings []models.UpcomingBooking
+	for rows.Next() {
+		var b models.UpcomingBooking
+		if err := rows.Scan(&b.ID, &b.UserID, &b.MachineID, &b.StartTime, &b.EndTime, &b.Status, &b.CreatedAt, &b.MachineName); err != nil {
+			return nil, fmt.Errorf("row scan error: %w", err)
+		}
+		bookings = append(bookings, b)
+	}
+	return bookings, rows.Err()
+}
+
+// ClaimEndingNotice отмечает уведомление о скором окончании брони как
+// отправленное. Время окончания входит в ключ, как и у ClaimReminder.
+func (r *BookingRepository) ClaimEndingNotice(ctx context.Context, bookingID int, endTime time.Time) (bool, error) {
+	query := `
+		INSERT INTO booking_ending_notices (booking_id, end_time)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+	tag, err := r.db.Exec(ctx, query, bookingID, endTime)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim ending notice: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
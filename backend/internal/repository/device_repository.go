@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"netiwash/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type DeviceRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewDeviceRepository(db *pgxpool.Pool) *DeviceRepository {
+	return &DeviceRepository{db: db}
+}
+
+const deviceColumns = `id, name, machine_id, key_prefix, key_hash, created_by, created_at, last_seen_at, revoked_at`
+
+func scanDevice(row pgx.Row) (*models.Device, error) {
+	var d models.Device
+	err := row.Scan(&d.ID, &d.Name, &d.MachineID, &d.KeyPrefix, &d.KeyHash, &d.CreatedBy, &d.CreatedAt, &d.LastSeenAt, &d.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *DeviceRepository) Create(ctx context.Context, d *models.Device) error {
+	query := `
+		INSERT INTO devices (name, machine_id, key_prefix, key_hash, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	err := r.db.QueryRow(ctx, query, d.Name, d.MachineID, d.KeyPrefix, d.KeyHash, d.CreatedBy).Scan(&d.ID, &d.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create device: %w", err)
+	}
+	return nil
+}
+
+func (r *DeviceRepository) GetByKeyPrefix(ctx context.Context, prefix string) (*models.Device, error) {
+	query := `SELECT ` + deviceColumns + ` FROM devices WHERE key_prefix = $1`
+	d, err := scanDevice(r.db.QueryRow(ctx, query, prefix))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get device: %w", err)
+	}
+	return d, nil
+}
+
+func (r *DeviceRepository) GetAll(ctx context.Context) ([]models.Device, error) {
+	query := `SELECT ` + deviceColumns + ` FROM devices ORDER BY id`
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query devices: %w", err)
+	}
+	defer rows.Close()
+
+	var devices []models.Device
+	for rows.Next() {
+		d, err := scanDevice(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan device: %w", err)
+		}
+		devices = append(devices, *d)
+	}
+	return devices, rows.Err()
+}
+
+// Revoke отзывает ключ устройства. false — устройства нет или оно уже отозвано.
+func (r *DeviceRepository) Revoke(ctx context.Context, id int) (bool, error) {
+	query := `UPDATE devices SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`
+	result, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke device: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+func (r *DeviceRepository) Touch(ctx context.Context, id int) error {
+	_, err := r.db.Exec(ctx, `UPDATE devices SET last_seen_at = NOW() WHERE id = $1`, id)
+	return err
+}
+
+func (r *DeviceRepository) CreateEvent(ctx context.Context, e *models.DeviceEvent) error {
+	query := `
+		INSERT INTO device_events (device_id, machine_id, event_type, data, occurred_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, received_at
+	`
+	var data interface{}
+	if len(e.Data) > 0 {
+		data = string(e.Data)
+	}
+	err := r.db.QueryRow(ctx, query, e.DeviceID, e.MachineID, e.Type, data, e.OccurredAt).Scan(&e.ID, &e.ReceivedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record device event: %w", err)
+	}
+	return nil
+}
+
+func (r *DeviceRepository) GetEvents(ctx context.Context, machineID int, since time.Time) ([]models.DeviceEvent, error) {
+	query := `
+		SELECT id, device_id, machine_id, event_type, data, occurred_at, received_at
+		FROM device_events
+		WHERE machine_id = $1 AND occurred_at >= $2
+		ORDER BY occurred_at DESC
+		LIMIT 200
+	`
+	rows, err := r.db.Query(ctx, query, machineID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query device events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []models.DeviceEvent
+	for rows.Next() {
+		var e models.DeviceEvent
+		var data []byte
+		if err := rows.Scan(&e.ID, &e.DeviceID, &e.MachineID, &e.Type, &data, &e.OccurredAt, &e.ReceivedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan device event: %w", err)
+		}
+		e.Data = data
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"netiwash/internal/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type InboxRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewInboxRepository(db *pgxpool.Pool) *InboxRepository {
+	return &InboxRepository{db: db}
+}
+
+func (r *InboxRepository) Create(ctx context.Context, userID int, n *models.InboxNotification) error {
+	query := `
+		INSERT INTO inbox_notifications (user_id, kind, title, body, url, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)
+		RETURNING id
+	`
+	if err := r.db.QueryRow(ctx, query, userID, n.Kind, n.Title, n.Body, n.URL, n.CreatedAt).Scan(&n.ID); err != nil {
+		return fmt.Errorf("failed to save notification: %w", err)
+	}
+	return nil
+}
+
+// List возвращает уведомления пользователя от новых к старым. beforeID > 0
+// — только старше этого уведомления (следующая страница).
+func (r *InboxRepository) List(ctx context.Context, userID int, unreadOnly bool, beforeID, limit int) ([]models.InboxNotification, error) {
+	query := `
+		SELECT id, kind, title, body, COALESCE(url, ''), read_at, created_at
+		FROM inbox_notifications
+		WHERE user_id = $1
+		  AND ($2 = FALSE OR read_at IS NULL)
+		  AND ($3 = 0 OR id < $3)
+		ORDER BY id DESC
+		LIMIT $4
+	`
+	rows, err := r.db.Query(ctx, query, userID, unreadOnly, beforeID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var out []models.InboxNotification
+	for rows.Next() {
+		var n models.InboxNotification
+		if err := rows.Scan(&n.ID, &n.Kind, &n.Title, &n.Body, &n.URL, &n.ReadAt, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, n)
+	}
+	return out, rows.Err()
+}
+
+func (r *InboxRepository) CountUnread(ctx context.Context, userID int) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM inbox_notifications WHERE user_id = $1 AND read_at IS NULL`, userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+	return count, nil
+}
+
+// MarkRead отмечает уведомление прочитанным. false — у пользователя нет
+// такого уведомления; повторная отметка не ошибка.
+func (r *InboxRepository) MarkRead(ctx context.Context, userID, id int, at time.Time) (bool, error) {
+	query := `
+		UPDATE inbox_notifications SET read_at = COALESCE(read_at, $3)
+		WHERE id = $1 AND user_id = $2
+	`
+	tag, err := r.db.Exec(ctx, query, id, userID, at)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark notification read: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *InboxRepository) MarkAllRead(ctx context.Context, userID int, at time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `UPDATE inbox_notifications SET read_at = $2 WHERE user_id = $1 AND read_at IS NULL`, userID, at)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *InboxRepository) Delete(ctx context.Context, userID, id int) (bool, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM inbox_notifications WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete notification: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Clear удаляет уведомления пользователя: все или только прочитанные.
+func (r *InboxRepository) Clear(ctx context.Context, userID int, readOnly bool) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM inbox_notifications WHERE user_id = $1 AND ($2 = FALSE OR read_at IS NOT NULL)`, userID, readOnly)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear notifications: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *InboxRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM inbox_notifications WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune notifications: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -2,13 +2,21 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"netiwash/internal/models"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+var (
+	ErrStatusChanged = errors.New("machine status was changed concurrently")
+	ErrPhotoLimit    = errors.New("machine photo limit reached")
+)
+
 type MachineRepository struct {
 	db *pgxpool.Pool
 }
@@ -17,8 +25,21 @@ func NewMachineRepository(db *pgxpool.Pool) *MachineRepository {
 	return &MachineRepository{db: db}
 }
 
+const machineColumns = `id, name, type, status, is_active, room, COALESCE(building, ''), is_running, last_cycle_started_at, last_cycle_ended_at,
+	last_door_opened_at, cycle_count, capacity_kg, COALESCE(brand, ''), floor, COALESCE(position, ''), COALESCE(notes, ''), attributes`
+
+func scanMachine(row pgx.Row) (*models.Machine, error) {
+	var m models.Machine
+	err := row.Scan(&m.ID, &m.Name, &m.Type, &m.Status, &m.IsActive, &m.Room, &m.Building, &m.IsRunning, &m.LastCycleStartedAt, &m.LastCycleEndedAt,
+		&m.LastDoorOpenedAt, &m.CycleCount, &m.CapacityKg, &m.Brand, &m.Floor, &m.Position, &m.Notes, &m.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
 func (r *MachineRepository) GetAll(ctx context.Context) ([]models.Machine, error) {
-	query := `SELECT id, name, type, status, is_active FROM machines WHERE is_active = true ORDER BY id`
+	query := `SELECT ` + machineColumns + ` FROM machines WHERE is_active = true ORDER BY id`
 
 	rows, err := r.db.Query(ctx, query)
 	if err != nil {
@@ -28,11 +49,11 @@ func (r *MachineRepository) GetAll(ctx context.Context) ([]models.Machine, error
 
 	var machines []models.Machine
 	for rows.Next() {
-		var m models.Machine
-		if err := rows.Scan(&m.ID, &m.Name, &m.Type, &m.Status, &m.IsActive); err != nil {
+		m, err := scanMachine(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan machine: %w", err)
 		}
-		machines = append(machines, m)
+		machines = append(machines, *m)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -42,31 +63,333 @@ func (r *MachineRepository) GetAll(ctx context.Context) ([]models.Machine, error
 	return machines, nil
 }
 
-func (r *MachineRepository) UpdateStatus(ctx context.Context, id int, status string) error {
-	query := `UPDATE machines SET status = $1 WHERE id = $2`
+// ChangeStatus меняет статус машины и пишет запись в machine_status_history
+// в одной транзакции. Статус обновляется, только если он всё ещё равен
+// change.FromStatus; иначе возвращается ErrStatusChanged.
+func (r *MachineRepository) ChangeStatus(ctx context.Context, change *models.MachineStatusChange) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := changeStatus(ctx, tx, change); err != nil {
+		return err
+	}
 
-	result, err := r.db.Exec(ctx, query, status, id)
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit status change: %w", err)
+	}
+	return nil
+}
+
+func changeStatus(ctx context.Context, tx pgx.Tx, change *models.MachineStatusChange) error {
+	result, err := tx.Exec(ctx, `UPDATE machines SET status = $1 WHERE id = $2 AND status = $3`,
+		change.ToStatus, change.MachineID, change.FromStatus)
 	if err != nil {
 		return fmt.Errorf("failed to update machine status: %w", err)
 	}
+	if result.RowsAffected() == 0 {
+		return ErrStatusChanged
+	}
+	return insertStatusHistory(ctx, tx, change)
+}
 
-	rowsAffected := result.RowsAffected()
-	if rowsAffected == 0 {
-		return fmt.Errorf("machine not found")
+func (r *MachineRepository) GetStatusHistory(ctx context.Context, machineID int, limit int) ([]models.MachineStatusChange, error) {
+	query := `
+		SELECT h.id, h.machine_id, COALESCE(h.from_status, ''), h.to_status, COALESCE(h.reason, ''),
+		       h.actor_id, COALESCE(u.login, ''), h.source, h.created_at
+		FROM machine_status_history h
+		LEFT JOIN users u ON u.id = h.actor_id
+		WHERE h.machine_id = $1
+		ORDER BY h.created_at DESC, h.id DESC
+		LIMIT $2
+	`
+	rows, err := r.db.Query(ctx, query, machineID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query status history: %w", err)
 	}
+	defer rows.Close()
+
+	var history []models.MachineStatusChange
+	for rows.Next() {
+		var h models.MachineStatusChange
+		if err := rows.Scan(&h.ID, &h.MachineID, &h.FromStatus, &h.ToStatus, &h.Reason,
+			&h.ActorID, &h.ActorLogin, &h.Source, &h.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan status history: %w", err)
+		}
+		history = append(history, h)
+	}
+	return history, rows.Err()
+}
 
+func insertStatusHistory(ctx context.Context, tx pgx.Tx, change *models.MachineStatusChange) error {
+	query := `
+		INSERT INTO machine_status_history (machine_id, from_status, to_status, reason, actor_id, source)
+		VALUES ($1, NULLIF($2, ''), $3, NULLIF($4, ''), $5, $6)
+		RETURNING id, created_at
+	`
+	err := tx.QueryRow(ctx, query, change.MachineID, change.FromStatus, change.ToStatus, change.Reason, change.ActorID, change.Source).
+		Scan(&change.ID, &change.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record status history: %w", err)
+	}
 	return nil
 }
 
-func (r *MachineRepository) Create(ctx context.Context, m *models.Machine) error {
+// Create добавляет машину; initial записывается в историю как первый статус.
+func (r *MachineRepository) Create(ctx context.Context, m *models.Machine, initial *models.MachineStatusChange) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if m.Attributes == nil {
+		m.Attributes = map[string]string{}
+	}
+
 	query := `
-		INSERT INTO machines (name, type, status, is_active)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id
+		INSERT INTO machines (name, type, status, is_active, room, capacity_kg, brand, floor, position, notes, attributes, building)
+		VALUES ($1, $2, $3, $4, COALESCE(NULLIF($5, ''), 'main'), $6, NULLIF($7, ''), $8, NULLIF($9, ''), NULLIF($10, ''), $11, NULLIF($12, ''))
+		RETURNING id, room
 	`
-	err := r.db.QueryRow(ctx, query, m.Name, m.Type, m.Status, m.IsActive).Scan(&m.ID)
+	err = tx.QueryRow(ctx, query, m.Name, m.Type, m.Status, m.IsActive, m.Room,
+		m.CapacityKg, m.Brand, m.Floor, m.Position, m.Notes, m.Attributes, m.Building).Scan(&m.ID, &m.Room)
 	if err != nil {
 		return fmt.Errorf("failed to create machine: %w", err)
 	}
+
+	if initial != nil {
+		initial.MachineID = m.ID
+		initial.ToStatus = m.Status
+		if err := insertStatusHistory(ctx, tx, initial); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit machine: %w", err)
+	}
+	return nil
+}
+
+func (r *MachineRepository) GetByID(ctx context.Context, id int) (*models.Machine, error) {
+	query := `SELECT ` + machineColumns + ` FROM machines WHERE id = $1`
+
+	m, err := scanMachine(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get machine: %w", err)
+	}
+	return m, nil
+}
+
+// Update сохраняет поля машины и, если change не nil, меняет её статус —
+// всё в одной транзакции: при отказе в смене статуса не меняется ничего.
+func (r *MachineRepository) Update(ctx context.Context, m *models.Machine, change *models.MachineStatusChange) error {
+	if m.Attributes == nil {
+		m.Attributes = map[string]string{}
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE machines
+		SET name = $1, type = $2, room = $3, capacity_kg = $4, brand = NULLIF($5, ''), floor = $6,
+		    position = NULLIF($7, ''), notes = NULLIF($8, ''), attributes = $9, building = NULLIF($11, '')
+		WHERE id = $10 AND is_active = true
+	`
+
+	result, err := tx.Exec(ctx, query, m.Name, m.Type, m.Room, m.CapacityKg, m.Brand, m.Floor, m.Position, m.Notes, m.Attributes, m.ID, m.Building)
+	if err != nil {
+		return fmt.Errorf("failed to update machine: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("machine not found")
+	}
+
+	if change != nil {
+		if err := changeStatus(ctx, tx, change); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit machine: %w", err)
+	}
+	return nil
+}
+
+// Decommission выводит машину из эксплуатации: is_active = false, а все
+// активные брони, которые ещё не закончились к now, отменяются. Строки
+// броней не удаляются, чтобы сохранить историю. Возвращает отменённые брони.
+func (r *MachineRepository) Decommission(ctx context.Context, id int, now time.Time) ([]models.Booking, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `UPDATE machines SET is_active = false WHERE id = $1 AND is_active = true`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decommission machine: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, fmt.Errorf("machine not found")
+	}
+
+	rows, err := tx.Query(ctx, `
+		UPDATE bookings SET status = 'cancelled'
+		WHERE machine_id = $1 AND status = 'active' AND end_time > $2
+		RETURNING id, user_id, machine_id, start_time, end_time, status, created_at
+	`, id, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel bookings: %w", err)
+	}
+	var cancelled []models.Booking
+	for rows.Next() {
+		var b models.Booking
+		if err := rows.Scan(&b.ID, &b.UserID, &b.MachineID, &b.StartTime, &b.EndTime, &b.Status, &b.CreatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("row scan error: %w", err)
+		}
+		cancelled = append(cancelled, b)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to cancel bookings: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit decommission: %w", err)
+	}
+	return cancelled, nil
+}
+
+// SetRunning сохраняет состояние, о котором сообщила телеметрия машины.
+// Конец цикла увеличивает счётчик стирок.
+func (r *MachineRepository) SetRunning(ctx context.Context, id int, running bool, at time.Time) error {
+	query := `
+		UPDATE machines
+		SET is_running = $1,
+		    last_cycle_started_at = CASE WHEN $1 THEN $2 ELSE last_cycle_started_at END,
+		    last_cycle_ended_at = CASE WHEN $1 THEN last_cycle_ended_at ELSE $2 END,
+		    cycle_count = CASE WHEN $1 THEN cycle_count ELSE cycle_count + 1 END
+		WHERE id = $3
+	`
+	_, err := r.db.Exec(ctx, query, running, at, id)
+	if err != nil {
+		return fmt.Errorf("failed to update machine running state: %w", err)
+	}
+	return nil
+}
+
+func (r *MachineRepository) SetDoorOpened(ctx context.Context, id int, at time.Time) error {
+	query := `UPDATE machines SET last_door_opened_at = $1 WHERE id = $2`
+	_, err := r.db.Exec(ctx, query, at, id)
+	if err != nil {
+		return fmt.Errorf("failed to update door state: %w", err)
+	}
+	return nil
+}
+
+func (r *MachineRepository) CountPhotos(ctx context.Context, machineID int) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM machine_photos WHERE machine_id = $1`, machineID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count machine photos: %w", err)
+	}
+	return count, nil
+}
+
+// AddPhoto добавляет фото, если у машины их меньше limit. Строка машины
+// блокируется на время транзакции, так что параллельные загрузки не
+// проскакивают лимит; при переполнении возвращается ErrPhotoLimit.
+func (r *MachineRepository) AddPhoto(ctx context.Context, p *models.MachinePhoto, limit int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `SELECT id FROM machines WHERE id = $1 FOR UPDATE`, p.MachineID)
+	if err != nil {
+		return fmt.Errorf("failed to lock machine: %w", err)
+	}
+	var count int
+	err = tx.QueryRow(ctx, `SELECT COUNT(*) FROM machine_photos WHERE machine_id = $1`, p.MachineID).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to count machine photos: %w", err)
+	}
+	if count >= limit {
+		return ErrPhotoLimit
+	}
+
+	query := `
+		INSERT INTO machine_photos (machine_id, storage_key, content_type)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+	err = tx.QueryRow(ctx, query, p.MachineID, p.StorageKey, p.ContentType).Scan(&p.ID, &p.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to add machine photo: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *MachineRepository) GetPhoto(ctx context.Context, machineID, photoID int) (*models.MachinePhoto, error) {
+	query := `SELECT id, machine_id, storage_key, content_type, created_at FROM machine_photos WHERE id = $1 AND machine_id = $2`
+	var p models.MachinePhoto
+	err := r.db.QueryRow(ctx, query, photoID, machineID).Scan(&p.ID, &p.MachineID, &p.StorageKey, &p.ContentType, &p.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get machine photo: %w", err)
+	}
+	return &p, nil
+}
+
+// GetPhotos возвращает фото всех активных машин, сгруппированные по machine_id.
+func (r *MachineRepository) GetPhotos(ctx context.Context) (map[int][]models.MachinePhoto, error) {
+	query := `
+		SELECT p.id, p.machine_id, p.storage_key, p.content_type, p.created_at
+		FROM machine_photos p
+		JOIN machines m ON m.id = p.machine_id
+		WHERE m.is_active = true
+		ORDER BY p.machine_id, p.id
+	`
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query machine photos: %w", err)
+	}
+	defer rows.Close()
+
+	photos := make(map[int][]models.MachinePhoto)
+	for rows.Next() {
+		var p models.MachinePhoto
+		if err := rows.Scan(&p.ID, &p.MachineID, &p.StorageKey, &p.ContentType, &p.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan machine photo: %w", err)
+		}
+		photos[p.MachineID] = append(photos[p.MachineID], p)
+	}
+	return photos, rows.Err()
+}
+
+func (r *MachineRepository) DeletePhoto(ctx context.Context, machineID, photoID int) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM machine_photos WHERE id = $1 AND machine_id = $2`, photoID, machineID)
+	if err != nil {
+		return fmt.Errorf("failed to delete machine photo: %w", err)
+	}
 	return nil
 }
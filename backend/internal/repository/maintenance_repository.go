@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"netiwash/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type MaintenanceRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewMaintenanceRepository(db *pgxpool.Pool) *MaintenanceRepository {
+	return &MaintenanceRepository{db: db}
+}
+
+const planColumns = `id, machine_type, name, every_cycles, every_days, window_minutes, is_active, created_at`
+
+func scanPlan(row pgx.Row) (*models.MaintenancePlan, error) {
+	var p models.MaintenancePlan
+	err := row.Scan(&p.ID, &p.MachineType, &p.Name, &p.EveryCycles, &p.EveryDays, &p.WindowMinutes, &p.IsActive, &p.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *MaintenanceRepository) CreatePlan(ctx context.Context, p *models.MaintenancePlan) error {
+	query := `
+		INSERT INTO maintenance_plans (machine_type, name, every_cycles, every_days, window_minutes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, is_active
+	`
+	err := r.db.QueryRow(ctx, query, p.MachineType, p.Name, p.EveryCycles, p.EveryDays, p.WindowMinutes, p.CreatedAt).
+		Scan(&p.ID, &p.IsActive)
+	if err != nil {
+		return fmt.Errorf("failed to create maintenance plan: %w", err)
+	}
+	return nil
+}
+
+// UpdatePlan возвращает nil, если активного плана с таким id нет.
+func (r *MaintenanceRepository) UpdatePlan(ctx context.Context, p *models.MaintenancePlan) (*models.MaintenancePlan, error) {
+	query := `
+		UPDATE maintenance_plans
+		SET machine_type = $1, name = $2, every_cycles = $3, every_days = $4, window_minutes = $5
+		WHERE id = $6 AND is_active = true
+		RETURNING created_at, is_active
+	`
+	err := r.db.QueryRow(ctx, query, p.MachineType, p.Name, p.EveryCycles, p.EveryDays, p.WindowMinutes, p.ID).
+		Scan(&p.CreatedAt, &p.IsActive)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to update maintenance plan: %w", err)
+	}
+	return p, nil
+}
+
+// DeactivatePlan выключает план и закрывает его открытые задачи, чтобы
+// освободить зарезервированные окна. ok = false, если активного плана нет.
+func (r *MaintenanceRepository) DeactivatePlan(ctx context.Context, id int) (ok bool, err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `UPDATE maintenance_plans SET is_active = false WHERE id = $1 AND is_active = true`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to deactivate maintenance plan: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM maintenance_tasks WHERE plan_id = $1 AND status = 'open'`, id); err != nil {
+		return false, fmt.Errorf("failed to drop open maintenance tasks: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+func (r *MaintenanceRepository) GetPlans(ctx context.Context, activeOnly bool) ([]models.MaintenancePlan, error) {
+	query := `SELECT ` + planColumns + ` FROM maintenance_plans WHERE is_active OR NOT $1 ORDER BY id`
+	rows, err := r.db.Query(ctx, query, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query maintenance plans: %w", err)
+	}
+	defer rows.Close()
+
+	var plans []models.MaintenancePlan
+	for rows.Next() {
+		p, err := scanPlan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan maintenance plan: %w", err)
+		}
+		plans = append(plans, *p)
+	}
+	return plans, rows.Err()
+}
+
+// LastDone возвращает счётчик стирок и время последнего выполненного
+// обслуживания машины по плану. ok = false, если его ещё не было.
+func (r *MaintenanceRepository) LastDone(ctx context.Context, planID, machineID int) (cycles int, at time.Time, ok bool, err error) {
+	query := `
+		SELECT cycle_count, completed_at
+		FROM maintenance_tasks
+		WHERE plan_id = $1 AND machine_id = $2 AND status = 'done'
+		ORDER BY completed_at DESC
+		LIMIT 1
+	`
+	err = r.db.QueryRow(ctx, query, planID, machineID).Scan(&cycles, &at)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, time.Time{}, false, nil
+		}
+		return 0, time.Time{}, false, fmt.Errorf("failed to get last maintenance: %w", err)
+	}
+	return cycles, at, true, nil
+}
+
+func (r *MaintenanceRepository) HasOpenTask(ctx context.Context, planID, machineID int) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM maintenance_tasks WHERE plan_id = $1 AND machine_id = $2 AND status = 'open')`
+	var exists bool
+	if err := r.db.QueryRow(ctx, query, planID, machineID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check maintenance tasks: %w", err)
+	}
+	return exists, nil
+}
+
+// CreateTask создаёт задачу. Если у задачи есть окно обслуживания, активные
+// брони, пересекающиеся с ним, отменяются в той же транзакции и
+// возвращаются, чтобы их владельцев можно было уведомить.
+func (r *MaintenanceRepository) CreateTask(ctx context.Context, t *models.MaintenanceTask) ([]models.Booking, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO maintenance_tasks (plan_id, machine_id, status, cycle_count, window_start, window_end)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+	err = tx.QueryRow(ctx, query, t.PlanID, t.MachineID, t.Status, t.CycleCount, t.WindowStart, t.WindowEnd).
+		Scan(&t.ID, &t.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create maintenance task: %w", err)
+	}
+
+	var cancelled []models.Booking
+	if t.WindowStart != nil && t.WindowEnd != nil {
+		rows, err := tx.Query(ctx, `
+			UPDATE bookings SET status = 'cancelled'
+			WHERE machine_id = $1 AND status = 'active' AND start_time < $3 AND end_time > $2
+			RETURNING id, user_id, machine_id, start_time, end_time, status, created_at
+		`, t.MachineID, *t.WindowStart, *t.WindowEnd)
+		if err != nil {
+			return nil, fmt.Errorf("failed to cancel bookings: %w", err)
+		}
+		for rows.Next() {
+			var b models.Booking
+			if err := rows.Scan(&b.ID, &b.UserID, &b.MachineID, &b.StartTime, &b.EndTime, &b.Status, &b.CreatedAt); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("row scan error: %w", err)
+			}
+			cancelled = append(cancelled, b)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to cancel bookings: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit maintenance task: %w", err)
+	}
+	return cancelled, nil
+}
+
+const taskColumns = `t.id, t.plan_id, p.name, t.machine_id, m.name, t.status, t.cycle_count,
+	t.window_start, t.window_end, t.created_at, t.completed_at, t.completed_by`
+
+func (r *MaintenanceRepository) GetTasks(ctx context.Context, status string) ([]models.MaintenanceTask, error) {
+	query := `
+		SELECT ` + taskColumns + `
+		FROM maintenance_tasks t
+		JOIN maintenance_plans p ON p.id = t.plan_id
+		JOIN machines m ON m.id = t.machine_id
+		WHERE $1 = '' OR t.status = $1
+		ORDER BY t.created_at DESC
+	`
+	rows, err := r.db.Query(ctx, query, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query maintenance tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []models.MaintenanceTask
+	for rows.Next() {
+		var t models.MaintenanceTask
+		if err := rows.Scan(&t.ID, &t.PlanID, &t.PlanName, &t.MachineID, &t.MachineName, &t.Status, &t.CycleCount,
+			&t.WindowStart, &t.WindowEnd, &t.CreatedAt, &t.CompletedAt, &t.CompletedBy); err != nil {
+			return nil, fmt.Errorf("failed to scan maintenance task: %w", err)
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, rows.Err()
+}
+
+// CompleteTask закрывает задачу и запоминает текущий счётчик стирок машины —
+// от него считается следующий срок.
+// CompleteTask закрывает открытую задачу; ok = false, если такой нет.
+func (r *MaintenanceRepository) CompleteTask(ctx context.Context, id, actorID int, at time.Time) (ok bool, err error) {
+	query := `
+		UPDATE maintenance_tasks t
+		SET status = 'done', completed_at = $2, completed_by = $3, cycle_count = m.cycle_count
+		FROM machines m
+		WHERE t.id = $1 AND t.status = 'open' AND m.id = t.machine_id
+	`
+	result, err := r.db.Exec(ctx, query, id, at, actorID)
+	if err != nil {
+		return false, fmt.Errorf("failed to complete maintenance task: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"netiwash/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type NotificationDeliveryRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewNotificationDeliveryRepository(db *pgxpool.Pool) *NotificationDeliveryRepository {
+	return &NotificationDeliveryRepository{db: db}
+}
+
+const deliveryColumns = `id, user_id, kind, channel, subscription_id, message, payload, status, attempts,
+		last_error, next_attempt_at, created_at, updated_at`
+
+func scanDelivery(row pgx.Row, d *models.NotificationDelivery) error {
+	return row.Scan(&d.ID, &d.UserID, &d.Kind, &d.Channel, &d.SubscriptionID, &d.Message, &d.Payload, &d.Status, &d.Attempts,
+		&d.LastError, &d.NextAttemptAt, &d.CreatedAt, &d.UpdatedAt)
+}
+
+// Create записывает доставки в статусе pending одной транзакцией и
+// заполняет их ID. nextAttemptAt — когда подобрать доставку повторно, если
+// процесс упадёт, не успев её отправить; доставка с собственным
+// NextAttemptAt (отложенная) записывается с ним.
+func (r *NotificationDeliveryRepository) Create(ctx context.Context, deliveries []models.NotificationDelivery, now, nextAttemptAt time.Time) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO notification_deliveries (user_id, kind, channel, subscription_id, message, payload, status, next_attempt_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+		RETURNING ` + deliveryColumns
+	for i := range deliveries {
+		d := &deliveries[i]
+		next := nextAttemptAt
+		if d.NextAttemptAt != nil {
+			next = *d.NextAttemptAt
+		}
+		row := tx.QueryRow(ctx, query, d.UserID, d.Kind, d.Channel, d.SubscriptionID, d.Message, d.Payload, models.DeliveryStatusPending, next, now)
+		if err := scanDelivery(row, d); err != nil {
+			return fmt.Errorf("failed to create delivery: %w", err)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// ClaimDue забирает до limit доставок, срок повтора которых наступил, и
+// сдвигает их next_attempt_at на leaseUntil, чтобы другой экземпляр не
+// взял те же строки, пока эта попытка идёт.
+func (r *NotificationDeliveryRepository) ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.NotificationDelivery, error) {
+	query := `
+		UPDATE notification_deliveries
+		SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT id FROM notification_deliveries
+			WHERE status IN ('pending', 'failed') AND next_attempt_at <= $1
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + deliveryColumns
+	rows, err := r.db.Query(ctx, query, now, leaseUntil, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var out []models.NotificationDelivery
+	for rows.Next() {
+		var d models.NotificationDelivery
+		if err := scanDelivery(rows, &d); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func (r *NotificationDeliveryRepository) MarkSent(ctx context.Context, id int, at time.Time) error {
+	query := `
+		UPDATE notification_deliveries
+		SET status = 'sent', attempts = attempts + 1, last_error = NULL, next_attempt_at = NULL, updated_at = $2
+		WHERE id = $1
+	`
+	if _, err := r.db.Exec(ctx, query, id, at); err != nil {
+		return fmt.Errorf("failed to mark delivery sent: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed фиксирует неудачную попытку. nextAttemptAt == nil вместе со
+// статусом dead означает, что повторов больше не будет.
+func (r *NotificationDeliveryRepository) MarkFailed(ctx context.Context, id int, status, lastError string, nextAttemptAt *time.Time, at time.Time) error {
+	query := `
+		UPDATE notification_deliveries
+		SET status = $2, attempts = attempts + 1, last_error = $3, next_attempt_at = $4, updated_at = $5
+		WHERE id = $1
+	`
+	if _, err := r.db.Exec(ctx, query, id, status, lastError, nextAttemptAt, at); err != nil {
+		return fmt.Errorf("failed to mark delivery failed: %w", err)
+	}
+	return nil
+}
+
+// List возвращает последние доставки в указанных статусах.
+func (r *NotificationDeliveryRepository) List(ctx context.Context, statuses []string, userID, limit int) ([]models.NotificationDelivery, error) {
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM notification_deliveries
+		WHERE status = ANY($1) AND ($2 = 0 OR user_id = $2)
+		ORDER BY updated_at DESC, id DESC
+		LIMIT $3
+	`
+	rows, err := r.db.Query(ctx, query, statuses, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var out []models.NotificationDelivery
+	for rows.Next() {
+		var d models.NotificationDelivery
+		if err := scanDelivery(rows, &d); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// Requeue возвращает доставку из dead в очередь на одну попытку. false —
+// доставки нет или она не в dead.
+func (r *NotificationDeliveryRepository) Requeue(ctx context.Context, id int, at time.Time) (bool, error) {
+	query := `
+		UPDATE notification_deliveries
+		SET status = 'failed', next_attempt_at = $2, updated_at = $2
+		WHERE id = $1 AND status = 'dead'
+	`
+	tag, err := r.db.Exec(ctx, query, id, at)
+	if err != nil {
+		return false, fmt.Errorf("failed to requeue delivery: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// DeleteSentBefore чистит журнал от давно доставленных уведомлений.
+func (r *NotificationDeliveryRepository) DeleteSentBefore(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM notification_deliveries WHERE status = 'sent' AND updated_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune deliveries: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"netiwash/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type NotificationPreferencesRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewNotificationPreferencesRepository(db *pgxpool.Pool) *NotificationPreferencesRepository {
+	return &NotificationPreferencesRepository{db: db}
+}
+
+// Get возвращает сохранённые настройки или nil, если пользователь их не менял.
+func (r *NotificationPreferencesRepository) Get(ctx context.Context, userID int) (*models.NotificationPreferences, error) {
+	query := `
+		SELECT locale, channels, quiet_hours_start, quiet_hours_end, updated_at
+		FROM notification_preferences
+		WHERE user_id = $1
+	`
+	var p models.NotificationPreferences
+	err := r.db.QueryRow(ctx, query, userID).Scan(&p.Locale, &p.Channels, &p.QuietHoursStart, &p.QuietHoursEnd, &p.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	return &p, nil
+}
+
+func (r *NotificationPreferencesRepository) Save(ctx context.Context, userID int, p *models.NotificationPreferences) error {
+	query := `
+		INSERT INTO notification_preferences (user_id, locale, channels, quiet_hours_start, quiet_hours_end, updated_at)
+		VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id) DO UPDATE
+		SET locale = EXCLUDED.locale,
+		    channels = EXCLUDED.channels,
+		    quiet_hours_start = EXCLUDED.quiet_hours_start,
+		    quiet_hours_end = EXCLUDED.quiet_hours_end,
+		    updated_at = EXCLUDED.updated_at
+		RETURNING updated_at
+	`
+	err := r.db.QueryRow(ctx, query, userID, p.Locale, p.Channels, p.QuietHoursStart, p.QuietHoursEnd).Scan(&p.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"netiwash/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PaymentRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPaymentRepository(db *pgxpool.Pool) *PaymentRepository {
+	return &PaymentRepository{db: db}
+}
+
+func (r *PaymentRepository) Create(ctx context.Context, p *models.Payment) error {
+	query := `
+		INSERT INTO payments (user_id, idempotence_key, credits, amount, currency, status)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+	err := r.db.QueryRow(ctx, query, p.UserID, p.IdempotenceKey, p.Credits, p.Amount, p.Currency, p.Status).
+		Scan(&p.ID, &p.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create payment: %w", err)
+	}
+	return nil
+}
+
+func (r *PaymentRepository) AttachProviderPayment(ctx context.Context, id int, providerPaymentID, confirmationURL string) error {
+	query := `UPDATE payments SET provider_payment_id = $1, confirmation_url = $2 WHERE id = $3`
+	_, err := r.db.Exec(ctx, query, providerPaymentID, confirmationURL, id)
+	if err != nil {
+		return fmt.Errorf("failed to attach provider payment: %w", err)
+	}
+	return nil
+}
+
+func (r *PaymentRepository) UpdateStatus(ctx context.Context, id int, status string) error {
+	query := `UPDATE payments SET status = $1 WHERE id = $2`
+	_, err := r.db.Exec(ctx, query, status, id)
+	if err != nil {
+		return fmt.Errorf("failed to update payment status: %w", err)
+	}
+	return nil
+}
+
+func (r *PaymentRepository) GetByProviderID(ctx context.Context, providerPaymentID string) (*models.Payment, error) {
+	query := `
+		SELECT id, user_id, COALESCE(provider_payment_id, ''), idempotence_key, credits, amount, currency, status,
+		       COALESCE(confirmation_url, ''), created_at, paid_at
+		FROM payments
+		WHERE provider_payment_id = $1
+	`
+	var p models.Payment
+	err := r.db.QueryRow(ctx, query, providerPaymentID).Scan(
+		&p.ID, &p.UserID, &p.ProviderPaymentID, &p.IdempotenceKey, &p.Credits, &p.Amount, &p.Currency, &p.Status,
+		&p.ConfirmationURL, &p.CreatedAt, &p.PaidAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+	return &p, nil
+}
+
+func (r *PaymentRepository) GetByUserID(ctx context.Context, userID int) ([]models.Payment, error) {
+	query := `
+		SELECT id, user_id, COALESCE(provider_payment_id, ''), idempotence_key, credits, amount, currency, status,
+		       COALESCE(confirmation_url, ''), created_at, paid_at
+		FROM payments
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query payments: %w", err)
+	}
+	defer rows.Close()
+
+	var payments []models.Payment
+	for rows.Next() {
+		var p models.Payment
+		if err := rows.Scan(
+			&p.ID, &p.UserID, &p.ProviderPaymentID, &p.IdempotenceKey, &p.Credits, &p.Amount, &p.Currency, &p.Status,
+			&p.ConfirmationURL, &p.CreatedAt, &p.PaidAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan payment: %w", err)
+		}
+		payments = append(payments, p)
+	}
+	return payments, rows.Err()
+}
+
+// MarkSucceededAndCredit переводит платёж в succeeded и зачисляет кредиты
+// в одной транзакции. Повторный вызов для того же платежа ничего не меняет
+// и возвращает false.
+func (r *PaymentRepository) MarkSucceededAndCredit(ctx context.Context, providerPaymentID string) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var paymentID, userID, credits int
+	err = tx.QueryRow(ctx, `
+		UPDATE payments SET status = 'succeeded', paid_at = NOW()
+		WHERE provider_payment_id = $1 AND status <> 'succeeded'
+		RETURNING id, user_id, credits
+	`, providerPaymentID).Scan(&paymentID, &userID, &credits)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to mark payment succeeded: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO wallet_transactions (user_id, amount, reason, payment_id)
+		VALUES ($1, $2, 'payment', $3)
+	`, userID, credits, paymentID)
+	if err != nil {
+		return false, fmt.Errorf("failed to record wallet transaction: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO wallets (user_id, balance, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id) DO UPDATE
+		SET balance = wallets.balance + EXCLUDED.balance, updated_at = NOW()
+	`, userID, credits)
+	if err != nil {
+		return false, fmt.Errorf("failed to credit wallet: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit payment: %w", err)
+	}
+	return true, nil
+}
+
+func (r *PaymentRepository) MarkCanceled(ctx context.Context, providerPaymentID string) error {
+	query := `UPDATE payments SET status = 'canceled' WHERE provider_payment_id = $1 AND status = 'pending'`
+	_, err := r.db.Exec(ctx, query, providerPaymentID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel payment: %w", err)
+	}
+	return nil
+}
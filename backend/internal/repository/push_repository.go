@@ -2,10 +2,13 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"netiwash/internal/models"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -19,12 +22,13 @@ func NewPushRepository(db *pgxpool.Pool) *PushRepository {
 
 func (r *PushRepository) CreateSubscription(ctx context.Context, sub *models.PushSubscription) error {
 	query := `
-		INSERT INTO push_subscriptions (user_id, endpoint, p256dh, auth)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO push_subscriptions (user_id, endpoint, p256dh, auth, user_agent, vapid_public_key)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''))
 		ON CONFLICT (user_id, endpoint) DO UPDATE 
-		SET p256dh = EXCLUDED.p256dh, auth = EXCLUDED.auth
+		SET p256dh = EXCLUDED.p256dh, auth = EXCLUDED.auth, user_agent = EXCLUDED.user_agent,
+		    vapid_public_key = EXCLUDED.vapid_public_key
 	`
-	_, err := r.db.Exec(ctx, query, sub.UserID, sub.Endpoint, sub.P256dh, sub.Auth)
+	_, err := r.db.Exec(ctx, query, sub.UserID, sub.Endpoint, sub.P256dh, sub.Auth, sub.UserAgent, sub.VAPIDKey)
 	if err != nil {
 		return fmt.Errorf("failed to save subscription: %w", err)
 	}
@@ -32,7 +36,12 @@ func (r *PushRepository) CreateSubscription(ctx context.Context, sub *models.Pus
 }
 
 func (r *PushRepository) GetSubscriptionsByUserID(ctx context.Context, userID int) ([]models.PushSubscription, error) {
-	query := `SELECT id, user_id, endpoint, p256dh, auth, created_at FROM push_subscriptions WHERE user_id = $1`
+	query := `
+		SELECT id, user_id, endpoint, p256dh, auth, COALESCE(user_agent, ''), COALESCE(vapid_public_key, ''), created_at, last_success_at
+		FROM push_subscriptions
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
 	rows, err := r.db.Query(ctx, query, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get subscriptions: %w", err)
@@ -42,10 +51,64 @@ func (r *PushRepository) GetSubscriptionsByUserID(ctx context.Context, userID in
 	var subs []models.PushSubscription
 	for rows.Next() {
 		var s models.PushSubscription
-		if err := rows.Scan(&s.ID, &s.UserID, &s.Endpoint, &s.P256dh, &s.Auth, &s.CreatedAt); err != nil {
+		if err := rows.Scan(&s.ID, &s.UserID, &s.Endpoint, &s.P256dh, &s.Auth, &s.UserAgent, &s.VAPIDKey, &s.CreatedAt, &s.LastSuccessAt); err != nil {
 			return nil, err
 		}
 		subs = append(subs, s)
 	}
 	return subs, nil
 }
+
+// GetSubscriptionByID возвращает nil, если подписки уже нет.
+func (r *PushRepository) GetSubscriptionByID(ctx context.Context, id int) (*models.PushSubscription, error) {
+	query := `
+		SELECT id, user_id, endpoint, p256dh, auth, COALESCE(user_agent, ''), COALESCE(vapid_public_key, ''), created_at, last_success_at
+		FROM push_subscriptions
+		WHERE id = $1
+	`
+	var s models.PushSubscription
+	err := r.db.QueryRow(ctx, query, id).Scan(&s.ID, &s.UserID, &s.Endpoint, &s.P256dh, &s.Auth, &s.UserAgent, &s.VAPIDKey, &s.CreatedAt, &s.LastSuccessAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	return &s, nil
+}
+
+func (r *PushRepository) MarkSuccess(ctx context.Context, id int, at time.Time) error {
+	_, err := r.db.Exec(ctx, `UPDATE push_subscriptions SET last_success_at = $1 WHERE id = $2`, at, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark subscription: %w", err)
+	}
+	return nil
+}
+
+// DeleteByID удаляет подписку; userID > 0 ограничивает удаление подписками
+// этого пользователя. Возвращает false, если удалять было нечего.
+func (r *PushRepository) DeleteByID(ctx context.Context, id, userID int) (bool, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM push_subscriptions WHERE id = $1 AND ($2 = 0 OR user_id = $2)`, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete subscription: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *PushRepository) DeleteByEndpoint(ctx context.Context, userID int, endpoint string) (bool, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM push_subscriptions WHERE user_id = $1 AND endpoint = $2`, userID, endpoint)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete subscription: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// AssignLegacyKey записывает ключ подпискам, созданным до того, как сервер
+// начал запоминать ключ каждой подписки.
+func (r *PushRepository) AssignLegacyKey(ctx context.Context, publicKey string) (int64, error) {
+	tag, err := r.db.Exec(ctx, `UPDATE push_subscriptions SET vapid_public_key = $1 WHERE vapid_public_key IS NULL`, publicKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to assign VAPID key to subscriptions: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
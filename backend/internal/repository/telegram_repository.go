@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"netiwash/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TelegramRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTelegramRepository(db *pgxpool.Pool) *TelegramRepository {
+	return &TelegramRepository{db: db}
+}
+
+// CreateLinkCode сохраняет код привязки; прежние коды пользователя и
+// просроченные коды удаляются.
+func (r *TelegramRepository) CreateLinkCode(ctx context.Context, userID int, code string, now, expiresAt time.Time) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM telegram_link_codes WHERE user_id = $1 OR expires_at < $2`, userID, now); err != nil {
+		return fmt.Errorf("failed to clear link codes: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO telegram_link_codes (code, user_id, expires_at) VALUES ($1, $2, $3)`, code, userID, expiresAt); err != nil {
+		return fmt.Errorf("failed to create link code: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// Link погашает код и привязывает чат к его владельцу. Чат, ранее
+// привязанный к другому пользователю, перепривязывается. Возвращает nil,
+// если код не найден или истёк.
+func (r *TelegramRepository) Link(ctx context.Context, code string, now time.Time, chatID int64, username string) (*models.TelegramAccount, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var userID int
+	err = tx.QueryRow(ctx, `DELETE FROM telegram_link_codes WHERE code = $1 AND expires_at > $2 RETURNING user_id`, code, now).Scan(&userID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to redeem link code: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM telegram_accounts WHERE chat_id = $1 AND user_id <> $2`, chatID, userID); err != nil {
+		return nil, fmt.Errorf("failed to release chat: %w", err)
+	}
+
+	a := models.TelegramAccount{UserID: userID, ChatID: chatID, Username: username}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO telegram_accounts (user_id, chat_id, username)
+		VALUES ($1, $2, NULLIF($3, ''))
+		ON CONFLICT (user_id) DO UPDATE
+		SET chat_id = EXCLUDED.chat_id, username = EXCLUDED.username, linked_at = CURRENT_TIMESTAMP
+		RETURNING linked_at
+	`, userID, chatID, username).Scan(&a.LinkedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to link telegram account: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *TelegramRepository) GetByUserID(ctx context.Context, userID int) (*models.TelegramAccount, error) {
+	return r.getAccount(ctx, `WHERE user_id = $1`, userID)
+}
+
+func (r *TelegramRepository) GetByChatID(ctx context.Context, chatID int64) (*models.TelegramAccount, error) {
+	return r.getAccount(ctx, `WHERE chat_id = $1`, chatID)
+}
+
+func (r *TelegramRepository) getAccount(ctx context.Context, where string, arg interface{}) (*models.TelegramAccount, error) {
+	query := `SELECT user_id, chat_id, COALESCE(username, ''), linked_at FROM telegram_accounts ` + where
+	var a models.TelegramAccount
+	err := r.db.QueryRow(ctx, query, arg).Scan(&a.UserID, &a.ChatID, &a.Username, &a.LinkedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get telegram account: %w", err)
+	}
+	return &a, nil
+}
+
+func (r *TelegramRepository) Unlink(ctx context.Context, userID int) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM telegram_accounts WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to unlink telegram account: %w", err)
+	}
+	return nil
+}
+
+func (r *TelegramRepository) UnlinkChat(ctx context.Context, chatID int64) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM telegram_accounts WHERE chat_id = $1`, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to unlink telegram chat: %w", err)
+	}
+	return nil
+}
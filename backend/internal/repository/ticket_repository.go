@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"netiwash/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrTicketStatusChanged = errors.New("ticket status was changed concurrently")
+
+type TicketRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTicketRepository(db *pgxpool.Pool) *TicketRepository {
+	return &TicketRepository{db: db}
+}
+
+const ticketColumns = `id, machine_id, reporter_id, category, description, COALESCE(photo_path, ''), status,
+	COALESCE(admin_note, ''), created_at, updated_at, resolved_at`
+
+func scanTicket(row pgx.Row) (*models.RepairTicket, error) {
+	var t models.RepairTicket
+	err := row.Scan(&t.ID, &t.MachineID, &t.ReporterID, &t.Category, &t.Description, &t.PhotoPath, &t.Status,
+		&t.AdminNote, &t.CreatedAt, &t.UpdatedAt, &t.ResolvedAt)
+	if err != nil {
+		return nil, err
+	}
+	t.HasPhoto = t.PhotoPath != ""
+	return &t, nil
+}
+
+func (r *TicketRepository) Create(ctx context.Context, t *models.RepairTicket) error {
+	query := `
+		INSERT INTO repair_tickets (machine_id, reporter_id, category, description, photo_path, status)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)
+		RETURNING id, created_at, updated_at
+	`
+	err := r.db.QueryRow(ctx, query, t.MachineID, t.ReporterID, t.Category, t.Description, t.PhotoPath, t.Status).
+		Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create ticket: %w", err)
+	}
+	t.HasPhoto = t.PhotoPath != ""
+	return nil
+}
+
+func (r *TicketRepository) GetByID(ctx context.Context, id int) (*models.RepairTicket, error) {
+	query := `SELECT ` + ticketColumns + ` FROM repair_tickets WHERE id = $1`
+	t, err := scanTicket(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get ticket: %w", err)
+	}
+	return t, nil
+}
+
+func (r *TicketRepository) List(ctx context.Context, status string, machineID int) ([]models.RepairTicket, error) {
+	query := `
+		SELECT ` + ticketColumns + `
+		FROM repair_tickets
+		WHERE ($1 = '' OR status = $1) AND ($2 = 0 OR machine_id = $2)
+		ORDER BY created_at DESC
+	`
+	return r.query(ctx, query, status, machineID)
+}
+
+func (r *TicketRepository) GetByReporter(ctx context.Context, reporterID int) ([]models.RepairTicket, error) {
+	query := `SELECT ` + ticketColumns + ` FROM repair_tickets WHERE reporter_id = $1 ORDER BY created_at DESC`
+	return r.query(ctx, query, reporterID)
+}
+
+// UpdateStatus переводит заявку из from в to, только если её статус всё ещё
+// from; иначе возвращается ErrTicketStatusChanged.
+func (r *TicketRepository) UpdateStatus(ctx context.Context, id int, from, to, note string) error {
+	query := `
+		UPDATE repair_tickets
+		SET status = $1,
+		    admin_note = COALESCE(NULLIF($2, ''), admin_note),
+		    updated_at = NOW(),
+		    resolved_at = CASE WHEN $1 = 'resolved' THEN NOW() ELSE resolved_at END
+		WHERE id = $3 AND status = $4
+	`
+	result, err := r.db.Exec(ctx, query, to, note, id, from)
+	if err != nil {
+		return fmt.Errorf("failed to update ticket: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrTicketStatusChanged
+	}
+	return nil
+}
+
+func (r *TicketRepository) CountInRepair(ctx context.Context, machineID int) (int, error) {
+	query := `SELECT COUNT(*) FROM repair_tickets WHERE machine_id = $1 AND status = 'in_repair'`
+	var count int
+	if err := r.db.QueryRow(ctx, query, machineID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count tickets: %w", err)
+	}
+	return count, nil
+}
+
+func (r *TicketRepository) query(ctx context.Context, query string, args ...interface{}) ([]models.RepairTicket, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tickets: %w", err)
+	}
+	defer rows.Close()
+
+	var tickets []models.RepairTicket
+	for rows.Next() {
+		t, err := scanTicket(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ticket: %w", err)
+		}
+		tickets = append(tickets, *t)
+	}
+	return tickets, rows.Err()
+}
+
+func (r *TicketRepository) CountUnresolvedWithoutReporter(ctx context.Context, machineID int) (int, error) {
+	query := `SELECT COUNT(*) FROM repair_tickets WHERE machine_id = $1 AND reporter_id IS NULL AND status <> 'resolved'`
+	var count int
+	if err := r.db.QueryRow(ctx, query, machineID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count tickets: %w", err)
+	}
+	return count, nil
+}
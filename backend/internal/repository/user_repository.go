@@ -70,6 +70,24 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.
 	return &user, nil
 }
 
+func (r *UserRepository) GetByID(ctx context.Context, id int) (*models.User, error) {
+	query := `SELECT id, email, login, password_hash, role, email_verified, created_at FROM users WHERE id = $1`
+
+	var user models.User
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&user.ID, &user.Email, &user.Login, &user.PasswordHash, &user.Role, &user.EmailVerified, &user.CreatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return &user, nil
+}
+
 func (r *UserRepository) VerifyEmailByToken(ctx context.Context, token string) error {
 	query := `UPDATE users SET email_verified = true, verification_token = NULL WHERE verification_token = $1`
 
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"netiwash/internal/models"
+)
+
+// VAPIDKeyFile хранит ключи VAPID в JSON-файле — для установок, где
+// секреты раздаются файлами (Docker/Kubernetes secrets), а не лежат в БД.
+type VAPIDKeyFile struct {
+	path string
+}
+
+func NewVAPIDKeyFile(path string) *VAPIDKeyFile {
+	return &VAPIDKeyFile{path: path}
+}
+
+// List возвращает ключи из файла; отсутствующий файл — пустой список.
+func (f *VAPIDKeyFile) List(ctx context.Context) ([]models.VAPIDKey, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read VAPID keys: %w", err)
+	}
+	var keys []models.VAPIDKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", f.path, err)
+	}
+	return keys, nil
+}
+
+func (f *VAPIDKeyFile) Add(ctx context.Context, key models.VAPIDKey) error {
+	keys, err := f.List(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range keys {
+		if keys[i].Active {
+			keys[i].Active = false
+			retiredAt := key.CreatedAt
+			keys[i].RetiredAt = &retiredAt
+		}
+	}
+	key.Active = true
+	return f.write(append([]models.VAPIDKey{key}, keys...))
+}
+
+func (f *VAPIDKeyFile) DeleteRetired(ctx context.Context, before time.Time) (int64, error) {
+	keys, err := f.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	kept := keys[:0]
+	for _, k := range keys {
+		if !k.Active && k.RetiredAt != nil && k.RetiredAt.Before(before) {
+			continue
+		}
+		kept = append(kept, k)
+	}
+	deleted := int64(len(keys) - len(kept))
+	if deleted == 0 {
+		return 0, nil
+	}
+	return deleted, f.write(kept)
+}
+
+// write заменяет файл атомарно: читатели не увидят его наполовину записанным.
+func (f *VAPIDKeyFile) write(keys []models.VAPIDKey) error {
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), ".vapid-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write VAPID keys: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write VAPID keys: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write VAPID keys: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("failed to write VAPID keys: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"netiwash/internal/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// VAPIDKeyRepository хранит ключи VAPID в таблице vapid_keys.
+type VAPIDKeyRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewVAPIDKeyRepository(db *pgxpool.Pool) *VAPIDKeyRepository {
+	return &VAPIDKeyRepository{db: db}
+}
+
+func (r *VAPIDKeyRepository) List(ctx context.Context) ([]models.VAPIDKey, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT public_key, private_key, active, created_at, retired_at
+		FROM vapid_keys
+		ORDER BY active DESC, created_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VAPID keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []models.VAPIDKey
+	for rows.Next() {
+		var k models.VAPIDKey
+		if err := rows.Scan(&k.PublicKey, &k.PrivateKey, &k.Active, &k.CreatedAt, &k.RetiredAt); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// Add делает key активным, а прежний активный ключ выводит из оборота.
+func (r *VAPIDKeyRepository) Add(ctx context.Context, key models.VAPIDKey) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `UPDATE vapid_keys SET active = FALSE, retired_at = $1 WHERE active`, key.CreatedAt); err != nil {
+		return fmt.Errorf("failed to retire VAPID key: %w", err)
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO vapid_keys (public_key, private_key, active, created_at)
+		VALUES ($1, $2, TRUE, $3)
+	`, key.PublicKey, key.PrivateKey, key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save VAPID key: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// DeleteRetired удаляет ключи, выведенные из оборота раньше before.
+func (r *VAPIDKeyRepository) DeleteRetired(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM vapid_keys WHERE NOT active AND retired_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete VAPID keys: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
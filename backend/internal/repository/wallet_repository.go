@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"netiwash/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type WalletRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewWalletRepository(db *pgxpool.Pool) *WalletRepository {
+	return &WalletRepository{db: db}
+}
+
+func (r *WalletRepository) GetByUserID(ctx context.Context, userID int) (*models.Wallet, error) {
+	query := `SELECT user_id, balance, updated_at FROM wallets WHERE user_id = $1`
+
+	w := models.Wallet{UserID: userID}
+	err := r.db.QueryRow(ctx, query, userID).Scan(&w.UserID, &w.Balance, &w.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return &w, nil
+		}
+		return nil, fmt.Errorf("failed to get wallet: %w", err)
+	}
+	return &w, nil
+}
+
+func (r *WalletRepository) GetTransactions(ctx context.Context, userID int, limit int) ([]models.WalletTransaction, error) {
+	query := `
+		SELECT id, user_id, amount, reason, payment_id, created_at
+		FROM wallet_transactions
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+	rows, err := r.db.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query wallet transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var txs []models.WalletTransaction
+	for rows.Next() {
+		var t models.WalletTransaction
+		if err := rows.Scan(&t.ID, &t.UserID, &t.Amount, &t.Reason, &t.PaymentID, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan wallet transaction: %w", err)
+		}
+		txs = append(txs, t)
+	}
+	return txs, rows.Err()
+}
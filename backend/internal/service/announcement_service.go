@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"netiwash/internal/models"
+	"netiwash/internal/repository"
+	"netiwash/pkg/utils"
+)
+
+var ErrInvalidAnnouncement = errors.New("invalid announcement")
+
+const (
+	// announcementAudienceWindow — за какой срок брони в корпусе или комнате
+	// делают пользователя получателем объявления для них.
+	announcementAudienceWindow = 30 * 24 * time.Hour
+	// announcementFeedWindow и announcementFeedLimit — сколько объявлений
+	// показывать в приложении.
+	announcementFeedWindow = 30 * 24 * time.Hour
+	announcementFeedLimit  = 50
+	// announcementFanOutInterval и announcementFanOutBatch — как часто и
+	// сколькими получателями воркер рассылает объявления.
+	announcementFanOutInterval = 10 * time.Second
+	announcementFanOutBatch    = 200
+)
+
+// AnnouncementService сохраняет объявления вместе со списком получателей.
+// Рассылает их воркер на экземпляре-лидере: каждому получателю — через
+// SendNotification, то есть через журнал доставок с повторами.
+type AnnouncementService struct {
+	repo          *repository.AnnouncementRepository
+	notifications *NotificationService
+}
+
+func NewAnnouncementService(repo *repository.AnnouncementRepository, notifications *NotificationService) *AnnouncementService {
+	return &AnnouncementService{repo: repo, notifications: notifications}
+}
+
+// Create сохраняет объявление и фиксирует получателей. Разошлёт его
+// RunWorker по каналам, которые каждый получатель включил для объявлений.
+func (s *AnnouncementService) Create(ctx context.Context, authorID int, req *models.CreateAnnouncementRequest) (*models.Announcement, error) {
+	loc := utils.LaundryLocation()
+	now := time.Now().In(loc)
+
+	a := &models.Announcement{
+		Title:     strings.TrimSpace(req.Title),
+		Body:      strings.TrimSpace(req.Body),
+		Target:    req.Target,
+		AuthorID:  &authorID,
+		CreatedAt: now,
+	}
+	if a.Title == "" || a.Body == "" {
+		return nil, fmt.Errorf("%w: title and body are required", ErrInvalidAnnouncement)
+	}
+
+	switch req.Target {
+	case models.AnnouncementTargetBuilding:
+		a.TargetValue = strings.TrimSpace(req.Building)
+		if a.TargetValue == "" {
+			return nil, fmt.Errorf("%w: building is required", ErrInvalidAnnouncement)
+		}
+	case models.AnnouncementTargetRoom:
+		a.TargetValue = strings.TrimSpace(req.Room)
+		if a.TargetValue == "" {
+			return nil, fmt.Errorf("%w: room is required", ErrInvalidAnnouncement)
+		}
+	case models.AnnouncementTargetBookings:
+		if req.From == nil || req.To == nil || !req.To.After(*req.From) {
+			return nil, fmt.Errorf("%w: from and to are required, from < to", ErrInvalidAnnouncement)
+		}
+		from, to := req.From.In(loc), req.To.In(loc)
+		a.RangeStart, a.RangeEnd = &from, &to
+	}
+
+	recipients, err := s.repo.Create(ctx, a, now.Add(-announcementAudienceWindow))
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("📢 [ANNOUNCEMENT] #%d (%s %s) by user %d, %d recipients", a.ID, a.Target, a.TargetValue, authorID, len(recipients))
+
+	a.Audience = announcementAudience(a)
+	return a, nil
+}
+
+// RunWorker рассылает объявления получателям, которым они ещё не ушли.
+func (s *AnnouncementService) RunWorker(ctx context.Context) {
+	ticker := time.NewTicker(announcementFanOutInterval)
+	defer ticker.Stop()
+	log.Println("📢 [ANNOUNCEMENT] Announcement worker started")
+	s.fanOut(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.fanOut(ctx)
+		}
+	}
+}
+
+// fanOut записывает уведомления для очередной пачки получателей. Получатель
+// отмечается после того, как его доставки записаны в журнал; если запись не
+// удалась, он остаётся в очереди до следующего прохода. Падение между
+// записью и отметкой может дать повторное уведомление, но не потерю.
+func (s *AnnouncementService) fanOut(ctx context.Context) {
+	for ctx.Err() == nil {
+		pending, err := s.repo.GetPending(ctx, announcementFanOutBatch)
+		if err != nil {
+			log.Printf("📢 [ANNOUNCEMENT] %v", err)
+			return
+		}
+		if len(pending) == 0 {
+			return
+		}
+
+		for _, rcp := range pending {
+			err := s.notifications.SendNotification(ctx, rcp.UserID, models.Notification{
+				Kind:     models.NotificationKindAnnouncement,
+				Template: tmplAnnouncement,
+				Params:   map[string]any{"Title": rcp.Title, "Body": rcp.Body},
+				URL:      "main.html",
+				Tag:      fmt.Sprintf("announcement-%d", rcp.AnnouncementID),
+			})
+			if err != nil {
+				log.Printf("📢 [ANNOUNCEMENT] #%d to user %d not queued, will retry: %v", rcp.AnnouncementID, rcp.UserID, err)
+				return
+			}
+			if err := s.repo.MarkNotified(ctx, rcp.AnnouncementID, rcp.UserID, time.Now().In(utils.LaundryLocation())); err != nil {
+				log.Printf("📢 [ANNOUNCEMENT] %v", err)
+				return
+			}
+		}
+		if len(pending) < announcementFanOutBatch {
+			return
+		}
+	}
+}
+
+// announcementAudience описывает словами, кто получил объявление.
+func announcementAudience(a *models.Announcement) string {
+	const window = "с бронями за последние 30 дней или будущими"
+	switch a.Target {
+	case models.AnnouncementTargetAll:
+		return "все пользователи"
+	case models.AnnouncementTargetBuilding:
+		return fmt.Sprintf("пользователи %s на машины корпуса %s", window, a.TargetValue)
+	case models.AnnouncementTargetRoom:
+		return fmt.Sprintf("пользователи %s на машины комнаты %s", window, a.TargetValue)
+	case models.AnnouncementTargetBookings:
+		if a.RangeStart != nil && a.RangeEnd != nil {
+			return fmt.Sprintf("владельцы броней с %s по %s",
+				a.RangeStart.Format("02.01 15:04"), a.RangeEnd.Format("02.01 15:04"))
+		}
+	}
+	return ""
+}
+
+// GetForUser возвращает объявления за последний месяц, адресованные пользователю.
+func (s *AnnouncementService) GetForUser(ctx context.Context, userID int) ([]models.Announcement, error) {
+	since := time.Now().In(utils.LaundryLocation()).Add(-announcementFeedWindow)
+	return s.repo.GetForUser(ctx, userID, since, announcementFeedLimit)
+}
+
+// GetRecent — последние объявления для админки, с описанием получателей.
+func (s *AnnouncementService) GetRecent(ctx context.Context) ([]models.Announcement, error) {
+	list, err := s.repo.GetRecent(ctx, announcementFeedLimit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		if list[i].RangeStart != nil {
+			start := utils.InLaundryLocation(*list[i].RangeStart)
+			list[i].RangeStart = &start
+		}
+		if list[i].RangeEnd != nil {
+			end := utils.InLaundryLocation(*list[i].RangeEnd)
+			list[i].RangeEnd = &end
+		}
+		list[i].Audience = announcementAudience(&list[i])
+	}
+	return list, nil
+}
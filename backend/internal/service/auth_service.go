@@ -14,13 +14,15 @@ import (
 
 type AuthService struct {
 	repo         *repository.UserRepository
+	prefsRepo    *repository.NotificationPreferencesRepository
 	jwtSecret    string
 	emailService *utils.EmailService
 }
 
-func NewAuthService(repo *repository.UserRepository, jwtSecret string, emailService *utils.EmailService) *AuthService {
+func NewAuthService(repo *repository.UserRepository, prefsRepo *repository.NotificationPreferencesRepository, jwtSecret string, emailService *utils.EmailService) *AuthService {
 	return &AuthService{
 		repo:         repo,
+		prefsRepo:    prefsRepo,
 		jwtSecret:    jwtSecret,
 		emailService: emailService,
 	}
@@ -60,8 +62,7 @@ func (s *AuthService) Register(ctx context.Context, req *models.RegisterRequest)
 	}
 
 	go func() {
-		verifyURL := fmt.Sprintf("http://localhost:8081/verify.html?token=%s", verificationToken)
-		err := s.emailService.SendVerificationEmail(user.Email, verifyURL)
+		err := s.emailService.SendVerificationEmail(user.Email, models.LocaleRU, verificationToken)
 		if err != nil {
 			fmt.Printf("Failed to send verification email: %v\n", err)
 		}
@@ -102,6 +103,11 @@ func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest) (*mod
 	return &response, nil
 }
 
+// IssueStreamTicket выдаёт билет на подключение к потоку событий.
+func (s *AuthService) IssueStreamTicket(userID int, role string) (string, error) {
+	return utils.GenerateStreamTicket(userID, role, s.jwtSecret)
+}
+
 func (s *AuthService) VerifyEmail(ctx context.Context, token string) error {
 	log.Printf("📧 [EMAIL_VERIFY] Verifying token: %s...", token[:min(16, len(token))])
 	err := s.repo.VerifyEmailByToken(ctx, token)
@@ -136,7 +142,7 @@ func (s *AuthService) RequestPasswordReset(ctx context.Context, email string) er
 	log.Printf("📧 [PASSWORD_RESET] Token saved for user %d, expires: %v", user.ID, expiry)
 
 	go func() {
-		err := s.emailService.SendPasswordResetEmail(user.Email, resetToken)
+		err := s.emailService.SendPasswordResetEmail(user.Email, userLocale(context.Background(), s.prefsRepo, user.ID), resetToken)
 		if err != nil {
 			log.Printf("📧 [PASSWORD_RESET] Send email error: %v", err)
 		} else {
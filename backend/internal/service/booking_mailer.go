@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"netiwash/internal/models"
+	"netiwash/internal/repository"
+	"netiwash/pkg/utils"
+)
+
+// BookingMailer отправляет письма о бронях с .ics-вложением: подтверждение,
+// перенос, продление, отмену администратором, из-за списания машины и из-за
+// обслуживания. Письмо
+// уходит, только если у пользователя включена почта для вида booking.
+type BookingMailer struct {
+	email               *utils.EmailService
+	users               *repository.UserRepository
+	machineRepo         *repository.MachineRepository
+	notificationService *NotificationService
+	publicURL           string
+}
+
+func NewBookingMailer(email *utils.EmailService, users *repository.UserRepository, machineRepo *repository.MachineRepository, notificationService *NotificationService, publicURL string) *BookingMailer {
+	return &BookingMailer{
+		email:               email,
+		users:               users,
+		machineRepo:         machineRepo,
+		notificationService: notificationService,
+		publicURL:           strings.TrimRight(publicURL, "/"),
+	}
+}
+
+// HandleEvent — подписчик шины событий. Письмо отправляется в фоне, чтобы
+// не задерживать запрос, поднявший событие. Подтверждение, перенос и
+// продление попадают и во входящие; об отменах туда пишет
+// NotificationService.
+func (m *BookingMailer) HandleEvent(ctx context.Context, e models.Event) {
+	if e.Type != models.EventBookingStatus || e.UserID == nil {
+		return
+	}
+	st, ok := e.Data.(models.BookingStatusEvent)
+	if !ok {
+		return
+	}
+
+	switch {
+	case st.Event == models.EventBookingCreated, st.Event == models.EventBookingRescheduled, st.Event == models.EventBookingExtended:
+	case st.Event == models.EventBookingCancelled && st.Cancellation != nil:
+	default:
+		return
+	}
+
+	userID := *e.UserID
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Minute)
+		defer cancel()
+		if st.Cancellation == nil {
+			m.addToInbox(ctx, userID, st)
+		}
+		if err := m.send(ctx, userID, st); err != nil {
+			log.Printf("📧 [EMAIL] Booking %d mail to user %d failed: %v", st.BookingID, userID, err)
+		}
+	}()
+}
+
+func (m *BookingMailer) send(ctx context.Context, userID int, st models.BookingStatusEvent) error {
+	if !m.notificationService.WantsChannel(ctx, userID, models.NotificationKindBooking, models.ChannelEmail) {
+		return nil
+	}
+
+	user, err := m.users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.EmailVerified {
+		return nil
+	}
+
+	machine, err := m.machineRepo.GetByID(ctx, st.MachineID)
+	if err != nil {
+		return err
+	}
+	if machine == nil {
+		return fmt.Errorf("machine %d not found", st.MachineID)
+	}
+
+	locale := userLocale(ctx, m.notificationService.prefsRepo, userID)
+	data := m.mailData(st, machine, locale)
+	summary, description := "Стирка: ", "Бронь #%d в NETI WASH. %s"
+	if locale == models.LocaleEN {
+		summary, description = "Laundry: ", "NETI WASH booking #%d. %s"
+	}
+	event := utils.CalendarEvent{
+		UID:         fmt.Sprintf("booking-%d@netiwash", st.BookingID),
+		Summary:     summary + machine.Name,
+		Description: fmt.Sprintf(description, st.BookingID, m.publicURL+"/bookings.html"),
+		Location:    data["Location"].(string),
+		Organizer:   m.email.From(),
+		Attendee:    user.Email,
+		Start:       st.StartTime,
+		End:         st.EndTime,
+		Sequence:    int(time.Now().Unix()),
+		Cancelled:   st.Event == models.EventBookingCancelled,
+	}
+
+	attachment := utils.EmailAttachment{
+		Filename:    fmt.Sprintf("booking-%d.ics", st.BookingID),
+		ContentType: "text/calendar; charset=utf-8; method=" + event.ICSMethod(),
+		Data:        utils.BuildICS(event),
+	}
+	return m.email.SendTemplate(user.Email, "booking", locale, data, attachment)
+}
+
+func (m *BookingMailer) addToInbox(ctx context.Context, userID int, st models.BookingStatusEvent) {
+	machine, err := m.machineRepo.GetByID(ctx, st.MachineID)
+	if err != nil || machine == nil {
+		log.Printf("📧 [EMAIL] Booking %d: machine %d not found for inbox: %v", st.BookingID, st.MachineID, err)
+		return
+	}
+
+	m.notificationService.AddToInbox(ctx, userID, models.Notification{
+		Kind:     models.NotificationKindBooking,
+		Template: bookingInboxTemplate(st.Event),
+		Params: map[string]any{
+			"BookingID": st.BookingID,
+			"Machine":   machine.Name,
+			"Start":     utils.InLaundryLocation(st.StartTime).Format("02.01 15:04"),
+			"End":       utils.InLaundryLocation(st.EndTime).Format("15:04"),
+		},
+		URL: "bookings.html",
+	})
+}
+
+// bookingInboxTemplate выбирает шаблон входящих для события о брони.
+func bookingInboxTemplate(event string) string {
+	switch event {
+	case models.EventBookingRescheduled:
+		return tmplBookingRescheduled
+	case models.EventBookingExtended:
+		return tmplBookingExtended
+	}
+	return tmplBookingConfirmed
+}
+
+// mailData — данные для шаблона booking. Event — одно из confirmed,
+// rescheduled, extended, cancelled_admin, cancelled_machine,
+// cancelled_maintenance.
+func (m *BookingMailer) mailData(st models.BookingStatusEvent, machine *models.Machine, locale string) map[string]any {
+	start := utils.InLaundryLocation(st.StartTime)
+	end := utils.InLaundryLocation(st.EndTime)
+
+	event, reason := "confirmed", ""
+	switch {
+	case st.Event == models.EventBookingRescheduled:
+		event = "rescheduled"
+	case st.Event == models.EventBookingExtended:
+		event = "extended"
+	case st.Cancellation != nil && st.Cancellation.Cause == models.CancelCauseMaintenance:
+		event, reason = "cancelled_maintenance", st.Cancellation.Reason
+	case st.Cancellation != nil && st.Cancellation.Cause == models.CancelCauseMachineRemoved:
+		event, reason = "cancelled_machine", st.Cancellation.Reason
+	case st.Cancellation != nil:
+		event, reason = "cancelled_admin", st.Cancellation.Reason
+	}
+
+	return map[string]any{
+		"Event":     event,
+		"BookingID": st.BookingID,
+		"Machine":   machine.Name,
+		"Location":  machineLocation(machine, locale),
+		"Date":      start.Format("02.01.2006"),
+		"Time":      start.Format("15:04") + "–" + end.Format("15:04"),
+		"Reason":    reason,
+		"AppURL":    m.publicURL,
+	}
+}
+
+func machineLocation(machine *models.Machine, locale string) string {
+	room, floor := "комната %s", "%d этаж"
+	if locale == models.LocaleEN {
+		room, floor = "room %s", "floor %d"
+	}
+	var parts []string
+	if machine.Room != "" {
+		parts = append(parts, fmt.Sprintf(room, machine.Room))
+	}
+	if machine.Floor != nil {
+		parts = append(parts, fmt.Sprintf(floor, *machine.Floor))
+	}
+	if machine.Position != "" {
+		parts = append(parts, machine.Position)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"netiwash/internal/models"
+	"netiwash/internal/repository"
+	"netiwash/pkg/utils"
+)
+
+func TestBookingMailTemplates(t *testing.T) {
+	m := &BookingMailer{publicURL: "http://localhost"}
+	machine := &models.Machine{Name: "Машинка #1", Room: "101"}
+
+	tests := []struct {
+		name   string
+		event  string
+		cancel *models.BookingCancellation
+		mail   string
+		inbox  string
+	}{
+		{"created", models.EventBookingCreated, nil, "confirmed", tmplBookingConfirmed},
+		{"rescheduled", models.EventBookingRescheduled, nil, "rescheduled", tmplBookingRescheduled},
+		{"extended", models.EventBookingExtended, nil, "extended", tmplBookingExtended},
+		{"cancelled by admin", models.EventBookingCancelled, &models.BookingCancellation{Cause: models.CancelCauseAdmin}, "cancelled_admin", ""},
+		{"machine removed", models.EventBookingCancelled, &models.BookingCancellation{Cause: models.CancelCauseMachineRemoved}, "cancelled_machine", ""},
+		{"maintenance", models.EventBookingCancelled, &models.BookingCancellation{Cause: models.CancelCauseMaintenance}, "cancelled_maintenance", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := models.BookingStatusEvent{BookingID: 7, Event: tt.event, Cancellation: tt.cancel}
+			if got := m.mailData(st, machine, models.LocaleRU)["Event"]; got != tt.mail {
+				t.Errorf("mail event = %v, want %s", got, tt.mail)
+			}
+			if tt.inbox != "" {
+				if got := bookingInboxTemplate(tt.event); got != tt.inbox {
+					t.Errorf("inbox template = %s, want %s", got, tt.inbox)
+				}
+			}
+		})
+	}
+}
+
+func TestBookingMailerAddsToInbox(t *testing.T) {
+	pool := testDB(t)
+	user := createTestUser(t, pool, "resident")
+	machine := createTestMachine(t, pool, "Машинка #1")
+
+	notifications := NewNotificationService(
+		repository.NewPushRepository(pool),
+		repository.NewBookingRepository(pool),
+		repository.NewNotificationPreferencesRepository(pool),
+		repository.NewNotificationDeliveryRepository(pool),
+		repository.NewInboxRepository(pool),
+		nopPublisher{}, nil, nil,
+	)
+	mailer := NewBookingMailer(nil, repository.NewUserRepository(pool), repository.NewMachineRepository(pool), notifications, "http://localhost")
+	ctx := context.Background()
+
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, utils.LaundryLocation())
+	for _, event := range []string{models.EventBookingCreated, models.EventBookingRescheduled, models.EventBookingExtended} {
+		mailer.addToInbox(ctx, user.ID, models.BookingStatusEvent{
+			BookingID: 7,
+			MachineID: machine.ID,
+			Event:     event,
+			StartTime: start,
+			EndTime:   start.Add(bookingDuration + bookingExtension),
+		})
+	}
+
+	page, err := notifications.GetInbox(ctx, user.ID, false, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"Бронь #7 на Машинка #1 продлена до 11:30.",
+		"Бронь #7 перенесена: Машинка #1, 02.03 10:00.",
+		"Бронь #7: Машинка #1, 02.03 10:00.",
+	}
+	if len(page.Notifications) != len(want) {
+		t.Fatalf("inbox = %+v, want %d notifications", page.Notifications, len(want))
+	}
+	for i, n := range page.Notifications {
+		if n.Kind != models.NotificationKindBooking || n.Body != want[i] {
+			t.Errorf("inbox[%d] = %s %q, want booking %q", i, n.Kind, n.Body, want[i])
+		}
+	}
+}
@@ -7,32 +7,63 @@ import (
 
 	"netiwash/internal/models"
 	"netiwash/internal/repository"
+	"netiwash/pkg/utils"
+)
+
+var (
+	ErrBookingNotFound  = errors.New("booking not found")
+	ErrBookingNotActive = errors.New("booking is not active")
+	ErrTimeSlotBusy     = errors.New("time slot is busy")
+	ErrBookingInPast    = errors.New("cannot book in the past")
+	ErrTooManyBookings  = errors.New("максимум 5 активных бронирований")
+)
+
+const (
+	maxActiveBookings   = 5
+	bookingDuration     = time.Hour
+	bookingExtension    = 30 * time.Minute // продление из уведомления, один раз
+	bookingEndingNotice = 10 * time.Minute // за сколько до конца брони предлагаем продлить
 )
 
 type BookingService struct {
-	repo *repository.BookingRepository
+	repo           *repository.BookingRepository
+	machineRepo    *repository.MachineRepository
+	machineService *MachineService
+	events         EventPublisher
 }
 
-func NewBookingService(repo *repository.BookingRepository) *BookingService {
-	return &BookingService{repo: repo}
+func NewBookingService(repo *repository.BookingRepository, machineRepo *repository.MachineRepository, machineService *MachineService, events EventPublisher) *BookingService {
+	return &BookingService{
+		repo:           repo,
+		machineRepo:    machineRepo,
+		machineService: machineService,
+		events:         events,
+	}
 }
 
 func (s *BookingService) Create(ctx context.Context, userID, machineID int, startTime time.Time) (*models.Booking, error) {
-	duration := time.Hour
-	endTime := startTime.Add(duration)
+	endTime := startTime.Add(bookingDuration)
 
 	if startTime.Before(time.Now()) {
 		if startTime.Add(1 * time.Minute).Before(time.Now()) {
-			return nil, errors.New("cannot book in the past")
+			return nil, ErrBookingInPast
 		}
 	}
 
+	machine, err := s.machineRepo.GetByID(ctx, machineID)
+	if err != nil {
+		return nil, err
+	}
+	if machine == nil || !machine.IsActive {
+		return nil, ErrMachineNotFound
+	}
+
 	activeCount, err := s.repo.CountActiveBookingsByUser(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
-	if activeCount >= 5 {
-		return nil, errors.New("максимум 5 активных бронирований")
+	if activeCount >= maxActiveBookings {
+		return nil, ErrTooManyBookings
 	}
 
 	available, err := s.repo.CheckAvailability(ctx, machineID, startTime, endTime)
@@ -40,7 +71,7 @@ func (s *BookingService) Create(ctx context.Context, userID, machineID int, star
 		return nil, err
 	}
 	if !available {
-		return nil, errors.New("time slot is busy")
+		return nil, ErrTimeSlotBusy
 	}
 
 	booking := &models.Booking{
@@ -55,6 +86,8 @@ func (s *BookingService) Create(ctx context.Context, userID, machineID int, star
 		return nil, err
 	}
 
+	publishBooking(ctx, s.events, models.EventBookingCreated, booking, booking.Status, nil)
+	s.machineService.PublishState(ctx, machineID)
 	return booking, nil
 }
 
@@ -66,13 +99,134 @@ func (s *BookingService) GetAll(ctx context.Context, isAdmin bool, userID int) (
 }
 
 func (s *BookingService) Cancel(ctx context.Context, id int, userID int, isAdmin bool) error {
-	return s.repo.Cancel(ctx, id)
+	b, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if b == nil || (!isAdmin && b.UserID != userID) {
+		return ErrBookingNotFound
+	}
+	if err := s.repo.Cancel(ctx, id); err != nil {
+		return err
+	}
+
+	var cancel *models.BookingCancellation
+	if isAdmin && b.UserID != userID {
+		cancel = &models.BookingCancellation{Cause: models.CancelCauseAdmin, Reason: "её отменил администратор"}
+	}
+	publishBooking(ctx, s.events, models.EventBookingCancelled, b, "cancelled", cancel)
+	s.machineService.PublishState(ctx, b.MachineID)
+	return nil
+}
+
+// Reschedule переносит бронь на новое время начала той же машины. Чужие
+// брони может переносить только админ.
+func (s *BookingService) Reschedule(ctx context.Context, id, userID int, isAdmin bool, startTime time.Time) (*models.Booking, error) {
+	b, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil || (!isAdmin && b.UserID != userID) {
+		return nil, ErrBookingNotFound
+	}
+	if b.Status != "active" {
+		return nil, ErrBookingNotActive
+	}
+	if startTime.Add(1 * time.Minute).Before(time.Now()) {
+		return nil, ErrBookingInPast
+	}
+
+	endTime := startTime.Add(bookingDuration)
+	ok, err := s.repo.Reschedule(ctx, id, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrTimeSlotBusy
+	}
+
+	b.StartTime = startTime
+	b.EndTime = endTime
+	publishBooking(ctx, s.events, models.EventBookingExtended, b, b.Status, nil)
+	s.machineService.PublishState(ctx, b.MachineID)
+	return b, nil
+}
+
+// Extend продлевает бронь владельца на bookingExtension. Продлить можно
+// один раз: повторный вызов возвращает бронь без изменений.
+func (s *BookingService) Extend(ctx context.Context, id, userID int) (*models.Booking, error) {
+	b, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil || b.UserID != userID {
+		return nil, ErrBookingNotFound
+	}
+	now := time.Now().In(utils.LaundryLocation())
+	if b.Status != "active" || !utils.InLaundryLocation(b.EndTime).After(now) {
+		return nil, ErrBookingNotActive
+	}
+
+	endTime := b.StartTime.Add(bookingDuration + bookingExtension)
+	if !b.EndTime.Before(endTime) {
+		return b, nil
+	}
+	ok, err := s.repo.Extend(ctx, id, endTime)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrTimeSlotBusy
+	}
+
+	b.EndTime = endTime
+	publishBooking(ctx, s.events, models.EventBookingExtended, b, b.Status, nil)
+	s.machineService.PublishState(ctx, b.MachineID)
+	return b, nil
+}
+
+// MarkPickedUp отмечает, что владелец забрал бельё после своей стирки, и
+// машина больше не ждёт его. Если цикла в рамках брони не было, ничего не
+// меняется.
+func (s *BookingService) MarkPickedUp(ctx context.Context, id, userID int) error {
+	b, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if b == nil || b.UserID != userID {
+		return ErrBookingNotFound
+	}
+	m, err := s.machineService.GetByID(ctx, b.MachineID)
+	if err != nil {
+		return err
+	}
+	if m.LastCycleEndedAt == nil || m.LastCycleEndedAt.Before(b.StartTime) {
+		return nil
+	}
+	return s.machineService.MarkPickedUp(ctx, m.ID)
 }
 
 func (s *BookingService) GetByID(ctx context.Context, id int) (*models.Booking, error) {
-	return s.repo.GetByID(ctx, id)
+	b, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, ErrBookingNotFound
+	}
+	return b, nil
 }
 
 func (s *BookingService) CompleteBooking(ctx context.Context, id int) error {
-	return s.repo.UpdateStatus(ctx, id, "completed")
+	b, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Complete(ctx, id); err != nil {
+		return err
+	}
+
+	publishBooking(ctx, s.events, models.EventBookingCompleted, b, "completed", nil)
+	s.machineService.PublishState(ctx, b.MachineID)
+	return nil
 }
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"netiwash/internal/models"
+	"netiwash/internal/repository"
+	"netiwash/pkg/utils"
+)
+
+// TestBookingPublishesEventKinds проверяет, какое событие уходит владельцу
+// брони: по нему BookingMailer выбирает письмо и запись во входящих, а
+// вкладки — обработчик SSE.
+func TestBookingPublishesEventKinds(t *testing.T) {
+	pool := testDB(t)
+	user := createTestUser(t, pool, "resident")
+	machine := createTestMachine(t, pool, "Машинка #1")
+
+	events := &recordingPublisher{}
+	machineRepo := repository.NewMachineRepository(pool)
+	bookingRepo := repository.NewBookingRepository(pool)
+	machineService := NewMachineService(machineRepo, bookingRepo, nil, nopPublisher{})
+	bookings := NewBookingService(bookingRepo, machineRepo, machineService, events)
+	ctx := context.Background()
+
+	start := time.Now().In(utils.LaundryLocation()).Truncate(time.Minute)
+	b, err := bookings.Create(ctx, user.ID, machine.ID, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := events.bookingEvents(); !slices.Equal(got, []string{models.EventBookingCreated}) {
+		t.Fatalf("Create published %v", got)
+	}
+
+	extended, err := bookings.Extend(ctx, b.ID, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := start.Add(bookingDuration + bookingExtension); !utils.InLaundryLocation(extended.EndTime).Equal(want) {
+		t.Fatalf("Extend end = %v, want %v", extended.EndTime, want)
+	}
+	if got := events.bookingEvents(); !slices.Equal(got, []string{models.EventBookingExtended}) {
+		t.Fatalf("Extend published %v, want %s", got, models.EventBookingExtended)
+	}
+
+	// Повторное продление ничего не меняет и не шлёт событие.
+	if _, err := bookings.Extend(ctx, b.ID, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := events.bookingEvents(); len(got) != 0 {
+		t.Fatalf("repeated Extend published %v", got)
+	}
+
+	if _, err := bookings.Reschedule(ctx, b.ID, user.ID, false, start.Add(3*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if got := events.bookingEvents(); !slices.Equal(got, []string{models.EventBookingRescheduled}) {
+		t.Fatalf("Reschedule published %v, want %s", got, models.EventBookingRescheduled)
+	}
+}
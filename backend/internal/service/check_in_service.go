@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"netiwash/internal/models"
+	"netiwash/internal/repository"
+	"netiwash/pkg/utils"
+
+	"github.com/skip2/go-qrcode"
+)
+
+var (
+	ErrInvalidMachineLink = errors.New("invalid machine link")
+	ErrMachineUnavailable = errors.New("machine is not available right now")
+)
+
+// CheckInService обслуживает QR-коды на машинах: выпускает подписанные
+// ссылки и по скану отмечает владельца брони или бронирует свободную машину.
+type CheckInService struct {
+	signer         *utils.LinkSigner
+	machineRepo    *repository.MachineRepository
+	bookingRepo    *repository.BookingRepository
+	machineService *MachineService
+	bookingService *BookingService
+	publicURL      string
+}
+
+func NewCheckInService(signer *utils.LinkSigner, machineRepo *repository.MachineRepository, bookingRepo *repository.BookingRepository, machineService *MachineService, bookingService *BookingService, publicURL string) *CheckInService {
+	return &CheckInService{
+		signer:         signer,
+		machineRepo:    machineRepo,
+		bookingRepo:    bookingRepo,
+		machineService: machineService,
+		bookingService: bookingService,
+		publicURL:      strings.TrimRight(publicURL, "/"),
+	}
+}
+
+// MachineURL возвращает ссылку, которая зашивается в QR-код машины.
+func (s *CheckInService) MachineURL(machineID int) string {
+	keyID, sig := s.signer.SignMachine(machineID)
+	q := url.Values{}
+	q.Set("m", strconv.Itoa(machineID))
+	q.Set("k", keyID)
+	q.Set("s", sig)
+	return s.publicURL + "/scan.html?" + q.Encode()
+}
+
+func (s *CheckInService) QRCode(ctx context.Context, machineID, size int) ([]byte, error) {
+	if _, err := s.machineService.GetByID(ctx, machineID); err != nil {
+		return nil, err
+	}
+	return qrcode.Encode(s.MachineURL(machineID), qrcode.Medium, size)
+}
+
+// RoomSheet готовит QR-коды всех активных машин комнаты для печати.
+func (s *CheckInService) RoomSheet(ctx context.Context, room string, size int) ([]models.MachineQR, error) {
+	machines, err := s.machineRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var sheet []models.MachineQR
+	for _, m := range machines {
+		if m.Room != room {
+			continue
+		}
+		link := s.MachineURL(m.ID)
+		png, err := qrcode.Encode(link, qrcode.Medium, size)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode qr for machine %d: %w", m.ID, err)
+		}
+		sheet = append(sheet, models.MachineQR{Machine: m, URL: link, PNG: png})
+	}
+	return sheet, nil
+}
+
+// Resolve проверяет подпись и говорит, что студент может сделать с машиной.
+func (s *CheckInService) Resolve(ctx context.Context, userID int, link *models.MachineLink) (*models.ScanResult, error) {
+	if !s.signer.VerifyMachine(link.MachineID, link.KeyID, link.Signature) {
+		return nil, ErrInvalidMachineLink
+	}
+
+	state, err := s.machineService.GetLiveByID(ctx, link.MachineID)
+	if err != nil {
+		return nil, err
+	}
+	if !state.IsActive {
+		return nil, ErrMachineNotFound
+	}
+
+	now := time.Now().In(utils.LaundryLocation())
+	result := &models.ScanResult{Machine: *state, Action: models.ScanActionUnavailable}
+
+	current, err := s.bookingRepo.GetCurrentForMachine(ctx, link.MachineID, now)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil && current.UserID == userID {
+		current.StartTime = utils.InLaundryLocation(current.StartTime)
+		current.EndTime = utils.InLaundryLocation(current.EndTime)
+		result.Action = models.ScanActionCheckIn
+		result.Booking = current
+		return result, nil
+	}
+
+	if state.Status == models.LiveStatusFree {
+		available, err := s.bookingRepo.CheckAvailability(ctx, link.MachineID, now, now.Add(bookingDuration))
+		if err != nil {
+			return nil, err
+		}
+		if available {
+			result.Action = models.ScanActionBook
+		}
+	}
+	return result, nil
+}
+
+// CheckIn выполняет действие, которое вернул бы Resolve: отмечает владельца
+// текущей брони или сразу бронирует свободную машину на ближайший час.
+func (s *CheckInService) CheckIn(ctx context.Context, userID int, link *models.MachineLink) (*models.ScanResult, error) {
+	result, err := s.Resolve(ctx, userID, link)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().In(utils.LaundryLocation())
+	switch result.Action {
+	case models.ScanActionCheckIn:
+		if err := s.bookingRepo.MarkCheckedIn(ctx, result.Booking.ID, now); err != nil {
+			return nil, err
+		}
+		log.Printf("📷 [CHECK-IN] User %d checked in to booking %d", userID, result.Booking.ID)
+	case models.ScanActionBook:
+		booking, err := s.bookingService.Create(ctx, userID, link.MachineID, now.Truncate(time.Minute))
+		if err != nil {
+			return nil, err
+		}
+		result.Booking = booking
+		log.Printf("📷 [CHECK-IN] User %d quick-booked machine %d (booking %d)", userID, link.MachineID, booking.ID)
+	default:
+		return nil, ErrMachineUnavailable
+	}
+
+	if state, err := s.machineService.GetLiveByID(ctx, link.MachineID); err == nil {
+		result.Machine = *state
+	}
+	return result, nil
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"netiwash/internal/models"
+	"netiwash/internal/repository"
+	"netiwash/pkg/utils"
+)
+
+var (
+	ErrDeviceNotFound         = errors.New("device not found")
+	ErrInvalidDeviceKey       = errors.New("invalid device key")
+	ErrDeviceMachineForbidden = errors.New("device is not bound to this machine")
+)
+
+type DeviceService struct {
+	repo             *repository.DeviceRepository
+	machineRepo      *repository.MachineRepository
+	machineService   *MachineService
+	telemetryService *TelemetryService
+	ticketService    *TicketService
+}
+
+func NewDeviceService(repo *repository.DeviceRepository, machineRepo *repository.MachineRepository, machineService *MachineService, telemetryService *TelemetryService, ticketService *TicketService) *DeviceService {
+	return &DeviceService{
+		repo:             repo,
+		machineRepo:      machineRepo,
+		machineService:   machineService,
+		telemetryService: telemetryService,
+		ticketService:    ticketService,
+	}
+}
+
+// Issue регистрирует устройство и выпускает для него ключ. Ключ в открытом
+// виде возвращается только здесь.
+func (s *DeviceService) Issue(ctx context.Context, actorID int, req *models.CreateDeviceRequest) (*models.CreateDeviceResponse, error) {
+	if req.MachineID != nil {
+		if _, err := s.machineService.GetByID(ctx, *req.MachineID); err != nil {
+			return nil, err
+		}
+	}
+
+	key, prefix, hash, err := utils.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	d := models.Device{
+		Name:      req.Name,
+		MachineID: req.MachineID,
+		KeyPrefix: prefix,
+		KeyHash:   hash,
+		CreatedBy: &actorID,
+	}
+	if err := s.repo.Create(ctx, &d); err != nil {
+		return nil, err
+	}
+
+	log.Printf("🔌 [DEVICE] Device %d (%s) issued by user %d", d.ID, d.Name, actorID)
+	return &models.CreateDeviceResponse{Device: d, APIKey: key}, nil
+}
+
+func (s *DeviceService) List(ctx context.Context) ([]models.Device, error) {
+	return s.repo.GetAll(ctx)
+}
+
+func (s *DeviceService) Revoke(ctx context.Context, id int) error {
+	revoked, err := s.repo.Revoke(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrDeviceNotFound
+	}
+	log.Printf("🔌 [DEVICE] Device %d revoked", id)
+	return nil
+}
+
+func (s *DeviceService) Authenticate(ctx context.Context, key string) (*models.Device, error) {
+	prefix, ok := utils.ParseAPIKeyPrefix(key)
+	if !ok {
+		return nil, ErrInvalidDeviceKey
+	}
+
+	d, err := s.repo.GetByKeyPrefix(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil || d.RevokedAt != nil || !utils.CheckAPIKey(key, d.KeyHash) {
+		return nil, ErrInvalidDeviceKey
+	}
+
+	if err := s.repo.Touch(ctx, d.ID); err != nil {
+		log.Printf("🔌 [DEVICE] Failed to update last_seen for device %d: %v", d.ID, err)
+	}
+	return d, nil
+}
+
+// HandleEvent сохраняет событие контроллера и применяет его к машине.
+func (s *DeviceService) HandleEvent(ctx context.Context, device *models.Device, req *models.DeviceEventRequest) (*models.DeviceEvent, error) {
+	if device.MachineID != nil && *device.MachineID != req.MachineID {
+		return nil, ErrDeviceMachineForbidden
+	}
+
+	m, err := s.machineService.GetByID(ctx, req.MachineID)
+	if err != nil {
+		return nil, err
+	}
+	if !m.IsActive {
+		return nil, ErrMachineNotFound
+	}
+
+	at := time.Now()
+	if req.OccurredAt != nil && !req.OccurredAt.After(at) {
+		at = *req.OccurredAt
+	}
+	at = at.In(utils.LaundryLocation())
+
+	event := &models.DeviceEvent{
+		DeviceID:   device.ID,
+		MachineID:  req.MachineID,
+		Type:       req.Type,
+		Data:       req.Data,
+		OccurredAt: at,
+	}
+	if err := s.repo.CreateEvent(ctx, event); err != nil {
+		return nil, err
+	}
+
+	switch req.Type {
+	case models.DeviceEventCycleStart:
+		s.telemetryService.CycleStarted(ctx, m.ID, at)
+	case models.DeviceEventCycleEnd:
+		startedAt := at
+		if m.IsRunning && m.LastCycleStartedAt != nil {
+			startedAt = utils.InLaundryLocation(*m.LastCycleStartedAt)
+		}
+		s.telemetryService.CycleEnded(ctx, m.ID, startedAt, at)
+	case models.DeviceEventDoorOpen:
+		if err := s.machineRepo.SetDoorOpened(ctx, m.ID, at); err != nil {
+			log.Printf("🔌 [DEVICE] %v", err)
+		}
+		s.machineService.PublishState(ctx, m.ID)
+	case models.DeviceEventError:
+		if _, err := s.ticketService.ReportDeviceError(ctx, m.ID, describeDeviceError(device, req.Data)); err != nil {
+			log.Printf("🔌 [DEVICE] Failed to open ticket for machine %d: %v", m.ID, err)
+		}
+	}
+
+	return event, nil
+}
+
+func (s *DeviceService) GetEvents(ctx context.Context, machineID int) ([]models.DeviceEvent, error) {
+	since := time.Now().In(utils.LaundryLocation()).AddDate(0, 0, -7)
+	return s.repo.GetEvents(ctx, machineID, since)
+}
+
+func describeDeviceError(device *models.Device, data json.RawMessage) string {
+	var payload struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(data, &payload)
+
+	desc := fmt.Sprintf("Ошибка контроллера «%s»", device.Name)
+	if payload.Code != "" {
+		desc += " " + payload.Code
+	}
+	if payload.Message != "" {
+		desc += ": " + payload.Message
+	}
+	return desc
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+
+	"netiwash/internal/models"
+	"netiwash/pkg/utils"
+)
+
+// EventPublisher принимает доменные события; в приложении это шина
+// eventbus, на которую подписаны realtime и уведомления.
+type EventPublisher interface {
+	Publish(ctx context.Context, e models.Event)
+}
+
+// publishBooking рассылает публичное событие о слоте и личное — владельцу.
+// cancel заполняется, когда бронь отменил не владелец.
+func publishBooking(ctx context.Context, events EventPublisher, eventType string, b *models.Booking, status string, cancel *models.BookingCancellation) {
+	events.Publish(ctx, models.Event{
+		Type: eventType,
+		Data: models.BookingSlotEvent{
+			BookingID: b.ID,
+			MachineID: b.MachineID,
+			StartTime: utils.InLaundryLocation(b.StartTime),
+			EndTime:   utils.InLaundryLocation(b.EndTime),
+		},
+	})
+
+	userID := b.UserID
+	events.Publish(ctx, models.Event{
+		Type:   models.EventBookingStatus,
+		UserID: &userID,
+		Data: models.BookingStatusEvent{
+			BookingID:    b.ID,
+			MachineID:    b.MachineID,
+			Event:        eventType,
+			StartTime:    utils.InLaundryLocation(b.StartTime),
+			EndTime:      utils.InLaundryLocation(b.EndTime),
+			Status:       status,
+			Cancellation: cancel,
+		},
+	})
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"netiwash/internal/models"
+	"netiwash/internal/repository"
+	"netiwash/pkg/storage"
+)
+
+const maxMachinePhotos = 10
+
+var ErrTooManyPhotos = fmt.Errorf("a machine can have at most %d photos", maxMachinePhotos)
+
+func machinePhotoURL(p models.MachinePhoto) string {
+	return fmt.Sprintf("/api/machines/%d/photos/%d", p.MachineID, p.ID)
+}
+
+func (s *MachineService) AddPhoto(ctx context.Context, machineID int, data []byte) (*models.MachinePhoto, error) {
+	m, err := s.GetByID(ctx, machineID)
+	if err != nil {
+		return nil, err
+	}
+	if !m.IsActive {
+		return nil, ErrMachineNotFound
+	}
+
+	// Предварительная проверка, чтобы не загружать файл в хранилище зря;
+	// окончательно лимит проверяется при вставке.
+	count, err := s.repo.CountPhotos(ctx, machineID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxMachinePhotos {
+		return nil, ErrTooManyPhotos
+	}
+
+	key, contentType, err := storePhoto(ctx, s.storage, "machines", data)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &models.MachinePhoto{MachineID: machineID, StorageKey: key, ContentType: contentType}
+	if err := s.repo.AddPhoto(ctx, p, maxMachinePhotos); err != nil {
+		_ = s.storage.Delete(ctx, key)
+		if errors.Is(err, repository.ErrPhotoLimit) {
+			return nil, ErrTooManyPhotos
+		}
+		return nil, err
+	}
+	p.URL = machinePhotoURL(*p)
+
+	log.Printf("📷 [MACHINE] Photo %d added to machine %d", p.ID, machineID)
+	s.PublishState(ctx, machineID)
+	return p, nil
+}
+
+// OpenPhoto открывает фото машины из хранилища; закрыть его должен вызывающий.
+func (s *MachineService) OpenPhoto(ctx context.Context, machineID, photoID int) (io.ReadCloser, string, error) {
+	p, err := s.repo.GetPhoto(ctx, machineID, photoID)
+	if err != nil {
+		return nil, "", err
+	}
+	if p == nil {
+		return nil, "", ErrMachineNotFound
+	}
+
+	f, err := s.storage.Get(ctx, p.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, "", ErrMachineNotFound
+		}
+		return nil, "", err
+	}
+	return f, p.ContentType, nil
+}
+
+func (s *MachineService) DeletePhoto(ctx context.Context, machineID, photoID int) error {
+	p, err := s.repo.GetPhoto(ctx, machineID, photoID)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return ErrMachineNotFound
+	}
+
+	if err := s.repo.DeletePhoto(ctx, machineID, photoID); err != nil {
+		return err
+	}
+	if err := s.storage.Delete(ctx, p.StorageKey); err != nil {
+		log.Printf("📷 [MACHINE] Failed to delete %s from storage: %v", p.StorageKey, err)
+	}
+	s.PublishState(ctx, machineID)
+	return nil
+}
+
+// attachPhotos заполняет Photos у машин; пустой список вместо nil, чтобы
+// фронтенд получал [] в JSON.
+func (s *MachineService) attachPhotos(ctx context.Context, machines []models.Machine) error {
+	photos, err := s.repo.GetPhotos(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range machines {
+		list := photos[machines[i].ID]
+		for j := range list {
+			list[j].URL = machinePhotoURL(list[j])
+		}
+		if list == nil {
+			list = []models.MachinePhoto{}
+		}
+		machines[i].Photos = list
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"netiwash/internal/models"
+	"netiwash/internal/repository"
+	"netiwash/pkg/storage"
+	"netiwash/pkg/utils"
+)
+
+var (
+	ErrMachineNotFound         = errors.New("machine not found")
+	ErrInvalidStatusTransition = errors.New("invalid machine status transition")
+	ErrStatusReasonRequired    = errors.New("reason is required for this status")
+)
+
+// machineStatusTransitions — допустимые переходы статуса машины. Из ремонта
+// машина возвращается только в free.
+var machineStatusTransitions = map[string][]string{
+	models.MachineStatusFree:   {models.MachineStatusBusy, models.MachineStatusRepair},
+	models.MachineStatusBusy:   {models.MachineStatusFree, models.MachineStatusRepair},
+	models.MachineStatusRepair: {models.MachineStatusFree},
+}
+
+func canTransition(from, to string) bool {
+	for _, s := range machineStatusTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+type MachineService struct {
+	repo        *repository.MachineRepository
+	bookingRepo *repository.BookingRepository
+	storage     storage.Storage
+	events      EventPublisher
+}
+
+func NewMachineService(repo *repository.MachineRepository, bookingRepo *repository.BookingRepository, storage storage.Storage, events EventPublisher) *MachineService {
+	return &MachineService{
+		repo:        repo,
+		bookingRepo: bookingRepo,
+		storage:     storage,
+		events:      events,
+	}
+}
+
+// PublishState рассылает клиентам текущий живой статус машины. Вызывается
+// после всего, что может его поменять: смены статуса, телеметрии, броней.
+func (s *MachineService) PublishState(ctx context.Context, id int) {
+	state, err := s.GetLiveByID(ctx, id)
+	if err != nil {
+		log.Printf("🧺 [MACHINE] Failed to publish state of machine %d: %v", id, err)
+		return
+	}
+	s.events.Publish(ctx, models.Event{Type: models.EventMachineUpdated, Data: state})
+}
+
+// MarkPickedUp снимает с машины ожидание владельца, как если бы открыли
+// дверцу.
+func (s *MachineService) MarkPickedUp(ctx context.Context, id int) error {
+	if err := s.repo.SetDoorOpened(ctx, id, time.Now().In(utils.LaundryLocation())); err != nil {
+		return err
+	}
+	s.PublishState(ctx, id)
+	return nil
+}
+
+func (s *MachineService) GetByID(ctx context.Context, id int) (*models.Machine, error) {
+	m, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, ErrMachineNotFound
+	}
+	return m, nil
+}
+
+func (s *MachineService) Create(ctx context.Context, actorID int, req *models.CreateMachineRequest) (*models.Machine, error) {
+	m := &models.Machine{
+		Name:     req.Name,
+		Type:     req.Type,
+		Status:   req.Status,
+		Room:     req.Room,
+		Building: req.Building,
+		IsActive: true,
+
+		CapacityKg: req.CapacityKg,
+		Brand:      req.Brand,
+		Floor:      req.Floor,
+		Position:   req.Position,
+		Notes:      req.Notes,
+		Attributes: req.Attributes,
+	}
+	if m.Type == "" {
+		m.Type = "washing"
+	}
+	if m.Status == "" {
+		m.Status = models.MachineStatusFree
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if m.Status == models.MachineStatusRepair && reason == "" {
+		return nil, ErrStatusReasonRequired
+	}
+
+	initial := &models.MachineStatusChange{
+		Reason:  reason,
+		ActorID: &actorID,
+		Source:  models.StatusSourceAdmin,
+	}
+	if err := s.repo.Create(ctx, m, initial); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (s *MachineService) Update(ctx context.Context, id, actorID int, req *models.UpdateMachineRequest) (*models.Machine, error) {
+	m, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !m.IsActive {
+		return nil, ErrMachineNotFound
+	}
+
+	// Смена статуса проверяется до записи и пишется вместе с остальными
+	// полями, чтобы отказ не оставил машину обновлённой наполовину.
+	var change *models.MachineStatusChange
+	if req.Status != nil && *req.Status != m.Status {
+		reason := strings.TrimSpace(req.Reason)
+		if err := validateStatusChange(m.Status, *req.Status, reason); err != nil {
+			return nil, err
+		}
+		change = &models.MachineStatusChange{
+			MachineID:  id,
+			FromStatus: m.Status,
+			ToStatus:   *req.Status,
+			Reason:     reason,
+			ActorID:    &actorID,
+			Source:     models.StatusSourceAdmin,
+		}
+	}
+
+	if req.Name != nil {
+		m.Name = *req.Name
+	}
+	if req.Type != nil {
+		m.Type = *req.Type
+	}
+	if req.Room != nil {
+		m.Room = *req.Room
+	}
+	if req.Building != nil {
+		m.Building = *req.Building
+	}
+	if req.CapacityKg != nil {
+		m.CapacityKg = req.CapacityKg
+	}
+	if req.Brand != nil {
+		m.Brand = *req.Brand
+	}
+	if req.Floor != nil {
+		m.Floor = req.Floor
+	}
+	if req.Position != nil {
+		m.Position = *req.Position
+	}
+	if req.Notes != nil {
+		m.Notes = *req.Notes
+	}
+	if req.Attributes != nil {
+		m.Attributes = req.Attributes
+	}
+
+	if err := s.repo.Update(ctx, m, change); err != nil {
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return nil, ErrInvalidStatusTransition
+		}
+		return nil, err
+	}
+
+	if change != nil {
+		m.Status = change.ToStatus
+		log.Printf("🧺 [MACHINE] Machine %d: %s -> %s (%s)", id, change.FromStatus, change.ToStatus, change.Source)
+	}
+	s.PublishState(ctx, id)
+	return m, nil
+}
+
+// ChangeStatus переводит машину в новый статус с проверкой допустимости
+// перехода и записью в историю. actorID равен nil для системных изменений.
+func (s *MachineService) ChangeStatus(ctx context.Context, id int, toStatus, reason string, actorID *int, source string) (*models.MachineStatusChange, error) {
+	m, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !m.IsActive {
+		return nil, ErrMachineNotFound
+	}
+
+	reason = strings.TrimSpace(reason)
+	if err := validateStatusChange(m.Status, toStatus, reason); err != nil {
+		return nil, err
+	}
+
+	change := &models.MachineStatusChange{
+		MachineID:  id,
+		FromStatus: m.Status,
+		ToStatus:   toStatus,
+		Reason:     reason,
+		ActorID:    actorID,
+		Source:     source,
+	}
+	if err := s.repo.ChangeStatus(ctx, change); err != nil {
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return nil, ErrInvalidStatusTransition
+		}
+		return nil, err
+	}
+
+	log.Printf("🧺 [MACHINE] Machine %d: %s -> %s (%s)", id, change.FromStatus, change.ToStatus, source)
+	s.PublishState(ctx, id)
+	return change, nil
+}
+
+func (s *MachineService) GetStatusHistory(ctx context.Context, id int) ([]models.MachineStatusChange, error) {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.GetStatusHistory(ctx, id, 100)
+}
+
+func validateStatusChange(from, to, reason string) error {
+	if !canTransition(from, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, from, to)
+	}
+	if to == models.MachineStatusRepair && strings.TrimSpace(reason) == "" {
+		return ErrStatusReasonRequired
+	}
+	return nil
+}
+
+// Decommission выводит машину из эксплуатации без удаления. Будущие брони
+// отменяются, их владельцы получают уведомление.
+func (s *MachineService) Decommission(ctx context.Context, id int) ([]models.Booking, error) {
+	m, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !m.IsActive {
+		return nil, ErrMachineNotFound
+	}
+
+	cancelled, err := s.repo.Decommission(ctx, id, time.Now().In(utils.LaundryLocation()))
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("🧺 [MACHINE] Machine %d decommissioned, %d bookings cancelled", id, len(cancelled))
+	s.events.Publish(ctx, models.Event{Type: models.EventMachineRemoved, Data: map[string]int{"machine_id": id}})
+
+	cancel := &models.BookingCancellation{
+		Cause:  models.CancelCauseMachineRemoved,
+		Reason: fmt.Sprintf("%s выведена из эксплуатации", m.Name),
+	}
+	for _, b := range cancelled {
+		publishBooking(ctx, s.events, models.EventBookingCancelled, &b, "cancelled", cancel)
+	}
+
+	return cancelled, nil
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"netiwash/internal/models"
+	"netiwash/pkg/utils"
+)
+
+const (
+	// pickupWindow — сколько после конца цикла машина считается ожидающей,
+	// пока бельё не заберут. Без датчика двери статус снимается по истечении окна.
+	pickupWindow = 30 * time.Minute
+	// liveStatusHorizon — насколько вперёд смотрим брони, чтобы посчитать next_free_at.
+	liveStatusHorizon = 48 * time.Hour
+)
+
+// GetLive возвращает активные машины с живым статусом.
+func (s *MachineService) GetLive(ctx context.Context) ([]models.MachineState, error) {
+	machines, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachPhotos(ctx, machines); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().In(utils.LaundryLocation())
+	bookings, err := s.bookingRepo.GetActiveInRange(ctx, now, now.Add(liveStatusHorizon))
+	if err != nil {
+		return nil, err
+	}
+
+	byMachine := make(map[int][]models.Booking)
+	for _, b := range bookings {
+		b.StartTime = utils.InLaundryLocation(b.StartTime)
+		b.EndTime = utils.InLaundryLocation(b.EndTime)
+		byMachine[b.MachineID] = append(byMachine[b.MachineID], b)
+	}
+
+	states := make([]models.MachineState, 0, len(machines))
+	for _, m := range machines {
+		states = append(states, liveState(m, byMachine[m.ID], now))
+	}
+	return states, nil
+}
+
+// GetLiveByID возвращает живой статус одной машины.
+func (s *MachineService) GetLiveByID(ctx context.Context, id int) (*models.MachineState, error) {
+	m, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	one := []models.Machine{*m}
+	if err := s.attachPhotos(ctx, one); err != nil {
+		return nil, err
+	}
+	m = &one[0]
+
+	now := time.Now().In(utils.LaundryLocation())
+	bookings, err := s.bookingRepo.GetActiveInRange(ctx, now, now.Add(liveStatusHorizon))
+	if err != nil {
+		return nil, err
+	}
+
+	var own []models.Booking
+	for _, b := range bookings {
+		if b.MachineID == id {
+			b.StartTime = utils.InLaundryLocation(b.StartTime)
+			b.EndTime = utils.InLaundryLocation(b.EndTime)
+			own = append(own, b)
+		}
+	}
+
+	state := liveState(*m, own, now)
+	return &state, nil
+}
+
+// liveState вычисляет статус машины на момент now. bookings — активные брони
+// машины, отсортированные по началу. Приоритет: ремонт, идёт стирка, бельё
+// ждёт владельца, слот забронирован, свободна.
+func liveState(m models.Machine, bookings []models.Booking, now time.Time) models.MachineState {
+	m.LastCycleStartedAt = laundryTimePtr(m.LastCycleStartedAt)
+	m.LastCycleEndedAt = laundryTimePtr(m.LastCycleEndedAt)
+	m.LastDoorOpenedAt = laundryTimePtr(m.LastDoorOpenedAt)
+
+	state := models.MachineState{
+		Machine:           m,
+		Status:            models.LiveStatusFree,
+		OperationalStatus: m.Status,
+	}
+
+	var current *models.Booking
+	for i := range bookings {
+		if !bookings[i].StartTime.After(now) && bookings[i].EndTime.After(now) {
+			current = &bookings[i]
+			break
+		}
+	}
+	if current != nil {
+		endsAt := current.EndTime
+		state.CurrentBookingEndsAt = &endsAt
+	}
+
+	// busyUntil — момент, до которого машина точно занята без учёта броней.
+	busyUntil := now
+	switch {
+	case m.Status == models.MachineStatusRepair:
+		state.Status = models.LiveStatusRepair
+		return state
+	case m.IsRunning:
+		state.Status = models.LiveStatusInUse
+		if m.LastCycleStartedAt != nil {
+			busyUntil = laterOf(busyUntil, m.LastCycleStartedAt.Add(bookingDuration))
+		}
+	case awaitingPickup(m, now):
+		state.Status = models.LiveStatusAwaitingPickup
+		busyUntil = laterOf(busyUntil, m.LastCycleEndedAt.Add(pickupWindow))
+	case current != nil:
+		state.Status = models.LiveStatusReserved
+	}
+
+	if state.Status == models.LiveStatusFree {
+		return state
+	}
+
+	// Стыкуем брони, идущие подряд, чтобы показать, когда машина реально освободится.
+	freeAt := busyUntil
+	for _, b := range bookings {
+		if b.StartTime.After(freeAt) {
+			break
+		}
+		freeAt = laterOf(freeAt, b.EndTime)
+	}
+	state.NextFreeAt = &freeAt
+	return state
+}
+
+// awaitingPickup — цикл закончился недавно, а дверцу после этого не открывали.
+func awaitingPickup(m models.Machine, now time.Time) bool {
+	if m.LastCycleEndedAt == nil || now.Sub(*m.LastCycleEndedAt) >= pickupWindow {
+		return false
+	}
+	return m.LastDoorOpenedAt == nil || m.LastDoorOpenedAt.Before(*m.LastCycleEndedAt)
+}
+
+func laundryTimePtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	lt := utils.InLaundryLocation(*t)
+	return &lt
+}
+
+func laterOf(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
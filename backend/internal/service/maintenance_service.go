@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"netiwash/internal/models"
+	"netiwash/internal/repository"
+	"netiwash/pkg/utils"
+)
+
+const (
+	maintenanceCheckInterval = 10 * time.Minute
+	// maintenanceWindowHorizon — как далеко вперёд ищем свободное окно под обслуживание.
+	maintenanceWindowHorizon = 7 * 24 * time.Hour
+)
+
+var (
+	ErrMaintenanceNotFound  = errors.New("maintenance plan or task not found")
+	ErrMaintenancePlanEmpty = errors.New("plan needs every_cycles or every_days")
+)
+
+// MaintenanceService следит за регламентным обслуживанием: по счётчику
+// стирок и по календарю поднимает задачи для админов и при необходимости
+// резервирует окно, в которое машину нельзя забронировать. Брони,
+// оказавшиеся в окне, отменяются, их владельцы получают уведомление.
+type MaintenanceService struct {
+	repo        *repository.MaintenanceRepository
+	machineRepo *repository.MachineRepository
+	bookingRepo *repository.BookingRepository
+	events      EventPublisher
+}
+
+func NewMaintenanceService(repo *repository.MaintenanceRepository, machineRepo *repository.MachineRepository, bookingRepo *repository.BookingRepository, events EventPublisher) *MaintenanceService {
+	return &MaintenanceService{
+		repo:        repo,
+		machineRepo: machineRepo,
+		bookingRepo: bookingRepo,
+		events:      events,
+	}
+}
+
+func (s *MaintenanceService) ListPlans(ctx context.Context) ([]models.MaintenancePlan, error) {
+	return s.repo.GetPlans(ctx, false)
+}
+
+func (s *MaintenanceService) CreatePlan(ctx context.Context, req *models.MaintenancePlanRequest) (*models.MaintenancePlan, error) {
+	if req.EveryCycles == nil && req.EveryDays == nil {
+		return nil, ErrMaintenancePlanEmpty
+	}
+
+	p := planFromRequest(req)
+	p.CreatedAt = time.Now().In(utils.LaundryLocation())
+	if err := s.repo.CreatePlan(ctx, p); err != nil {
+		return nil, err
+	}
+
+	log.Printf("🧰 [MAINTENANCE] Plan %d (%s) created for %s machines", p.ID, p.Name, p.MachineType)
+	return p, nil
+}
+
+func (s *MaintenanceService) UpdatePlan(ctx context.Context, id int, req *models.MaintenancePlanRequest) (*models.MaintenancePlan, error) {
+	if req.EveryCycles == nil && req.EveryDays == nil {
+		return nil, ErrMaintenancePlanEmpty
+	}
+
+	p := planFromRequest(req)
+	p.ID = id
+	updated, err := s.repo.UpdatePlan(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrMaintenanceNotFound
+	}
+	return updated, nil
+}
+
+func (s *MaintenanceService) DeactivatePlan(ctx context.Context, id int) error {
+	ok, err := s.repo.DeactivatePlan(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrMaintenanceNotFound
+	}
+	return nil
+}
+
+func (s *MaintenanceService) ListTasks(ctx context.Context, status string) ([]models.MaintenanceTask, error) {
+	return s.repo.GetTasks(ctx, status)
+}
+
+func (s *MaintenanceService) CompleteTask(ctx context.Context, id, actorID int) error {
+	ok, err := s.repo.CompleteTask(ctx, id, actorID, time.Now().In(utils.LaundryLocation()))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrMaintenanceNotFound
+	}
+	log.Printf("🧰 [MAINTENANCE] Task %d completed by user %d", id, actorID)
+	return nil
+}
+
+func (s *MaintenanceService) RunWorker(ctx context.Context) {
+	ticker := time.NewTicker(maintenanceCheckInterval)
+	defer ticker.Stop()
+	log.Println("🧰 [MAINTENANCE] Maintenance worker started")
+	s.CheckDue(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.CheckDue(ctx)
+		}
+	}
+}
+
+// CheckDue поднимает задачи по всем планам, срок которых наступил. Пока
+// по паре план–машина есть открытая задача, новая не создаётся.
+func (s *MaintenanceService) CheckDue(ctx context.Context) {
+	plans, err := s.repo.GetPlans(ctx, true)
+	if err != nil {
+		log.Printf("🧰 [MAINTENANCE] %v", err)
+		return
+	}
+	if len(plans) == 0 {
+		return
+	}
+
+	machines, err := s.machineRepo.GetAll(ctx)
+	if err != nil {
+		log.Printf("🧰 [MAINTENANCE] %v", err)
+		return
+	}
+
+	now := time.Now().In(utils.LaundryLocation())
+	for _, p := range plans {
+		for _, m := range machines {
+			if m.Type != p.MachineType {
+				continue
+			}
+			if err := s.checkMachine(ctx, p, m, now); err != nil {
+				log.Printf("🧰 [MAINTENANCE] Plan %d, machine %d: %v", p.ID, m.ID, err)
+			}
+		}
+	}
+}
+
+func (s *MaintenanceService) checkMachine(ctx context.Context, p models.MaintenancePlan, m models.Machine, now time.Time) error {
+	open, err := s.repo.HasOpenTask(ctx, p.ID, m.ID)
+	if err != nil || open {
+		return err
+	}
+
+	doneCycles, doneAt, ok, err := s.repo.LastDone(ctx, p.ID, m.ID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		doneAt = p.CreatedAt
+	}
+	doneAt = utils.InLaundryLocation(doneAt)
+
+	due := (p.EveryCycles != nil && m.CycleCount-doneCycles >= *p.EveryCycles) ||
+		(p.EveryDays != nil && !now.Before(doneAt.AddDate(0, 0, *p.EveryDays)))
+	if !due {
+		return nil
+	}
+
+	task := &models.MaintenanceTask{
+		PlanID:     p.ID,
+		MachineID:  m.ID,
+		Status:     models.MaintenanceTaskOpen,
+		CycleCount: m.CycleCount,
+	}
+	if p.WindowMinutes > 0 {
+		start, ok, err := s.findWindow(ctx, m.ID, time.Duration(p.WindowMinutes)*time.Minute, now)
+		if err != nil {
+			return err
+		}
+		if ok {
+			end := start.Add(time.Duration(p.WindowMinutes) * time.Minute)
+			task.WindowStart = &start
+			task.WindowEnd = &end
+		}
+	}
+
+	cancelled, err := s.repo.CreateTask(ctx, task)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("🧰 [MAINTENANCE] Task %d raised: %s for %s (%d cycles)", task.ID, p.Name, m.Name, m.CycleCount)
+
+	if len(cancelled) > 0 {
+		log.Printf("🧰 [MAINTENANCE] Task %d: %d bookings in the window cancelled", task.ID, len(cancelled))
+		cancel := &models.BookingCancellation{
+			Cause:  models.CancelCauseMaintenance,
+			Reason: fmt.Sprintf("на это время запланировано обслуживание «%s»", p.Name),
+		}
+		for _, b := range cancelled {
+			publishBooking(ctx, s.events, models.EventBookingCancelled, &b, "cancelled", cancel)
+		}
+	}
+	return nil
+}
+
+// findWindow ищет ближайший свободный интервал, начиная со следующего часа.
+func (s *MaintenanceService) findWindow(ctx context.Context, machineID int, length time.Duration, now time.Time) (time.Time, bool, error) {
+	start := now.Truncate(time.Hour).Add(time.Hour)
+	for ; start.Before(now.Add(maintenanceWindowHorizon)); start = start.Add(time.Hour) {
+		available, err := s.bookingRepo.CheckAvailability(ctx, machineID, start, start.Add(length))
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		if available {
+			return start, true, nil
+		}
+	}
+	return time.Time{}, false, nil
+}
+
+func planFromRequest(req *models.MaintenancePlanRequest) *models.MaintenancePlan {
+	return &models.MaintenancePlan{
+		MachineType:   req.MachineType,
+		Name:          req.Name,
+		EveryCycles:   req.EveryCycles,
+		EveryDays:     req.EveryDays,
+		WindowMinutes: req.WindowMinutes,
+	}
+}
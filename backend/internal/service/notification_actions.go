@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"netiwash/internal/models"
+	"netiwash/pkg/utils"
+)
+
+var ErrInvalidActionToken = errors.New("invalid or expired action token")
+
+// notificationActionTTL — сколько действует кнопка в уведомлении.
+const notificationActionTTL = 24 * time.Hour
+
+// EnableActions подключает кнопки в пушах. Без него уведомления уходят без
+// кнопок, а PerformAction отвергает любые токены.
+func (s *NotificationService) EnableActions(signer *utils.LinkSigner, bookings *BookingService) {
+	s.signer = signer
+	s.bookings = bookings
+}
+
+func actionTokenData(action string, bookingID, userID int, expires int64) string {
+	return fmt.Sprintf("notification-action:%s:%d:%d:%d", action, bookingID, userID, expires)
+}
+
+// actionToken выдаёт токен кнопки: "<action>.<booking>.<user>.<expires>.<key>.<signature>".
+func (s *NotificationService) actionToken(action string, bookingID, userID int, now time.Time) string {
+	expires := now.Add(notificationActionTTL).Unix()
+	keyID, sig := s.signer.Sign(actionTokenData(action, bookingID, userID, expires))
+	return strings.Join([]string{action, strconv.Itoa(bookingID), strconv.Itoa(userID), strconv.FormatInt(expires, 10), keyID, sig}, ".")
+}
+
+// PerformAction выполняет действие по токену из кнопки уведомления от
+// имени пользователя, которому уведомление было отправлено.
+func (s *NotificationService) PerformAction(ctx context.Context, token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if s.signer == nil || len(parts) < 6 {
+		return "", ErrInvalidActionToken
+	}
+	action := parts[0]
+	bookingID, err1 := strconv.Atoi(parts[1])
+	userID, err2 := strconv.Atoi(parts[2])
+	expires, err3 := strconv.ParseInt(parts[3], 10, 64)
+	keyID := strings.Join(parts[4:len(parts)-1], ".")
+	sig := parts[len(parts)-1]
+	if err1 != nil || err2 != nil || err3 != nil || time.Now().Unix() > expires ||
+		!s.signer.Verify(actionTokenData(action, bookingID, userID, expires), keyID, sig) {
+		return "", ErrInvalidActionToken
+	}
+
+	switch action {
+	case models.NotificationActionExtend:
+		_, err := s.bookings.Extend(ctx, bookingID, userID)
+		return action, err
+	case models.NotificationActionPickedUp:
+		return action, s.bookings.MarkPickedUp(ctx, bookingID, userID)
+	default:
+		return "", ErrInvalidActionToken
+	}
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+
+	"netiwash/internal/repository"
+	"netiwash/pkg/utils"
+)
+
+// NotificationChannel доставляет текст уведомления пользователю по одному
+// каналу (пуш, почта, ...). Каналы регистрируются в NotificationService
+// под именем, которое пользователь указывает в настройках.
+type NotificationChannel interface {
+	Send(ctx context.Context, userID int, message string) error
+}
+
+// EmailChannel отправляет уведомление на почту пользователя. Письма уходят
+// только на подтверждённые адреса.
+type EmailChannel struct {
+	users     *repository.UserRepository
+	prefsRepo *repository.NotificationPreferencesRepository
+	email     *utils.EmailService
+}
+
+func NewEmailChannel(users *repository.UserRepository, prefsRepo *repository.NotificationPreferencesRepository, email *utils.EmailService) *EmailChannel {
+	return &EmailChannel{users: users, prefsRepo: prefsRepo, email: email}
+}
+
+func (c *EmailChannel) Send(ctx context.Context, userID int, message string) error {
+	user, err := c.users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.EmailVerified {
+		return nil
+	}
+	locale := userLocale(ctx, c.prefsRepo, userID)
+	return c.email.SendTemplate(user.Email, "notification", locale, map[string]any{"Message": message})
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"netiwash/internal/models"
+	"netiwash/pkg/utils"
+)
+
+var (
+	ErrDeliveryNotFound      = errors.New("delivery not found")
+	ErrInvalidDeliveryStatus = errors.New("status must be failed or dead")
+)
+
+// errDeliveryPermanent оборачивает ошибки, после которых повторять доставку
+// бессмысленно: подписка удалена, канал не настроен, push-сервис отверг запрос.
+var errDeliveryPermanent = errors.New("permanent delivery failure")
+
+const (
+	deliveryMaxAttempts = 6
+	deliveryBaseBackoff = 30 * time.Second
+	deliveryMaxBackoff  = time.Hour
+	// deliveryLease — через сколько повторить доставку, попытку которой
+	// начали, но не завершили (процесс упал посреди отправки).
+	deliveryLease      = 5 * time.Minute
+	deliveryBatchSize  = 100
+	deliveryRetention  = 30 * 24 * time.Hour
+	deliveryListLimit  = 200
+	deliveryErrorLimit = 500
+)
+
+// deliveryBackoff — пауза перед следующей попыткой после attempts неудачных:
+// 30с, 1м, 2м, 4м, ... но не больше часа.
+func deliveryBackoff(attempts int) time.Duration {
+	d := deliveryBaseBackoff
+	for i := 1; i < attempts && d < deliveryMaxBackoff; i++ {
+		d *= 2
+	}
+	if d > deliveryMaxBackoff {
+		d = deliveryMaxBackoff
+	}
+	return d
+}
+
+// enqueue записывает в журнал по доставке на каждый канал, для пуша — на
+// каждую подписку пользователя. Каналы, которые не подключены (например,
+// выключенный Telegram-бот), пропускаются.
+// Пуш получает payload целиком, остальные каналы — только текст.
+// Если deferUntil не nil, доставки по тихим каналам получают этот срок и
+// уходят через воркер. Возвращаются доставки, которые нужно отправить сразу.
+func (s *NotificationService) enqueue(ctx context.Context, userID int, kind string, payload *models.PushPayload, channels []string, deferUntil *time.Time) ([]models.NotificationDelivery, error) {
+	var deliveries []models.NotificationDelivery
+	for _, name := range channels {
+		var next *time.Time
+		if quietChannels[name] {
+			next = deferUntil
+		}
+		if name == models.ChannelWebPush {
+			subs, err := s.repo.GetSubscriptionsByUserID(ctx, userID)
+			if err != nil {
+				return nil, err
+			}
+			for _, sub := range subs {
+				id := sub.ID
+				deliveries = append(deliveries, models.NotificationDelivery{
+					UserID: userID, Kind: kind, Channel: name, SubscriptionID: &id, Message: payload.Body, Payload: payload, NextAttemptAt: next,
+				})
+			}
+			continue
+		}
+		if _, ok := s.channels[name]; !ok {
+			continue
+		}
+		deliveries = append(deliveries, models.NotificationDelivery{
+			UserID: userID, Kind: kind, Channel: name, Message: payload.Body, NextAttemptAt: next,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil, nil
+	}
+
+	deferred := make([]bool, len(deliveries))
+	for i := range deliveries {
+		deferred[i] = deliveries[i].NextAttemptAt != nil
+	}
+
+	now := time.Now().In(utils.LaundryLocation())
+	if err := s.deliveryRepo.Create(ctx, deliveries, now, now.Add(deliveryLease)); err != nil {
+		return nil, err
+	}
+
+	immediate := deliveries[:0]
+	for i := range deliveries {
+		if !deferred[i] {
+			immediate = append(immediate, deliveries[i])
+		}
+	}
+	if n := len(deliveries) - len(immediate); n > 0 {
+		log.Printf("[PUSH] %d deliveries to user %d deferred until %s (quiet hours)", n, userID, deferUntil.Format("15:04"))
+	}
+	return immediate, nil
+}
+
+// attempt делает одну попытку доставки.
+func (s *NotificationService) attempt(ctx context.Context, d *models.NotificationDelivery) error {
+	if d.Channel == models.ChannelWebPush {
+		if d.SubscriptionID == nil {
+			return fmt.Errorf("%w: subscription removed", errDeliveryPermanent)
+		}
+		sub, err := s.repo.GetSubscriptionByID(ctx, *d.SubscriptionID)
+		if err != nil {
+			return err
+		}
+		if sub == nil {
+			return fmt.Errorf("%w: subscription removed", errDeliveryPermanent)
+		}
+		if d.Payload == nil {
+			return s.sendToSubscription(ctx, *sub, []byte(d.Message))
+		}
+		body, err := json.Marshal(d.Payload)
+		if err != nil {
+			return fmt.Errorf("%w: %v", errDeliveryPermanent, err)
+		}
+		return s.sendToSubscription(ctx, *sub, body)
+	}
+
+	ch, ok := s.channels[d.Channel]
+	if !ok {
+		return fmt.Errorf("%w: channel %s is not configured", errDeliveryPermanent, d.Channel)
+	}
+	return ch.Send(ctx, d.UserID, d.Message)
+}
+
+// deliver пробует доставить и записывает результат: sent, failed со сроком
+// следующей попытки или dead, если попытки кончились.
+func (s *NotificationService) deliver(ctx context.Context, d *models.NotificationDelivery) {
+	err := s.attempt(ctx, d)
+	now := time.Now().In(utils.LaundryLocation())
+	if err == nil {
+		if err := s.deliveryRepo.MarkSent(ctx, d.ID, now); err != nil {
+			log.Printf("[PUSH] %v", err)
+		}
+		return
+	}
+
+	attempts := d.Attempts + 1
+	status := models.DeliveryStatusFailed
+	var next *time.Time
+	if errors.Is(err, errDeliveryPermanent) || attempts >= deliveryMaxAttempts {
+		status = models.DeliveryStatusDead
+		log.Printf("[PUSH] Delivery %d (%s) to user %d dead after %d attempts: %v", d.ID, d.Channel, d.UserID, attempts, err)
+	} else {
+		at := now.Add(deliveryBackoff(attempts))
+		next = &at
+		log.Printf("[PUSH] Delivery %d (%s) to user %d failed, retry at %s: %v", d.ID, d.Channel, d.UserID, at.Format("15:04:05"), err)
+	}
+
+	msg := err.Error()
+	if len(msg) > deliveryErrorLimit {
+		msg = strings.ToValidUTF8(msg[:deliveryErrorLimit], "")
+	}
+	if err := s.deliveryRepo.MarkFailed(ctx, d.ID, status, msg, next, now); err != nil {
+		log.Printf("[PUSH] %v", err)
+	}
+}
+
+// retryDeliveries повторяет доставки, срок которых наступил, и чистит
+// журнал от старых успешных.
+func (s *NotificationService) retryDeliveries(ctx context.Context) {
+	now := time.Now().In(utils.LaundryLocation())
+	due, err := s.deliveryRepo.ClaimDue(ctx, now, now.Add(deliveryLease), deliveryBatchSize)
+	if err != nil {
+		log.Printf("🤖 [WORKER] Error claiming deliveries: %v", err)
+		return
+	}
+	for i := range due {
+		s.deliver(ctx, &due[i])
+	}
+
+	if n, err := s.deliveryRepo.DeleteSentBefore(ctx, now.Add(-deliveryRetention)); err != nil {
+		log.Printf("🤖 [WORKER] %v", err)
+	} else if n > 0 {
+		log.Printf("🤖 [WORKER] Pruned %d old deliveries", n)
+	}
+}
+
+// ListDeliveries возвращает проблемные доставки для админки. status —
+// failed или dead, пустой — обе.
+func (s *NotificationService) ListDeliveries(ctx context.Context, status string, userID int) ([]models.NotificationDelivery, error) {
+	statuses := []string{models.DeliveryStatusFailed, models.DeliveryStatusDead}
+	switch status {
+	case "":
+	case models.DeliveryStatusFailed, models.DeliveryStatusDead:
+		statuses = []string{status}
+	default:
+		return nil, ErrInvalidDeliveryStatus
+	}
+	return s.deliveryRepo.List(ctx, statuses, userID, deliveryListLimit)
+}
+
+// RetryDelivery даёт доставке из dead ещё одну попытку при следующем
+// проходе воркера.
+func (s *NotificationService) RetryDelivery(ctx context.Context, id int) error {
+	ok, err := s.deliveryRepo.Requeue(ctx, id, time.Now().In(utils.LaundryLocation()))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrDeliveryNotFound
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"netiwash/internal/models"
+	"netiwash/pkg/utils"
+)
+
+var ErrInboxNotificationNotFound = errors.New("notification not found")
+
+const (
+	inboxRetention    = 90 * 24 * time.Hour
+	inboxDefaultLimit = 50
+	inboxMaxLimit     = 200
+)
+
+// InboxPage — страница входящих и число непрочитанных.
+type InboxPage struct {
+	Notifications []models.InboxNotification `json:"notifications"`
+	Unread        int                        `json:"unread"`
+}
+
+// saveToInbox кладёт отрисованное уведомление во входящие и сообщает об
+// этом открытым вкладкам получателя. Ошибка не мешает доставке по каналам.
+func (s *NotificationService) saveToInbox(ctx context.Context, userID int, kind string, payload *models.PushPayload) {
+	n := &models.InboxNotification{
+		Kind:      kind,
+		Title:     payload.Title,
+		Body:      payload.Body,
+		URL:       payload.URL,
+		CreatedAt: time.Now().In(utils.LaundryLocation()),
+	}
+	if err := s.inboxRepo.Create(ctx, userID, n); err != nil {
+		log.Printf("[PUSH] Failed to save notification to inbox of user %d: %v", userID, err)
+		return
+	}
+	s.events.Publish(ctx, models.Event{Type: models.EventNotificationCreated, UserID: &userID, Data: n})
+}
+
+// AddToInbox кладёт уведомление во входящие, не рассылая его по каналам:
+// для сообщений, которые доставляет не NotificationService, например писем
+// о бронях.
+func (s *NotificationService) AddToInbox(ctx context.Context, userID int, n models.Notification) {
+	payload, err := renderNotification(userLocale(ctx, s.prefsRepo, userID), n, nil)
+	if err != nil {
+		log.Printf("[PUSH] Failed to render notification %s for user %d: %v", n.Template, userID, err)
+		return
+	}
+	s.saveToInbox(ctx, userID, n.Kind, payload)
+}
+
+// GetInbox возвращает входящие пользователя от новых к старым. beforeID —
+// ID последнего уведомления предыдущей страницы.
+func (s *NotificationService) GetInbox(ctx context.Context, userID int, unreadOnly bool, beforeID, limit int) (*InboxPage, error) {
+	if limit <= 0 || limit > inboxMaxLimit {
+		limit = inboxDefaultLimit
+	}
+	items, err := s.inboxRepo.List(ctx, userID, unreadOnly, beforeID, limit)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := s.inboxRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &InboxPage{Notifications: make([]models.InboxNotification, 0, len(items)), Unread: unread}
+	for _, n := range items {
+		n.CreatedAt = utils.InLaundryLocation(n.CreatedAt)
+		if n.ReadAt != nil {
+			readAt := utils.InLaundryLocation(*n.ReadAt)
+			n.ReadAt = &readAt
+		}
+		page.Notifications = append(page.Notifications, n)
+	}
+	return page, nil
+}
+
+func (s *NotificationService) CountUnread(ctx context.Context, userID int) (int, error) {
+	return s.inboxRepo.CountUnread(ctx, userID)
+}
+
+func (s *NotificationService) MarkInboxRead(ctx context.Context, userID, id int) error {
+	ok, err := s.inboxRepo.MarkRead(ctx, userID, id, time.Now().In(utils.LaundryLocation()))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInboxNotificationNotFound
+	}
+	return nil
+}
+
+func (s *NotificationService) MarkInboxAllRead(ctx context.Context, userID int) (int64, error) {
+	return s.inboxRepo.MarkAllRead(ctx, userID, time.Now().In(utils.LaundryLocation()))
+}
+
+func (s *NotificationService) DeleteInboxNotification(ctx context.Context, userID, id int) error {
+	ok, err := s.inboxRepo.Delete(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInboxNotificationNotFound
+	}
+	return nil
+}
+
+// ClearInbox удаляет все уведомления пользователя или только прочитанные.
+func (s *NotificationService) ClearInbox(ctx context.Context, userID int, readOnly bool) (int64, error) {
+	return s.inboxRepo.Clear(ctx, userID, readOnly)
+}
+
+// pruneInbox удаляет уведомления старше срока хранения.
+func (s *NotificationService) pruneInbox(ctx context.Context) {
+	before := time.Now().In(utils.LaundryLocation()).Add(-inboxRetention)
+	if n, err := s.inboxRepo.DeleteBefore(ctx, before); err != nil {
+		log.Printf("🤖 [WORKER] %v", err)
+	} else if n > 0 {
+		log.Printf("🤖 [WORKER] Pruned %d old inbox notifications", n)
+	}
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"netiwash/internal/models"
+	"netiwash/internal/repository"
+	"netiwash/pkg/utils"
+)
+
+var ErrInvalidPreferences = errors.New("invalid notification preferences")
+
+// defaultPreferences — письма о бронях на почту, остальное пушем, без тихих часов.
+func defaultPreferences() *models.NotificationPreferences {
+	channels := make(map[string][]string, len(models.NotificationKinds))
+	for _, kind := range models.NotificationKinds {
+		channels[kind] = []string{models.ChannelWebPush}
+	}
+	channels[models.NotificationKindBooking] = []string{models.ChannelEmail}
+	return &models.NotificationPreferences{Locale: models.LocaleRU, Channels: channels}
+}
+
+// quietChannels — каналы, которые молчат в тихие часы: их доставки
+// откладываются до конца тихих часов.
+var quietChannels = map[string]bool{
+	models.ChannelWebPush:  true,
+	models.ChannelTelegram: true,
+}
+
+// userLocale — язык писем и уведомлений пользователя из его настроек;
+// без настроек — язык по умолчанию.
+func userLocale(ctx context.Context, prefsRepo *repository.NotificationPreferencesRepository, userID int) string {
+	stored, err := prefsRepo.Get(ctx, userID)
+	if err != nil {
+		log.Printf("[PUSH] Error getting preferences for user %d: %v", userID, err)
+	}
+	if stored == nil || stored.Locale == "" {
+		return models.LocaleRU
+	}
+	return stored.Locale
+}
+
+// GetPreferences возвращает настройки пользователя, дополненные значениями
+// по умолчанию для видов, которые он не задавал.
+func (s *NotificationService) GetPreferences(ctx context.Context, userID int) (*models.NotificationPreferences, error) {
+	stored, err := s.prefsRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs := defaultPreferences()
+	if stored == nil {
+		return prefs, nil
+	}
+	for kind, channels := range stored.Channels {
+		prefs.Channels[kind] = channels
+	}
+	prefs.Locale = stored.Locale
+	prefs.QuietHoursStart = stored.QuietHoursStart
+	prefs.QuietHoursEnd = stored.QuietHoursEnd
+	prefs.UpdatedAt = stored.UpdatedAt
+	return prefs, nil
+}
+
+func (s *NotificationService) UpdatePreferences(ctx context.Context, userID int, prefs *models.NotificationPreferences) (*models.NotificationPreferences, error) {
+	if err := validatePreferences(prefs); err != nil {
+		return nil, err
+	}
+	if err := s.prefsRepo.Save(ctx, userID, prefs); err != nil {
+		return nil, err
+	}
+	return s.GetPreferences(ctx, userID)
+}
+
+func validatePreferences(prefs *models.NotificationPreferences) error {
+	if prefs.Locale == "" {
+		prefs.Locale = models.LocaleRU
+	}
+	if !isLocale(prefs.Locale) {
+		return fmt.Errorf("%w: unknown locale %q", ErrInvalidPreferences, prefs.Locale)
+	}
+	if prefs.Channels == nil {
+		prefs.Channels = map[string][]string{}
+	}
+	for kind, channels := range prefs.Channels {
+		if !isNotificationKind(kind) {
+			return fmt.Errorf("%w: unknown kind %q", ErrInvalidPreferences, kind)
+		}
+		if channels == nil {
+			prefs.Channels[kind] = []string{}
+		}
+		for _, ch := range channels {
+			if !isChannel(ch) {
+				return fmt.Errorf("%w: unknown channel %q", ErrInvalidPreferences, ch)
+			}
+		}
+	}
+
+	if (prefs.QuietHoursStart == nil) != (prefs.QuietHoursEnd == nil) {
+		return fmt.Errorf("%w: quiet hours need both start and end", ErrInvalidPreferences)
+	}
+	if prefs.QuietHoursStart != nil {
+		for _, v := range []string{*prefs.QuietHoursStart, *prefs.QuietHoursEnd} {
+			if _, err := time.Parse("15:04", v); err != nil {
+				return fmt.Errorf("%w: quiet hours must be HH:MM", ErrInvalidPreferences)
+			}
+		}
+	}
+	return nil
+}
+
+// WantsChannel сообщает, выбрал ли пользователь канал channel для
+// уведомлений вида kind.
+func (s *NotificationService) WantsChannel(ctx context.Context, userID int, kind, channel string) bool {
+	prefs, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		log.Printf("[PUSH] Error getting preferences for user %d: %v", userID, err)
+		prefs = defaultPreferences()
+	}
+	for _, ch := range prefs.Channels[kind] {
+		if ch == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// quietUntil возвращает конец текущих тихих часов пользователя по времени
+// прачечной; false — сейчас не тихие часы.
+func quietUntil(prefs *models.NotificationPreferences, now time.Time) (time.Time, bool) {
+	if !inQuietHours(prefs, now) {
+		return time.Time{}, false
+	}
+	end, _ := time.Parse("15:04", *prefs.QuietHoursEnd)
+	now = now.In(utils.LaundryLocation())
+	until := time.Date(now.Year(), now.Month(), now.Day(), end.Hour(), end.Minute(), 0, 0, now.Location())
+	if !until.After(now) {
+		until = until.AddDate(0, 0, 1)
+	}
+	return until, true
+}
+
+func inQuietHours(prefs *models.NotificationPreferences, now time.Time) bool {
+	if prefs.QuietHoursStart == nil || prefs.QuietHoursEnd == nil {
+		return false
+	}
+	start, err1 := time.Parse("15:04", *prefs.QuietHoursStart)
+	end, err2 := time.Parse("15:04", *prefs.QuietHoursEnd)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+
+	now = now.In(utils.LaundryLocation())
+	cur := now.Hour()*60 + now.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+
+	if from <= to {
+		return cur >= from && cur < to
+	}
+	// Через полночь: 23:00–07:00.
+	return cur >= from || cur < to
+}
+
+func isNotificationKind(kind string) bool {
+	for _, k := range models.NotificationKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// isChannel проверяет канал по списку известных, а не подключённых: выбор
+// Telegram не должен пропадать из-за того, что бот временно выключен.
+func isChannel(channel string) bool {
+	for _, ch := range models.Channels {
+		if ch == channel {
+			return true
+		}
+	}
+	return false
+}
+
+func isLocale(locale string) bool {
+	for _, l := range models.Locales {
+		if l == locale {
+			return true
+		}
+	}
+	return false
+}
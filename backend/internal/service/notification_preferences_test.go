@@ -0,0 +1,73 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"netiwash/internal/models"
+	"netiwash/pkg/utils"
+)
+
+func TestQuietUntil(t *testing.T) {
+	loc := utils.LaundryLocation()
+	hours := func(start, end string) *models.NotificationPreferences {
+		return &models.NotificationPreferences{QuietHoursStart: &start, QuietHoursEnd: &end}
+	}
+
+	tests := []struct {
+		name  string
+		prefs *models.NotificationPreferences
+		now   time.Time
+		want  time.Time
+		quiet bool
+	}{
+		{"no quiet hours", &models.NotificationPreferences{}, time.Date(2026, 10, 19, 23, 30, 0, 0, loc), time.Time{}, false},
+		{"before midnight", hours("23:00", "07:00"), time.Date(2026, 10, 19, 23, 30, 0, 0, loc), time.Date(2026, 10, 20, 7, 0, 0, 0, loc), true},
+		{"after midnight", hours("23:00", "07:00"), time.Date(2026, 10, 20, 6, 59, 0, 0, loc), time.Date(2026, 10, 20, 7, 0, 0, 0, loc), true},
+		{"quiet hours are over", hours("23:00", "07:00"), time.Date(2026, 10, 20, 7, 0, 0, 0, loc), time.Time{}, false},
+		{"same day", hours("13:00", "15:30"), time.Date(2026, 10, 19, 14, 0, 0, 0, loc), time.Date(2026, 10, 19, 15, 30, 0, 0, loc), true},
+		// 21:00 UTC — 04:00 следующего дня в Новосибирске.
+		{"utc clock", hours("23:00", "07:00"), time.Date(2026, 10, 19, 21, 0, 0, 0, time.UTC), time.Date(2026, 10, 20, 7, 0, 0, 0, loc), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, quiet := quietUntil(tt.prefs, tt.now)
+			if quiet != tt.quiet || !got.Equal(tt.want) {
+				t.Fatalf("quietUntil = %s, %v; want %s, %v", got, quiet, tt.want, tt.quiet)
+			}
+		})
+	}
+}
+
+func TestValidatePreferences(t *testing.T) {
+	valid := &models.NotificationPreferences{
+		Locale: models.LocaleEN,
+		Channels: map[string][]string{
+			// Telegram принимается, даже если бот на этом сервере не настроен.
+			models.NotificationKindReminder:     {models.ChannelWebPush, models.ChannelTelegram},
+			models.NotificationKindAnnouncement: {models.ChannelEmail},
+			models.NotificationKindCompletion:   nil,
+			models.NotificationKindWaitlist:     {models.ChannelWebPush},
+		},
+	}
+	if err := validatePreferences(valid); err != nil {
+		t.Fatalf("validatePreferences: %v", err)
+	}
+	if valid.Channels[models.NotificationKindCompletion] == nil {
+		t.Fatal("nil channel list should become empty")
+	}
+
+	start, end := "23:00", "7am"
+	for name, prefs := range map[string]*models.NotificationPreferences{
+		"unknown channel": {Channels: map[string][]string{models.NotificationKindReminder: {"sms"}}},
+		"unknown kind":    {Channels: map[string][]string{"promo": {models.ChannelWebPush}}},
+		"unknown locale":  {Locale: "de"},
+		"half of quiet":   {QuietHoursStart: &start},
+		"bad quiet hours": {QuietHoursStart: &start, QuietHoursEnd: &end},
+	} {
+		if err := validatePreferences(prefs); !errors.Is(err, ErrInvalidPreferences) {
+			t.Errorf("%s: err = %v, want ErrInvalidPreferences", name, err)
+		}
+	}
+}
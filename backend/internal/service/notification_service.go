@@ -4,80 +4,160 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"math"
+	"net/http"
 	"netiwash/internal/models"
 	"netiwash/internal/repository"
-	"os"
+	"netiwash/pkg/utils"
+	"sort"
 	"time"
 
 	"github.com/SherClockHolmes/webpush-go"
 )
 
 type NotificationService struct {
-	repo        *repository.PushRepository
-	bookingRepo *repository.BookingRepository
-	vapidOne    string // приватный ключ
-	vapidTwo    string // публичный
-	vapidEmail  string
-}
+	repo         *repository.PushRepository
+	bookingRepo  *repository.BookingRepository
+	prefsRepo    *repository.NotificationPreferencesRepository
+	deliveryRepo *repository.NotificationDeliveryRepository
+	inboxRepo    *repository.InboxRepository
+	channels     map[string]NotificationChannel
+	events       EventPublisher
+	reminders    []time.Duration // по возрастанию
+	vapid        *VAPIDKeyring
 
-func NewNotificationService(repo *repository.PushRepository, bookingRepo *repository.BookingRepository) *NotificationService {
-	priv := os.Getenv("VAPID_PRIVATE_KEY")
-	pub := os.Getenv("VAPID_PUBLIC_KEY")
-	email := os.Getenv("VAPID_EMAIL")
+	signer   *utils.LinkSigner // подпись кнопок в пушах, см. EnableActions
+	bookings *BookingService
+}
 
-	if email == "" {
-		email = "mailto:admin@neti.ru"
-	}
-
-	if priv == "" {
-		priv = "MCkShAz8ggYo3uq8j5wMHfmv0fSZgvLaRC0j0YaCaUk"
-	}
-	if pub == "" {
-		pub = "BIV66_T0YefjdnM4JUXri7hD8m9cn_mRLcHYlEQJ5B4NcLo2UnPelrzJcbQd_6sTCHf9n0583IKwPzxdqqBFjM0"
-	}
+func NewNotificationService(repo *repository.PushRepository, bookingRepo *repository.BookingRepository, prefsRepo *repository.NotificationPreferencesRepository, deliveryRepo *repository.NotificationDeliveryRepository, inboxRepo *repository.InboxRepository, events EventPublisher, vapid *VAPIDKeyring, reminderOffsets []time.Duration) *NotificationService {
+	reminders := append([]time.Duration(nil), reminderOffsets...)
+	sort.Slice(reminders, func(i, j int) bool { return reminders[i] < reminders[j] })
 
 	return &NotificationService{
-		repo:        repo,
-		bookingRepo: bookingRepo,
-		vapidOne:    priv,
-		vapidTwo:    pub,
-		vapidEmail:  email,
+		repo:         repo,
+		bookingRepo:  bookingRepo,
+		prefsRepo:    prefsRepo,
+		deliveryRepo: deliveryRepo,
+		inboxRepo:    inboxRepo,
+		channels:     make(map[string]NotificationChannel),
+		events:       events,
+		reminders:    reminders,
+		vapid:        vapid,
 	}
 }
 
-func (s *NotificationService) GetPublicKey() string {
-	return s.vapidTwo
+// RegisterChannel подключает канал доставки; name указывается в настройках.
+// Пуш встроен в сервис: он рассылается по подпискам, а не через канал.
+func (s *NotificationService) RegisterChannel(name string, ch NotificationChannel) {
+	s.channels[name] = ch
 }
 
-func (s *NotificationService) Subscribe(ctx context.Context, userID int, endpoint, p256dh, auth string) error {
+// GetPublicKey — активный ключ VAPID. Если он не совпадает с ключом
+// подписки браузера, браузер должен переподписаться.
+func (s *NotificationService) GetPublicKey(ctx context.Context) string {
+	return s.vapid.Current(ctx).PublicKey
+}
+
+// Subscribe сохраняет подписку браузера. applicationServerKey — ключ, с
+// которым браузер подписался; подписку на старый ключ после ротации не
+// принимаем (ErrStaleVAPIDKey), браузер должен переподписаться.
+func (s *NotificationService) Subscribe(ctx context.Context, userID int, endpoint, p256dh, auth, userAgent, applicationServerKey string) error {
+	key := s.vapid.Current(ctx).PublicKey
+	if applicationServerKey != "" && applicationServerKey != key {
+		if !s.vapid.IsCurrent(ctx, applicationServerKey) {
+			return ErrStaleVAPIDKey
+		}
+		key = applicationServerKey
+	}
+
 	sub := &models.PushSubscription{
-		UserID:   userID,
-		Endpoint: endpoint,
-		P256dh:   p256dh,
-		Auth:     auth,
+		UserID:    userID,
+		Endpoint:  endpoint,
+		P256dh:    p256dh,
+		Auth:      auth,
+		UserAgent: userAgent,
+		VAPIDKey:  key,
 	}
 	return s.repo.CreateSubscription(ctx, sub)
 }
 
-func (s *NotificationService) SendNotification(ctx context.Context, userID int, message string) {
-	subs, err := s.repo.GetSubscriptionsByUserID(ctx, userID)
-	if err != nil {
-		log.Printf("[PUSH] Error getting subscriptions for user %d: %v", userID, err)
-		return
+// AssignLegacySubscriptions привязывает подписки, созданные до учёта
+// ключей, к legacyKey — ключу, которым их тогда подписал сервер. Если этого
+// ключа нет среди известных, первая же доставка удалит такую подписку, и
+// браузер переподпишется на активный ключ.
+func (s *NotificationService) AssignLegacySubscriptions(ctx context.Context, legacyKey string) error {
+	n, err := s.repo.AssignLegacyKey(ctx, legacyKey)
+	if err != nil || n == 0 {
+		return err
 	}
+	if _, ok := s.vapid.Lookup(ctx, legacyKey); ok {
+		log.Printf("[PUSH] Assigned VAPID key %s to %d existing subscriptions", legacyKey, n)
+	} else {
+		log.Printf("[PUSH] %d existing subscriptions use VAPID key %s which is no longer configured, browsers will resubscribe", n, legacyKey)
+	}
+	return nil
+}
 
-	if len(subs) == 0 {
-		return
+// SendNotification отправляет уведомление по каналам, которые пользователь
+// выбрал для его вида. Текст собирается из шаблона на языке пользователя и
+// сохраняется во входящих — даже если ни один канал не выбран. Каждая
+// доставка (канал или push-устройство) записывается в журнал до отправки,
+// неудачные повторяет воркер. В тихие часы доставки по «громким» каналам
+// записываются со сроком на их конец, и их отправляет воркер. Ошибка
+// означает, что записать доставки не удалось и уведомление не ушло.
+func (s *NotificationService) SendNotification(ctx context.Context, userID int, n models.Notification) error {
+	prefs, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		log.Printf("[PUSH] Error getting preferences for user %d: %v", userID, err)
+		prefs = defaultPreferences()
+	}
+	channels := []string{models.ChannelWebPush}
+	var deferUntil *time.Time
+	if n.Kind != models.NotificationKindSystem {
+		channels = prefs.Channels[n.Kind]
+		if until, ok := quietUntil(prefs, time.Now()); ok {
+			deferUntil = &until
+		}
 	}
 
-	log.Printf("[PUSH] Sending to %d devices for user %d", len(subs), userID)
+	var sign func(action string) string
+	if s.signer != nil && n.BookingID != 0 {
+		now := time.Now()
+		sign = func(action string) string { return s.actionToken(action, n.BookingID, userID, now) }
+	}
+	payload, err := renderNotification(prefs.Locale, n, sign)
+	if err != nil {
+		log.Printf("[PUSH] Failed to render notification %s for user %d: %v", n.Template, userID, err)
+		return err
+	}
+	s.saveToInbox(ctx, userID, n.Kind, payload)
 
-	for _, sub := range subs {
-		s.sendToSubscription(sub, message)
+	deliveries, err := s.enqueue(ctx, userID, n.Kind, payload, channels, deferUntil)
+	if err != nil {
+		log.Printf("[PUSH] Failed to record notification for user %d: %v", userID, err)
+		return err
+	}
+	for i := range deliveries {
+		s.deliver(ctx, &deliveries[i])
 	}
+	return nil
 }
 
-func (s *NotificationService) sendToSubscription(sub models.PushSubscription, message string) {
+// sendToSubscription отправляет пуш на одно устройство ключом, с которым
+// создана подписка. Подписку, которую push-сервис считает несуществующей
+// (404/410) или ключ которой уже удалён, удаляем: повторять такую доставку
+// бессмысленно.
+func (s *NotificationService) sendToSubscription(ctx context.Context, sub models.PushSubscription, message []byte) error {
+	key, ok := s.vapid.Lookup(ctx, sub.VAPIDKey)
+	if !ok {
+		log.Printf("[PUSH] Subscription %d of user %d uses a deleted VAPID key, deleting", sub.ID, sub.UserID)
+		if _, err := s.repo.DeleteByID(ctx, sub.ID, 0); err != nil {
+			log.Printf("[PUSH] %v", err)
+		}
+		return fmt.Errorf("%w: VAPID key deleted", errDeliveryPermanent)
+	}
+
 	sObj := &webpush.Subscription{
 		Endpoint: sub.Endpoint,
 		Keys: webpush.Keys{
@@ -86,47 +166,94 @@ func (s *NotificationService) sendToSubscription(sub models.PushSubscription, me
 		},
 	}
 
-	resp, err := webpush.SendNotification([]byte(message), sObj, &webpush.Options{
-		Subscriber:      s.vapidEmail,
-		VAPIDPublicKey:  s.vapidTwo,
-		VAPIDPrivateKey: s.vapidOne,
+	resp, err := webpush.SendNotification(message, sObj, &webpush.Options{
+		Subscriber:      s.vapid.subject,
+		VAPIDPublicKey:  key.PublicKey,
+		VAPIDPrivateKey: key.PrivateKey,
 		TTL:             30,
 	})
 	if err != nil {
-		log.Printf("[PUSH] Send error: %v", err)
-		return
+		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == 201 {
-	} else if resp.StatusCode == 410 {
-		log.Printf("[PUSH] Subscription expired/gone")
-	} else {
-		log.Printf("[PUSH] Unexpected status code: %d", resp.StatusCode)
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		if err := s.repo.MarkSuccess(ctx, sub.ID, time.Now().In(utils.LaundryLocation())); err != nil {
+			log.Printf("[PUSH] %v", err)
+		}
+		return nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		log.Printf("[PUSH] Subscription %d of user %d is gone (%d), deleting", sub.ID, sub.UserID, resp.StatusCode)
+		if _, err := s.repo.DeleteByID(ctx, sub.ID, 0); err != nil {
+			log.Printf("[PUSH] %v", err)
+		}
+		return fmt.Errorf("%w: push service returned %d", errDeliveryPermanent, resp.StatusCode)
+	case resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests:
+		return fmt.Errorf("%w: push service returned %d", errDeliveryPermanent, resp.StatusCode)
+	default:
+		return fmt.Errorf("push service returned %d", resp.StatusCode)
+	}
+}
+
+// HandleEvent — подписчик шины событий. Владельцу брони, которую отменил
+// не он сам, уходит пуш с причиной.
+func (s *NotificationService) HandleEvent(ctx context.Context, e models.Event) {
+	if e.Type != models.EventBookingStatus || e.UserID == nil {
+		return
+	}
+	st, ok := e.Data.(models.BookingStatusEvent)
+	if !ok || st.Cancellation == nil {
+		return
+	}
+
+	tmpl := tmplCancelledByAdmin
+	switch st.Cancellation.Cause {
+	case models.CancelCauseMachineRemoved:
+		tmpl = tmplCancelledMachineGone
+	case models.CancelCauseMaintenance:
+		tmpl = tmplCancelledMaintenance
 	}
+	s.SendNotification(ctx, *e.UserID, models.Notification{
+		Kind:     models.NotificationKindSystem,
+		Template: tmpl,
+		Params: map[string]any{
+			"BookingID": st.BookingID,
+			"Start":     st.StartTime.Format("02.01 15:04"),
+			"Reason":    st.Cancellation.Reason,
+		},
+		URL: "bookings.html",
+		Tag: fmt.Sprintf("booking-%d", st.BookingID),
+	})
 }
 
-func (s *NotificationService) StartWorker(ctx context.Context) {
+func (s *NotificationService) RunWorker(ctx context.Context) {
 	ticker := time.NewTicker(30 * time.Second)
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				s.checkAndNotify(ctx)
-			}
-		}
-	}()
+	defer ticker.Stop()
 	log.Println("🤖 [WORKER] Notification worker started")
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.checkAndNotify(ctx)
+		}
+	}
 }
 
 func (s *NotificationService) checkAndNotify(ctx context.Context) {
+	s.retryDeliveries(ctx)
+	s.pruneInbox(ctx)
+	s.sendReminders(ctx)
+	s.sendEndingNotices(ctx)
+
 	activeExpired, err := s.bookingRepo.GetExpiredActiveBookings(ctx)
 	if err == nil {
 		for _, b := range activeExpired {
 			log.Printf("🤖 [WORKER] Auto-completing booking %d", b.ID)
-			s.bookingRepo.UpdateStatus(ctx, b.ID, "completed")
+			if err := s.bookingRepo.Complete(ctx, b.ID); err == nil {
+				publishBooking(ctx, s.events, models.EventBookingCompleted, &b, "completed", nil)
+			}
 		}
 	}
 
@@ -138,10 +265,158 @@ func (s *NotificationService) checkAndNotify(ctx context.Context) {
 
 	for _, b := range unnotified {
 		log.Printf("🤖 [WORKER] Sending push for booking %d", b.ID)
+		s.notifyCompleted(ctx, &b)
+	}
+}
+
+// sendReminders напоминает о бронях, которые скоро начнутся. Отправка
+// каждого напоминания фиксируется в БД, поэтому после рестарта оно не
+// повторяется. Если наступило сразу несколько порогов (бронь создана за
+// 3 минуты до начала), уходит одно напоминание.
+func (s *NotificationService) sendReminders(ctx context.Context) {
+	if len(s.reminders) == 0 {
+		return
+	}
+
+	now := time.Now().In(utils.LaundryLocation())
+	upcoming, err := s.bookingRepo.GetUpcomingActive(ctx, now, now.Add(s.reminders[len(s.reminders)-1]))
+	if err != nil {
+		log.Printf("🤖 [WORKER] Error checking upcoming bookings: %v", err)
+		return
+	}
+
+	for _, b := range upcoming {
+		start := utils.InLaundryLocation(b.StartTime)
+		left := start.Sub(now)
+
+		due := false
+		for _, offset := range s.reminders {
+			if left > offset {
+				continue
+			}
+			claimed, err := s.bookingRepo.ClaimReminder(ctx, b.ID, int(offset/time.Minute), b.StartTime)
+			if err != nil {
+				log.Printf("🤖 [WORKER] Error claiming reminder for booking %d: %v", b.ID, err)
+				break
+			}
+			due = due || claimed
+		}
+		if !due {
+			continue
+		}
+
+		log.Printf("🤖 [WORKER] Sending reminder for booking %d", b.ID)
+		s.SendNotification(ctx, b.UserID, models.Notification{
+			Kind:     models.NotificationKindReminder,
+			Template: tmplReminder,
+			Params: map[string]any{
+				"Minutes": int(math.Ceil(left.Minutes())),
+				"Machine": b.MachineName,
+				"Start":   start.Format("15:04"),
+			},
+			URL:       "bookings.html",
+			Tag:       fmt.Sprintf("booking-%d", b.ID),
+			BookingID: b.ID,
+		})
+	}
+}
+
+// sendEndingNotices предупреждает, что идущая бронь скоро закончится, и
+// предлагает продлить её. Кнопка продления есть только здесь: до начала
+// брони продлевать рано, а продлить можно один раз.
+func (s *NotificationService) sendEndingNotices(ctx context.Context) {
+	now := time.Now().In(utils.LaundryLocation())
+	ending, err := s.bookingRepo.GetEndingActive(ctx, now, now.Add(bookingEndingNotice))
+	if err != nil {
+		log.Printf("🤖 [WORKER] Error checking ending bookings: %v", err)
+		return
+	}
+
+	for _, b := range ending {
+		claimed, err := s.bookingRepo.ClaimEndingNotice(ctx, b.ID, b.EndTime)
+		if err != nil {
+			log.Printf("🤖 [WORKER] Error claiming ending notice for booking %d: %v", b.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
 
-		msg := fmt.Sprintf("Стирка #%d завершена! Не забудьте забрать вещи.", b.ID)
-		s.SendNotification(ctx, b.UserID, msg)
+		start, end := utils.InLaundryLocation(b.StartTime), utils.InLaundryLocation(b.EndTime)
+		var actions []string
+		if end.Before(start.Add(bookingDuration + bookingExtension)) {
+			actions = []string{models.NotificationActionExtend}
+		}
+
+		log.Printf("🤖 [WORKER] Sending ending notice for booking %d", b.ID)
+		s.SendNotification(ctx, b.UserID, models.Notification{
+			Kind:     models.NotificationKindReminder,
+			Template: tmplBookingEnding,
+			Params: map[string]any{
+				"Minutes": int(math.Ceil(end.Sub(now).Minutes())),
+				"Machine": b.MachineName,
+				"End":     end.Format("15:04"),
+			},
+			URL:       "bookings.html",
+			Tag:       fmt.Sprintf("booking-%d", b.ID),
+			BookingID: b.ID,
+			Actions:   actions,
+		})
+	}
+}
+
+// NotifyCycleCompleted вызывается, когда телеметрия сообщила об окончании
+// стирки. Бронь, к которой относится цикл, завершается досрочно, а
+// владелец получает то же уведомление, что и от воркера.
+func (s *NotificationService) NotifyCycleCompleted(ctx context.Context, machineID int, startedAt, endedAt time.Time) {
+	loc := utils.LaundryLocation()
+	b, err := s.bookingRepo.GetUnnotifiedForCycle(ctx, machineID, startedAt.In(loc), endedAt.In(loc))
+	if err != nil {
+		log.Printf("[PUSH] Error finding booking for machine %d: %v", machineID, err)
+		return
+	}
+	if b == nil {
+		return
+	}
+
+	if b.Status == "active" {
+		if err := s.bookingRepo.Complete(ctx, b.ID); err != nil {
+			log.Printf("[PUSH] Error completing booking %d: %v", b.ID, err)
+			return
+		}
+		publishBooking(ctx, s.events, models.EventBookingCompleted, b, "completed", nil)
+	}
+
+	s.notifyCompleted(ctx, b)
+}
+
+// notifyCompleted отправляет уведомление об окончании стирки, если его ещё
+// никто не отправил. Отметка ставится до отправки и снимается, если
+// уведомление не удалось поставить в очередь: тогда его повторит воркер.
+func (s *NotificationService) notifyCompleted(ctx context.Context, b *models.Booking) {
+	claimed, err := s.bookingRepo.ClaimPushSent(ctx, b.ID)
+	if err != nil {
+		log.Printf("[PUSH] %v", err)
+		return
+	}
+	if !claimed {
+		return
+	}
+	if err := s.SendNotification(ctx, b.UserID, completionNotification(b.ID)); err != nil {
+		if err := s.bookingRepo.ReleasePushSent(ctx, b.ID); err != nil {
+			log.Printf("[PUSH] %v", err)
+		}
+	}
+}
 
-		s.bookingRepo.MarkPushSent(ctx, b.ID)
+func completionNotification(bookingID int) models.Notification {
+	return models.Notification{
+		Kind:      models.NotificationKindCompletion,
+		Template:  tmplCompletion,
+		Params:    map[string]any{"BookingID": bookingID},
+		URL:       "bookings.html",
+		Tag:       fmt.Sprintf("booking-%d", bookingID),
+		BookingID: bookingID,
+		Actions:   []string{models.NotificationActionPickedUp},
 	}
 }
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"netiwash/internal/models"
+)
+
+// Шаблоны уведомлений. Параметры передаются в models.Notification.Params.
+const (
+	tmplReminder             = "reminder"                          // Minutes, Machine, Start
+	tmplCompletion           = "completion"                        // BookingID
+	tmplBookingEnding        = "booking.ending"                    // Minutes, Machine, End
+	tmplBookingConfirmed     = "booking.confirmed"                 // BookingID, Machine, Start
+	tmplBookingRescheduled   = "booking.rescheduled"               // BookingID, Machine, Start
+	tmplBookingExtended      = "booking.extended"                  // BookingID, Machine, End
+	tmplCancelledByAdmin     = "booking_cancelled.admin"           // BookingID, Start, Reason
+	tmplCancelledMachineGone = "booking_cancelled.machine_removed" // BookingID, Start, Reason
+	tmplCancelledMaintenance = "booking_cancelled.maintenance"     // BookingID, Start, Reason
+	tmplTicketResolved       = "ticket_resolved"                   // Machine, TicketID
+	tmplAnnouncement         = "announcement"                      // Title, Body
+)
+
+const (
+	notificationIcon          = "/icons/icon-192x192.png"
+	notificationDefaultLocale = models.LocaleRU
+)
+
+// notificationTexts — заголовок и текст каждого шаблона по языкам. Если
+// шаблона нет на языке пользователя, берётся русский.
+var notificationTexts = map[string]map[string][2]string{
+	models.LocaleRU: {
+		tmplReminder:             {"Скоро стирка", "Через {{.Minutes}} мин. начинается ваша стирка: {{.Machine}} в {{.Start}}."},
+		tmplCompletion:           {"Стирка завершена", "Стирка #{{.BookingID}} завершена! Не забудьте забрать вещи."},
+		tmplBookingEnding:        {"Бронь скоро закончится", "Бронь на {{.Machine}} закончится через {{.Minutes}} мин, в {{.End}}."},
+		tmplBookingConfirmed:     {"Бронь подтверждена", "Бронь #{{.BookingID}}: {{.Machine}}, {{.Start}}."},
+		tmplBookingRescheduled:   {"Бронь перенесена", "Бронь #{{.BookingID}} перенесена: {{.Machine}}, {{.Start}}."},
+		tmplBookingExtended:      {"Бронь продлена", "Бронь #{{.BookingID}} на {{.Machine}} продлена до {{.End}}."},
+		tmplCancelledByAdmin:     {"Бронь отменена", "Бронь #{{.BookingID}} на {{.Start}} отменена: {{.Reason}}."},
+		tmplCancelledMachineGone: {"Бронь отменена", "Бронь #{{.BookingID}} на {{.Start}} отменена: {{.Reason}}."},
+		tmplCancelledMaintenance: {"Бронь отменена", "Бронь #{{.BookingID}} на {{.Start}} отменена: {{.Reason}}."},
+		tmplTicketResolved:       {"Машина снова работает", "{{.Machine}} снова работает. Спасибо, что сообщили о проблеме (заявка #{{.TicketID}})!"},
+		tmplAnnouncement:         {"{{.Title}}", "{{.Body}}"},
+	},
+	models.LocaleEN: {
+		tmplReminder:             {"Laundry starts soon", "Your laundry starts in {{.Minutes}} min: {{.Machine}} at {{.Start}}."},
+		tmplCompletion:           {"Laundry is done", "Laundry #{{.BookingID}} is done! Don't forget to pick up your clothes."},
+		tmplBookingEnding:        {"Booking ends soon", "Your booking of {{.Machine}} ends in {{.Minutes}} min, at {{.End}}."},
+		tmplBookingConfirmed:     {"Booking confirmed", "Booking #{{.BookingID}}: {{.Machine}}, {{.Start}}."},
+		tmplBookingRescheduled:   {"Booking rescheduled", "Booking #{{.BookingID}} was moved: {{.Machine}}, {{.Start}}."},
+		tmplBookingExtended:      {"Booking extended", "Booking #{{.BookingID}} on {{.Machine}} now ends at {{.End}}."},
+		tmplCancelledByAdmin:     {"Booking cancelled", "Booking #{{.BookingID}} at {{.Start}} was cancelled by an administrator."},
+		tmplCancelledMachineGone: {"Booking cancelled", "Booking #{{.BookingID}} at {{.Start}} was cancelled: the machine was taken out of service."},
+		tmplCancelledMaintenance: {"Booking cancelled", "Booking #{{.BookingID}} at {{.Start}} was cancelled: the machine is scheduled for maintenance."},
+		tmplTicketResolved:       {"Machine is back", "{{.Machine}} works again. Thanks for reporting the problem (ticket #{{.TicketID}})!"},
+	},
+}
+
+var notificationActionTitles = map[string]map[string]string{
+	models.LocaleRU: {
+		models.NotificationActionExtend:   "Продлить на 30 мин",
+		models.NotificationActionPickedUp: "Я забрал вещи",
+	},
+	models.LocaleEN: {
+		models.NotificationActionExtend:   "Extend by 30 min",
+		models.NotificationActionPickedUp: "I picked it up",
+	},
+}
+
+type notificationTemplate struct {
+	title *template.Template
+	body  *template.Template
+}
+
+var notificationTemplates = parseNotificationTemplates()
+
+func parseNotificationTemplates() map[string]map[string]notificationTemplate {
+	out := make(map[string]map[string]notificationTemplate, len(notificationTexts))
+	for locale, texts := range notificationTexts {
+		out[locale] = make(map[string]notificationTemplate, len(texts))
+		for name, t := range texts {
+			id := locale + "/" + name
+			out[locale][name] = notificationTemplate{
+				title: template.Must(template.New(id + "/title").Option("missingkey=error").Parse(t[0])),
+				body:  template.Must(template.New(id + "/body").Option("missingkey=error").Parse(t[1])),
+			}
+		}
+	}
+	return out
+}
+
+// renderNotification собирает пуш на языке locale. Кнопки действий
+// добавляются, только если передан sign — он выдаёт токен для кнопки.
+func renderNotification(locale string, n models.Notification, sign func(action string) string) (*models.PushPayload, error) {
+	t, ok := notificationTemplates[locale][n.Template]
+	if !ok {
+		locale = notificationDefaultLocale
+		if t, ok = notificationTemplates[locale][n.Template]; !ok {
+			return nil, fmt.Errorf("unknown notification template %q", n.Template)
+		}
+	}
+
+	var title, body strings.Builder
+	if err := t.title.Execute(&title, n.Params); err != nil {
+		return nil, err
+	}
+	if err := t.body.Execute(&body, n.Params); err != nil {
+		return nil, err
+	}
+
+	p := &models.PushPayload{
+		Title: title.String(),
+		Body:  body.String(),
+		Icon:  notificationIcon,
+		URL:   n.URL,
+		Tag:   n.Tag,
+	}
+	if sign != nil {
+		for _, action := range n.Actions {
+			p.Actions = append(p.Actions, models.PushAction{
+				Action: action,
+				Title:  notificationActionTitles[locale][action],
+				Token:  sign(action),
+			})
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"netiwash/internal/models"
+	"netiwash/internal/repository"
+	"netiwash/pkg/payment"
+	"netiwash/pkg/utils"
+)
+
+var (
+	ErrPaymentNotFound       = errors.New("payment not found")
+	ErrPaymentAmountMismatch = errors.New("payment amount mismatch")
+	ErrInvalidCredits        = errors.New("credits must be between 1 and 100")
+	ErrPaymentUserNotFound   = errors.New("user not found")
+	ErrPaymentProvider       = errors.New("payment provider error")
+)
+
+const maxCheckoutCredits = 100
+
+type PaymentService struct {
+	repo          *repository.PaymentRepository
+	walletRepo    *repository.WalletRepository
+	userRepo      *repository.UserRepository
+	provider      payment.Provider
+	webhookSecret string
+	returnURL     string
+	creditPrice   int
+}
+
+func NewPaymentService(repo *repository.PaymentRepository, walletRepo *repository.WalletRepository, userRepo *repository.UserRepository, provider payment.Provider, webhookSecret, returnURL string, creditPrice int) *PaymentService {
+	return &PaymentService{
+		repo:          repo,
+		walletRepo:    walletRepo,
+		userRepo:      userRepo,
+		provider:      provider,
+		webhookSecret: webhookSecret,
+		returnURL:     returnURL,
+		creditPrice:   creditPrice,
+	}
+}
+
+func (s *PaymentService) CreateCheckout(ctx context.Context, userID, credits int) (*models.Payment, error) {
+	if credits < 1 || credits > maxCheckoutCredits {
+		return nil, ErrInvalidCredits
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrPaymentUserNotFound
+	}
+
+	key, err := utils.GenerateSecureToken()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &models.Payment{
+		UserID:         userID,
+		IdempotenceKey: key,
+		Credits:        credits,
+		Amount:         credits * s.creditPrice,
+		Currency:       "RUB",
+		Status:         models.PaymentStatusPending,
+	}
+	if err := s.repo.Create(ctx, p); err != nil {
+		return nil, err
+	}
+
+	res, err := s.provider.CreatePayment(ctx, payment.CreatePaymentRequest{
+		IdempotenceKey: key,
+		Amount:         p.Amount,
+		Currency:       p.Currency,
+		Description:    fmt.Sprintf("NETI WASH: %d стирок", credits),
+		ReturnURL:      s.returnURL,
+		Metadata: map[string]string{
+			"payment_id": strconv.Itoa(p.ID),
+			"user_id":    strconv.Itoa(userID),
+		},
+	})
+	if err != nil {
+		log.Printf("💳 [PAYMENT] Provider error for payment %d: %v", p.ID, err)
+		providerErr := fmt.Errorf("%w: %v", ErrPaymentProvider, err)
+		if err := s.repo.UpdateStatus(ctx, p.ID, models.PaymentStatusCanceled); err != nil {
+			return nil, errors.Join(providerErr, err)
+		}
+		return nil, providerErr
+	}
+
+	if err := s.repo.AttachProviderPayment(ctx, p.ID, res.ID, res.ConfirmationURL); err != nil {
+		return nil, err
+	}
+	p.ProviderPaymentID = res.ID
+	p.ConfirmationURL = res.ConfirmationURL
+
+	log.Printf("💳 [PAYMENT] Checkout %s created for user %d (%d credits)", res.ID, userID, credits)
+	return p, nil
+}
+
+// HandleWebhook проверяет подпись уведомления, перезапрашивает платёж у
+// провайдера и применяет его итоговый статус. Повторные уведомления
+// безопасны: кредиты зачисляются не более одного раза.
+func (s *PaymentService) HandleWebhook(ctx context.Context, body []byte, signature string) error {
+	if err := payment.VerifySignature(body, signature, s.webhookSecret); err != nil {
+		return err
+	}
+
+	var event payment.WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return fmt.Errorf("invalid webhook payload: %w", err)
+	}
+	if event.Object.ID == "" {
+		return errors.New("invalid webhook payload: missing payment id")
+	}
+
+	return s.Reconcile(ctx, event.Object.ID)
+}
+
+func (s *PaymentService) Reconcile(ctx context.Context, providerPaymentID string) error {
+	local, err := s.repo.GetByProviderID(ctx, providerPaymentID)
+	if err != nil {
+		return err
+	}
+	if local == nil {
+		return ErrPaymentNotFound
+	}
+
+	remote, err := s.provider.GetPayment(ctx, providerPaymentID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch payment from provider: %w", err)
+	}
+
+	switch remote.Status {
+	case payment.StatusSucceeded:
+		amount, err := payment.ParseAmount(remote.Amount.Value)
+		if err != nil {
+			return err
+		}
+		if amount != local.Amount || remote.Amount.Currency != local.Currency {
+			log.Printf("💳 [PAYMENT] Amount mismatch for %s: expected %d %s, got %s %s",
+				providerPaymentID, local.Amount, local.Currency, remote.Amount.Value, remote.Amount.Currency)
+			return ErrPaymentAmountMismatch
+		}
+		credited, err := s.repo.MarkSucceededAndCredit(ctx, providerPaymentID)
+		if err != nil {
+			return err
+		}
+		if credited {
+			log.Printf("💳 [PAYMENT] Credited %d to user %d (payment %s)", local.Credits, local.UserID, providerPaymentID)
+		}
+	case payment.StatusCanceled:
+		return s.repo.MarkCanceled(ctx, providerPaymentID)
+	}
+	return nil
+}
+
+func (s *PaymentService) GetWallet(ctx context.Context, userID int) (*models.Wallet, []models.WalletTransaction, error) {
+	wallet, err := s.walletRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	txs, err := s.walletRepo.GetTransactions(ctx, userID, 50)
+	if err != nil {
+		return nil, nil, err
+	}
+	return wallet, txs, nil
+}
+
+func (s *PaymentService) GetPayments(ctx context.Context, userID int) ([]models.Payment, error) {
+	return s.repo.GetByUserID(ctx, userID)
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"netiwash/internal/models"
+	"netiwash/internal/repository"
+	"netiwash/pkg/payment"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const testWebhookSecret = "test-webhook-secret"
+
+// paymentFixture — PaymentService на тестовой базе и заглушка провайдера
+// из cmd/fake_payment_provider, которая шлёт webhook прямо в сервис.
+type paymentFixture struct {
+	t        *testing.T
+	pool     *pgxpool.Pool
+	service  *PaymentService
+	provider *httptest.Server
+	webhooks chan error
+}
+
+func newPaymentFixture(t *testing.T) *paymentFixture {
+	t.Helper()
+	pool := testDB(t)
+
+	f := &paymentFixture{t: t, pool: pool, webhooks: make(chan error, 10)}
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		err := f.service.HandleWebhook(r.Context(), body, r.Header.Get(payment.SignatureHeader))
+		f.webhooks <- err
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(hook.Close)
+
+	f.provider = httptest.NewServer(payment.NewFakeServer("", hook.URL, testWebhookSecret))
+	t.Cleanup(f.provider.Close)
+
+	f.service = NewPaymentService(
+		repository.NewPaymentRepository(pool),
+		repository.NewWalletRepository(pool),
+		repository.NewUserRepository(pool),
+		payment.NewYooKassaProvider(f.provider.URL+"/v3", "shop", "key"),
+		testWebhookSecret, "http://localhost/wallet", 15000,
+	)
+	return f
+}
+
+// checkout нажимает кнопку на странице оплаты заглушки и ждёт, пока
+// сервис обработает webhook.
+func (f *paymentFixture) checkout(providerPaymentID, action string) error {
+	f.t.Helper()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.PostForm(f.provider.URL+"/checkout/"+providerPaymentID, url.Values{"action": {action}})
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	resp.Body.Close()
+	return <-f.webhooks
+}
+
+func (f *paymentFixture) balance(userID int) int {
+	f.t.Helper()
+	wallet, _, err := f.service.GetWallet(context.Background(), userID)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return wallet.Balance
+}
+
+func TestPaymentCheckoutCreditsWalletOnce(t *testing.T) {
+	f := newPaymentFixture(t)
+	user := createTestUser(t, f.pool, "payer")
+	ctx := context.Background()
+
+	p, err := f.service.CreateCheckout(ctx, user.ID, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Amount != 45000 || p.ProviderPaymentID == "" || p.ConfirmationURL == "" {
+		t.Fatalf("checkout = %+v", p)
+	}
+
+	if err := f.checkout(p.ProviderPaymentID, "pay"); err != nil {
+		t.Fatalf("webhook: %v", err)
+	}
+	if got := f.balance(user.ID); got != 3 {
+		t.Fatalf("balance = %d, want 3", got)
+	}
+
+	// Повторные уведомления и ручная сверка не зачисляют кредиты ещё раз.
+	if err := f.service.Reconcile(ctx, p.ProviderPaymentID); err != nil {
+		t.Fatal(err)
+	}
+	body, _ := json.Marshal(payment.WebhookEvent{Event: "payment.succeeded", Object: payment.Payment{ID: p.ProviderPaymentID}})
+	if err := f.service.HandleWebhook(ctx, body, payment.Sign(body, testWebhookSecret)); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.balance(user.ID); got != 3 {
+		t.Fatalf("balance after duplicate webhook = %d, want 3", got)
+	}
+
+	payments, err := f.service.GetPayments(ctx, user.ID)
+	if err != nil || len(payments) != 1 || payments[0].Status != models.PaymentStatusSucceeded {
+		t.Fatalf("payments = %+v, %v", payments, err)
+	}
+}
+
+func TestPaymentCheckoutCanceled(t *testing.T) {
+	f := newPaymentFixture(t)
+	user := createTestUser(t, f.pool, "payer")
+	ctx := context.Background()
+
+	p, err := f.service.CreateCheckout(ctx, user.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.checkout(p.ProviderPaymentID, "cancel"); err != nil {
+		t.Fatalf("webhook: %v", err)
+	}
+	if got := f.balance(user.ID); got != 0 {
+		t.Fatalf("balance = %d, want 0", got)
+	}
+	payments, err := f.service.GetPayments(ctx, user.ID)
+	if err != nil || len(payments) != 1 || payments[0].Status != models.PaymentStatusCanceled {
+		t.Fatalf("payments = %+v, %v", payments, err)
+	}
+}
+
+func TestPaymentWebhookRejectsForgedNotifications(t *testing.T) {
+	f := newPaymentFixture(t)
+	user := createTestUser(t, f.pool, "payer")
+	ctx := context.Background()
+
+	p, err := f.service.CreateCheckout(ctx, user.ID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Подпись чужим секретом отклоняется ещё до обращения к базе.
+	body, _ := json.Marshal(payment.WebhookEvent{Event: "payment.succeeded", Object: payment.Payment{ID: p.ProviderPaymentID, Status: payment.StatusSucceeded}})
+	if err := f.service.HandleWebhook(ctx, body, payment.Sign(body, "forged")); !errors.Is(err, payment.ErrInvalidSignature) {
+		t.Fatalf("forged signature: err = %v, want ErrInvalidSignature", err)
+	}
+
+	// Корректно подписанное уведомление о неоплаченном платеже ничего не
+	// зачисляет: статус берётся у провайдера, а не из тела запроса.
+	if err := f.service.HandleWebhook(ctx, body, payment.Sign(body, testWebhookSecret)); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.balance(user.ID); got != 0 {
+		t.Fatalf("balance = %d, want 0", got)
+	}
+
+	unknown, _ := json.Marshal(payment.WebhookEvent{Event: "payment.succeeded", Object: payment.Payment{ID: "fake-unknown"}})
+	if err := f.service.HandleWebhook(ctx, unknown, payment.Sign(unknown, testWebhookSecret)); !errors.Is(err, ErrPaymentNotFound) {
+		t.Fatalf("unknown payment: err = %v, want ErrPaymentNotFound", err)
+	}
+}
+
+func TestCreateCheckoutDomainErrors(t *testing.T) {
+	f := newPaymentFixture(t)
+	ctx := context.Background()
+
+	if _, err := f.service.CreateCheckout(ctx, 1, 0); !errors.Is(err, ErrInvalidCredits) {
+		t.Fatalf("0 credits: err = %v, want ErrInvalidCredits", err)
+	}
+	if _, err := f.service.CreateCheckout(ctx, 999999, 1); !errors.Is(err, ErrPaymentUserNotFound) {
+		t.Fatalf("unknown user: err = %v, want ErrPaymentUserNotFound", err)
+	}
+
+	user := createTestUser(t, f.pool, "payer")
+	f.provider.Close()
+	if _, err := f.service.CreateCheckout(ctx, user.ID, 1); !errors.Is(err, ErrPaymentProvider) {
+		t.Fatalf("provider down: err = %v, want ErrPaymentProvider", err)
+	}
+}
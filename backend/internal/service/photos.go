@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path"
+
+	"netiwash/pkg/storage"
+	"netiwash/pkg/utils"
+)
+
+const maxPhotoSize = 5 << 20
+
+var ErrUnsupportedPhoto = errors.New("photo must be a JPEG, PNG or WebP image up to 5 MB")
+
+var photoExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// storePhoto проверяет, что data — картинка допустимого формата и размера,
+// и сохраняет её в store под случайным именем в каталоге dir.
+func storePhoto(ctx context.Context, store storage.Storage, dir string, data []byte) (key, contentType string, err error) {
+	if len(data) > maxPhotoSize {
+		return "", "", ErrUnsupportedPhoto
+	}
+	contentType = http.DetectContentType(data)
+	ext, ok := photoExtensions[contentType]
+	if !ok {
+		return "", "", ErrUnsupportedPhoto
+	}
+
+	name, err := utils.GenerateSecureToken()
+	if err != nil {
+		return "", "", err
+	}
+	key = path.Join(dir, name[:32]+ext)
+
+	if err := store.Put(ctx, key, data, contentType); err != nil {
+		return "", "", err
+	}
+	return key, contentType, nil
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+
+	"netiwash/internal/models"
+)
+
+var ErrPushDeviceNotFound = errors.New("push device not found")
+
+// Unsubscribe удаляет подписку текущего браузера. Повторный вызов не ошибка.
+func (s *NotificationService) Unsubscribe(ctx context.Context, userID int, endpoint string) error {
+	deleted, err := s.repo.DeleteByEndpoint(ctx, userID, endpoint)
+	if err != nil {
+		return err
+	}
+	if deleted {
+		log.Printf("[PUSH] User %d unsubscribed a device", userID)
+	}
+	return nil
+}
+
+// ListDevices возвращает подписки пользователя без ключей и полного endpoint.
+func (s *NotificationService) ListDevices(ctx context.Context, userID int) ([]models.PushDevice, error) {
+	subs, err := s.repo.GetSubscriptionsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	devices := make([]models.PushDevice, 0, len(subs))
+	for _, sub := range subs {
+		d := models.PushDevice{
+			ID:            sub.ID,
+			UserAgent:     sub.UserAgent,
+			CreatedAt:     sub.CreatedAt,
+			LastSuccessAt: sub.LastSuccessAt,
+		}
+		if u, err := url.Parse(sub.Endpoint); err == nil {
+			d.Service = u.Host
+		}
+		devices = append(devices, d)
+	}
+	return devices, nil
+}
+
+// RevokeDevice удаляет подписку id, если она принадлежит userID. Этим же
+// методом админ отзывает устройство конкретного пользователя.
+func (s *NotificationService) RevokeDevice(ctx context.Context, userID, id int) error {
+	deleted, err := s.repo.DeleteByID(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrPushDeviceNotFound
+	}
+	log.Printf("[PUSH] Device %d of user %d revoked", id, userID)
+	return nil
+}
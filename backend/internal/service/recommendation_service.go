@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"netiwash/internal/models"
+	"netiwash/internal/repository"
+	"netiwash/pkg/utils"
+)
+
+const (
+	occupancyWindowWeeks = 8
+	recommendationDays   = 7
+)
+
+var ErrBookingLimitReached = errors.New("booking limit reached")
+
+type RecommendationService struct {
+	bookingRepo *repository.BookingRepository
+	machineRepo *repository.MachineRepository
+}
+
+func NewRecommendationService(bookingRepo *repository.BookingRepository, machineRepo *repository.MachineRepository) *RecommendationService {
+	return &RecommendationService{
+		bookingRepo: bookingRepo,
+		machineRepo: machineRepo,
+	}
+}
+
+type occupancyKey struct {
+	room    string
+	weekday int
+	hour    int
+}
+
+// GetOccupancy возвращает загрузку каждой комнаты по дням недели и часам
+// за последние occupancyWindowWeeks недель. Процент считается от числа
+// машин в комнате, умноженного на число недель в окне.
+func (s *RecommendationService) GetOccupancy(ctx context.Context) ([]models.OccupancyCell, error) {
+	occupancy, err := s.occupancyMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cells := make([]models.OccupancyCell, 0, len(occupancy))
+	for _, c := range occupancy {
+		cells = append(cells, c)
+	}
+	sort.Slice(cells, func(i, j int) bool {
+		if cells[i].Room != cells[j].Room {
+			return cells[i].Room < cells[j].Room
+		}
+		if cells[i].Weekday != cells[j].Weekday {
+			return cells[i].Weekday < cells[j].Weekday
+		}
+		return cells[i].Hour < cells[j].Hour
+	})
+	return cells, nil
+}
+
+// Recommend подбирает limit ближайших свободных слотов с наименьшей
+// исторической загрузкой. Учитываются лимит активных броней пользователя
+// и его собственные брони, пересекающиеся со слотом.
+func (s *RecommendationService) Recommend(ctx context.Context, userID int, room, machineType string, limit int) ([]models.SlotRecommendation, error) {
+	activeCount, err := s.bookingRepo.CountActiveBookingsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if activeCount >= maxActiveBookings {
+		return nil, ErrBookingLimitReached
+	}
+
+	machines, err := s.machineRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []models.Machine
+	for _, m := range machines {
+		if m.Status == "repair" {
+			continue
+		}
+		if room != "" && m.Room != room {
+			continue
+		}
+		if machineType != "" && m.Type != machineType {
+			continue
+		}
+		candidates = append(candidates, m)
+	}
+	if len(candidates) == 0 {
+		return []models.SlotRecommendation{}, nil
+	}
+
+	occupancy, err := s.occupancyMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().In(utils.LaundryLocation())
+	from := now.Truncate(time.Hour).Add(time.Hour)
+	to := from.Add(recommendationDays * 24 * time.Hour)
+
+	active, err := s.bookingRepo.GetActiveInRange(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	busy := make(map[int][]models.Booking)
+	var own []models.Booking
+	for _, b := range active {
+		b.StartTime = utils.InLaundryLocation(b.StartTime)
+		b.EndTime = utils.InLaundryLocation(b.EndTime)
+		busy[b.MachineID] = append(busy[b.MachineID], b)
+		if b.UserID == userID {
+			own = append(own, b)
+		}
+	}
+
+	var slots []models.SlotRecommendation
+	for start := from; start.Before(to); start = start.Add(bookingDuration) {
+		end := start.Add(bookingDuration)
+		if overlapsAny(own, start, end) {
+			continue
+		}
+		seenRooms := make(map[string]bool)
+		for _, m := range candidates {
+			if seenRooms[m.Room] || overlapsAny(busy[m.ID], start, end) {
+				continue
+			}
+			seenRooms[m.Room] = true
+			slots = append(slots, models.SlotRecommendation{
+				MachineID:   m.ID,
+				MachineName: m.Name,
+				Room:        m.Room,
+				StartTime:   start,
+				EndTime:     end,
+				Occupancy:   occupancy[occupancyKey{m.Room, isoWeekday(start), start.Hour()}].Occupancy,
+			})
+		}
+	}
+
+	sort.SliceStable(slots, func(i, j int) bool {
+		return slots[i].Occupancy < slots[j].Occupancy
+	})
+	if len(slots) > limit {
+		slots = slots[:limit]
+	}
+	return slots, nil
+}
+
+func (s *RecommendationService) occupancyMap(ctx context.Context) (map[occupancyKey]models.OccupancyCell, error) {
+	machines, err := s.machineRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	machinesPerRoom := make(map[string]int)
+	for _, m := range machines {
+		machinesPerRoom[m.Room]++
+	}
+
+	now := time.Now().In(utils.LaundryLocation())
+	since := now.AddDate(0, 0, -7*occupancyWindowWeeks)
+	counts, err := s.bookingRepo.GetHourlyCounts(ctx, since, now)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[occupancyKey]models.OccupancyCell)
+	for room := range machinesPerRoom {
+		for weekday := 1; weekday <= 7; weekday++ {
+			for hour := 0; hour < 24; hour++ {
+				result[occupancyKey{room, weekday, hour}] = models.OccupancyCell{
+					Room:    room,
+					Weekday: weekday,
+					Hour:    hour,
+				}
+			}
+		}
+	}
+	for _, c := range counts {
+		n := machinesPerRoom[c.Room]
+		if n == 0 {
+			continue
+		}
+		c.Occupancy = float64(c.Bookings) * 100 / float64(n*occupancyWindowWeeks)
+		if c.Occupancy > 100 {
+			c.Occupancy = 100
+		}
+		result[occupancyKey{c.Room, c.Weekday, c.Hour}] = c
+	}
+	return result, nil
+}
+
+func overlapsAny(bookings []models.Booking, start, end time.Time) bool {
+	for _, b := range bookings {
+		if b.StartTime.Before(end) && b.EndTime.After(start) {
+			return true
+		}
+	}
+	return false
+}
+
+func isoWeekday(t time.Time) int {
+	if t.Weekday() == time.Sunday {
+		return 7
+	}
+	return int(t.Weekday())
+}
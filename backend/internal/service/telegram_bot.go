@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"netiwash/internal/models"
+	"netiwash/pkg/telegram"
+	"netiwash/pkg/utils"
+)
+
+var liveStatusNames = map[string]string{
+	models.LiveStatusFree:           "свободна",
+	models.LiveStatusReserved:       "забронирована",
+	models.LiveStatusInUse:          "идёт стирка",
+	models.LiveStatusAwaitingPickup: "ждёт, когда заберут бельё",
+	models.LiveStatusRepair:         "в ремонте",
+}
+
+const telegramHelp = `Команды:
+/machines — машины и их статус
+/mybookings — мои брони
+/book <номер машины> <ЧЧ:ММ> [ДД.ММ] — забронировать час
+/cancel <номер брони> — отменить бронь
+
+Чтобы получать уведомления, привяжите аккаунт: в профиле NETI WASH нажмите «Привязать Telegram» и отправьте сюда /start <код>.`
+
+// handleMessage разбирает команду боту. Список машин доступен всем,
+// остальные команды — только после привязки аккаунта.
+func (s *TelegramService) handleMessage(ctx context.Context, msg *telegram.Message) {
+	fields := strings.Fields(msg.Text)
+	if len(fields) == 0 {
+		return
+	}
+	// В группах команда приходит как /book@netiwash_bot.
+	cmd, _, _ := strings.Cut(strings.ToLower(fields[0]), "@")
+	args := fields[1:]
+	chatID := msg.Chat.ID
+
+	switch cmd {
+	case "/start", "/link":
+		if len(args) == 0 {
+			s.reply(ctx, chatID, "Привет! Я бот прачечной NETI WASH.\n\n"+telegramHelp)
+			return
+		}
+		s.link(ctx, msg, args[0])
+		return
+	case "/help":
+		s.reply(ctx, chatID, telegramHelp)
+		return
+	case "/machines":
+		s.reply(ctx, chatID, s.machinesText(ctx))
+		return
+	}
+
+	account, err := s.repo.GetByChatID(ctx, chatID)
+	if err != nil {
+		log.Printf("✈️ [TELEGRAM] Failed to load account for chat %d: %v", chatID, err)
+		s.reply(ctx, chatID, "Что-то пошло не так, попробуйте позже.")
+		return
+	}
+
+	switch cmd {
+	case "/mybookings", "/book", "/cancel":
+		if account == nil {
+			s.reply(ctx, chatID, "Сначала привяжите аккаунт: в профиле NETI WASH нажмите «Привязать Telegram» и отправьте сюда /start <код>.")
+			return
+		}
+	default:
+		s.reply(ctx, chatID, "Не знаю такой команды.\n\n"+telegramHelp)
+		return
+	}
+
+	var text string
+	switch cmd {
+	case "/mybookings":
+		text = s.myBookingsText(ctx, account.UserID)
+	case "/book":
+		text = s.book(ctx, account.UserID, args)
+	case "/cancel":
+		text = s.cancel(ctx, account.UserID, args)
+	}
+	s.reply(ctx, chatID, text)
+}
+
+func (s *TelegramService) link(ctx context.Context, msg *telegram.Message, code string) {
+	username := ""
+	if msg.From != nil {
+		username = msg.From.Username
+	}
+
+	account, err := s.repo.Link(ctx, strings.ToUpper(code), time.Now().In(utils.LaundryLocation()), msg.Chat.ID, username)
+	if err != nil {
+		log.Printf("✈️ [TELEGRAM] Link failed for chat %d: %v", msg.Chat.ID, err)
+		s.reply(ctx, msg.Chat.ID, "Что-то пошло не так, попробуйте позже.")
+		return
+	}
+	if account == nil {
+		s.reply(ctx, msg.Chat.ID, "Код не найден или истёк. Получите новый в профиле NETI WASH.")
+		return
+	}
+
+	log.Printf("✈️ [TELEGRAM] User %d linked chat %d", account.UserID, account.ChatID)
+	s.reply(ctx, msg.Chat.ID, "Готово! Аккаунт привязан, уведомления будут приходить сюда. Выбрать, какие именно, можно в настройках уведомлений.\n\n"+telegramHelp)
+}
+
+func (s *TelegramService) machinesText(ctx context.Context) string {
+	machines, err := s.machineService.GetLive(ctx)
+	if err != nil {
+		log.Printf("✈️ [TELEGRAM] Failed to load machines: %v", err)
+		return "Не удалось загрузить список машин, попробуйте позже."
+	}
+	if len(machines) == 0 {
+		return "Машин пока нет."
+	}
+
+	var b strings.Builder
+	for _, m := range machines {
+		fmt.Fprintf(&b, "#%d %s — %s", m.ID, m.Name, liveStatusNames[m.Status])
+		if m.NextFreeAt != nil {
+			fmt.Fprintf(&b, ", освободится в %s", utils.InLaundryLocation(*m.NextFreeAt).Format("15:04"))
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func (s *TelegramService) myBookingsText(ctx context.Context, userID int) string {
+	bookings, err := s.bookingService.GetAll(ctx, false, userID)
+	if err != nil {
+		log.Printf("✈️ [TELEGRAM] Failed to load bookings of user %d: %v", userID, err)
+		return "Не удалось загрузить брони, попробуйте позже."
+	}
+
+	var active []models.Booking
+	for _, b := range bookings {
+		if b.Status == "active" {
+			active = append(active, b)
+		}
+	}
+	if len(active) == 0 {
+		return "Активных броней нет. Забронировать: /book <номер машины> <ЧЧ:ММ>"
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].StartTime.Before(active[j].StartTime) })
+
+	var b strings.Builder
+	for _, bk := range active {
+		start := utils.InLaundryLocation(bk.StartTime)
+		end := utils.InLaundryLocation(bk.EndTime)
+		fmt.Fprintf(&b, "Бронь #%d: машина #%d, %s–%s\n", bk.ID, bk.MachineID, start.Format("02.01 15:04"), end.Format("15:04"))
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func (s *TelegramService) book(ctx context.Context, userID int, args []string) string {
+	const usage = "Формат: /book <номер машины> <ЧЧ:ММ> [ДД.ММ], например /book 2 19:00"
+	if len(args) < 2 || len(args) > 3 {
+		return usage
+	}
+	machineID, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+	if err != nil {
+		return usage
+	}
+
+	date := ""
+	if len(args) == 3 {
+		date = args[2]
+	}
+	start, err := bookingStart(time.Now().In(utils.LaundryLocation()), args[1], date)
+	if err != nil {
+		return usage
+	}
+
+	booking, err := s.bookingService.Create(ctx, userID, machineID, start)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrMachineNotFound):
+			return "Машина не найдена. Список: /machines"
+		case errors.Is(err, ErrTimeSlotBusy):
+			return "Это время уже занято."
+		case errors.Is(err, ErrBookingInPast):
+			return "Нельзя забронировать время в прошлом."
+		case errors.Is(err, ErrTooManyBookings):
+			return "Не получилось: " + err.Error() + "."
+		}
+		log.Printf("✈️ [TELEGRAM] Booking failed for user %d: %v", userID, err)
+		return "Не удалось забронировать, попробуйте позже."
+	}
+
+	end := utils.InLaundryLocation(booking.EndTime)
+	return fmt.Sprintf("Забронировано: бронь #%d, машина #%d, %s–%s.", booking.ID, machineID, start.Format("02.01 15:04"), end.Format("15:04"))
+}
+
+// bookingStart собирает начало брони из ЧЧ:ММ и необязательной даты ДД.ММ.
+// Без даты — сегодня; дата, которая в этом году уже прошла, относится к
+// следующему: 02.01, набранное 30 декабря, — это январь.
+func bookingStart(now time.Time, clockArg, dateArg string) (time.Time, error) {
+	clock, err := time.Parse("15:04", clockArg)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc := now.Location()
+	year, month, day := now.Date()
+	if dateArg != "" {
+		date, err := time.Parse("02.01", dateArg)
+		if err != nil {
+			return time.Time{}, err
+		}
+		today := time.Date(year, month, day, 0, 0, 0, 0, loc)
+		month, day = date.Month(), date.Day()
+		if time.Date(year, month, day, 0, 0, 0, 0, loc).Before(today) {
+			year++
+		}
+	}
+	return time.Date(year, month, day, clock.Hour(), clock.Minute(), 0, 0, loc), nil
+}
+
+func (s *TelegramService) cancel(ctx context.Context, userID int, args []string) string {
+	if len(args) != 1 {
+		return "Формат: /cancel <номер брони>. Номера броней: /mybookings"
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+	if err != nil {
+		return "Формат: /cancel <номер брони>. Номера броней: /mybookings"
+	}
+
+	b, err := s.bookingService.GetByID(ctx, id)
+	if err != nil && !errors.Is(err, ErrBookingNotFound) {
+		log.Printf("✈️ [TELEGRAM] Cancel failed for booking %d: %v", id, err)
+		return "Не удалось отменить бронь, попробуйте позже."
+	}
+	if err != nil || b.UserID != userID || b.Status != "active" {
+		return "Активная бронь с таким номером не найдена. Ваши брони: /mybookings"
+	}
+	if err := s.bookingService.Cancel(ctx, id, userID, false); err != nil {
+		log.Printf("✈️ [TELEGRAM] Cancel failed for booking %d: %v", id, err)
+		return "Не удалось отменить бронь, попробуйте позже."
+	}
+	return fmt.Sprintf("Бронь #%d отменена.", id)
+}
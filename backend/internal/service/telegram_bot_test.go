@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"netiwash/internal/repository"
+	"netiwash/pkg/telegram"
+	"netiwash/pkg/utils"
+)
+
+func TestBookingStart(t *testing.T) {
+	loc := utils.LaundryLocation()
+	now := time.Date(2026, 12, 30, 21, 15, 0, 0, loc)
+
+	tests := []struct {
+		clock, date string
+		want        time.Time
+	}{
+		{"22:00", "", time.Date(2026, 12, 30, 22, 0, 0, 0, loc)},
+		{"09:00", "30.12", time.Date(2026, 12, 30, 9, 0, 0, 0, loc)},
+		{"10:30", "31.12", time.Date(2026, 12, 31, 10, 30, 0, 0, loc)},
+		{"10:30", "02.01", time.Date(2027, 1, 2, 10, 30, 0, 0, loc)},
+		{"08:00", "29.12", time.Date(2027, 12, 29, 8, 0, 0, 0, loc)},
+	}
+	for _, tt := range tests {
+		got, err := bookingStart(now, tt.clock, tt.date)
+		if err != nil {
+			t.Fatalf("bookingStart(%q, %q): %v", tt.clock, tt.date, err)
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("bookingStart(%q, %q) = %s, want %s", tt.clock, tt.date, got, tt.want)
+		}
+	}
+
+	for _, args := range [][2]string{{"25:00", ""}, {"19:00", "32.01"}, {"7pm", ""}, {"19:00", "2027-01-02"}} {
+		if _, err := bookingStart(now, args[0], args[1]); err == nil {
+			t.Errorf("bookingStart(%q, %q) should fail", args[0], args[1])
+		}
+	}
+}
+
+// telegramChat — переписка с ботом через локальную заглушку Bot API.
+type telegramChat struct {
+	t      *testing.T
+	fake   *telegram.FakeServer
+	chatID int64
+}
+
+// send пишет боту и ждёт его ответ.
+func (c *telegramChat) send(text string) string {
+	c.t.Helper()
+	before := len(c.fake.Sent(c.chatID))
+	c.fake.Inject(c.chatID, "resident", text)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		sent := c.fake.Sent(c.chatID)
+		if len(sent) > before {
+			return sent[len(sent)-1].Text
+		}
+		if time.Now().After(deadline) {
+			c.t.Fatalf("no reply to %q", text)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func (c *telegramChat) expect(text, want string) string {
+	c.t.Helper()
+	reply := c.send(text)
+	if !strings.Contains(reply, want) {
+		c.t.Fatalf("reply to %q = %q, want it to contain %q", text, reply, want)
+	}
+	return reply
+}
+
+func TestTelegramBotCommands(t *testing.T) {
+	pool := testDB(t)
+	user := createTestUser(t, pool, "resident")
+	machine := createTestMachine(t, pool, "Машинка #1")
+
+	const token = "123:test"
+	fake := telegram.NewFakeServer(token)
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	machineRepo := repository.NewMachineRepository(pool)
+	bookingRepo := repository.NewBookingRepository(pool)
+	machineService := NewMachineService(machineRepo, bookingRepo, nil, nopPublisher{})
+	bookingService := NewBookingService(bookingRepo, machineRepo, machineService, nopPublisher{})
+	bot := NewTelegramService(telegram.NewClient(srv.URL, token), repository.NewTelegramRepository(pool), machineService, bookingService, "netiwash_bot")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		bot.RunPolling(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	chat := &telegramChat{t: t, fake: fake, chatID: 4242}
+
+	// До привязки доступен только список машин.
+	chat.expect("/mybookings", "Сначала привяжите аккаунт")
+	chat.expect("/machines", fmt.Sprintf("#%d Машинка #1", machine.ID))
+
+	// /link
+	chat.expect("/link WRONGCODE", "Код не найден или истёк")
+	code, err := bot.CreateLinkCode(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chat.expect("/link "+strings.ToLower(code.Code), "Аккаунт привязан")
+	account, err := bot.GetAccount(context.Background(), user.ID)
+	if err != nil || account == nil || account.ChatID != chat.chatID {
+		t.Fatalf("account after /link = %+v, %v", account, err)
+	}
+	chat.expect("/link "+code.Code, "Код не найден или истёк")
+
+	// /book
+	chat.expect("/mybookings", "Активных броней нет")
+	chat.expect("/book", "Формат: /book")
+	chat.expect("/book 1 19", "Формат: /book")
+
+	tomorrow := time.Now().In(utils.LaundryLocation()).AddDate(0, 0, 1)
+	bookArgs := fmt.Sprintf("%d 10:00 %s", machine.ID, tomorrow.Format("02.01"))
+	chat.expect("/book "+bookArgs, "Забронировано: бронь #")
+	chat.expect("/book "+bookArgs, "Это время уже занято.")
+	chat.expect("/book 999999 10:00 "+tomorrow.Format("02.01"), "Машина не найдена")
+
+	bookings, err := bookingRepo.GetByUserID(context.Background(), user.ID)
+	if err != nil || len(bookings) != 1 {
+		t.Fatalf("bookings after /book = %v, %v", bookings, err)
+	}
+	booking := bookings[0]
+	if start := utils.InLaundryLocation(booking.StartTime); start.Format("02.01 15:04") != tomorrow.Format("02.01")+" 10:00" {
+		t.Fatalf("booking starts at %s", start)
+	}
+
+	// /mybookings
+	chat.expect("/mybookings", fmt.Sprintf("Бронь #%d: машина #%d, %s 10:00–11:00", booking.ID, machine.ID, tomorrow.Format("02.01")))
+
+	// /cancel
+	chat.expect("/cancel", "Формат: /cancel")
+	other := createTestUser(t, pool, "neighbour")
+	otherBooking, err := bookingService.Create(context.Background(), other.ID, machine.ID, utils.InLaundryLocation(booking.StartTime).Add(2*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chat.expect(fmt.Sprintf("/cancel %d", otherBooking.ID), "Активная бронь с таким номером не найдена")
+	chat.expect(fmt.Sprintf("/cancel #%d", booking.ID), fmt.Sprintf("Бронь #%d отменена.", booking.ID))
+	chat.expect(fmt.Sprintf("/cancel %d", booking.ID), "Активная бронь с таким номером не найдена")
+	chat.expect("/mybookings", "Активных броней нет")
+
+	stillActive, err := bookingRepo.GetByID(context.Background(), otherBooking.ID)
+	if err != nil || stillActive == nil || stillActive.Status != "active" {
+		t.Fatalf("neighbour's booking = %+v, %v", stillActive, err)
+	}
+}
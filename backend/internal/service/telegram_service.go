@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"netiwash/internal/models"
+	"netiwash/internal/repository"
+	"netiwash/pkg/telegram"
+	"netiwash/pkg/utils"
+)
+
+var ErrTelegramDisabled = errors.New("telegram bot is not configured")
+
+const (
+	telegramCodeTTL      = 15 * time.Minute
+	telegramPollTimeout  = 50 // секунд, long polling getUpdates
+	telegramRetryDelay   = 5 * time.Second
+	telegramCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	telegramCodeLength   = 8
+)
+
+// TelegramService привязывает аккаунты к чатам с ботом, доставляет через
+// бота уведомления и отвечает на команды.
+type TelegramService struct {
+	client         *telegram.Client
+	repo           *repository.TelegramRepository
+	machineService *MachineService
+	bookingService *BookingService
+	botUsername    string
+}
+
+// NewTelegramService: client == nil — бот не настроен, привязка недоступна.
+func NewTelegramService(client *telegram.Client, repo *repository.TelegramRepository, machineService *MachineService, bookingService *BookingService, botUsername string) *TelegramService {
+	return &TelegramService{
+		client:         client,
+		repo:           repo,
+		machineService: machineService,
+		bookingService: bookingService,
+		botUsername:    botUsername,
+	}
+}
+
+// CreateLinkCode выдаёт одноразовый код, который пользователь отправляет
+// боту командой /start <код>.
+func (s *TelegramService) CreateLinkCode(ctx context.Context, userID int) (*models.TelegramLinkCode, error) {
+	if s.client == nil {
+		return nil, ErrTelegramDisabled
+	}
+
+	code, err := generateLinkCode()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().In(utils.LaundryLocation())
+	expiresAt := now.Add(telegramCodeTTL)
+	if err := s.repo.CreateLinkCode(ctx, userID, code, now, expiresAt); err != nil {
+		return nil, err
+	}
+
+	link := &models.TelegramLinkCode{Code: code, ExpiresAt: expiresAt}
+	if s.botUsername != "" {
+		link.DeepLink = fmt.Sprintf("https://t.me/%s?start=%s", s.botUsername, code)
+	}
+	return link, nil
+}
+
+// GetAccount возвращает привязку пользователя или nil.
+func (s *TelegramService) GetAccount(ctx context.Context, userID int) (*models.TelegramAccount, error) {
+	return s.repo.GetByUserID(ctx, userID)
+}
+
+func (s *TelegramService) Unlink(ctx context.Context, userID int) error {
+	return s.repo.Unlink(ctx, userID)
+}
+
+// Send — канал уведомлений. Пользователь без привязки пропускается; если
+// он заблокировал бота, привязка снимается.
+func (s *TelegramService) Send(ctx context.Context, userID int, message string) error {
+	if s.client == nil {
+		return nil
+	}
+	account, err := s.repo.GetByUserID(ctx, userID)
+	if err != nil || account == nil {
+		return err
+	}
+
+	err = s.client.SendMessage(ctx, account.ChatID, message)
+	var apiErr *telegram.APIError
+	if errors.As(err, &apiErr) && apiErr.Code == 403 {
+		log.Printf("✈️ [TELEGRAM] User %d blocked the bot, unlinking", userID)
+		return s.repo.UnlinkChat(ctx, account.ChatID)
+	}
+	return err
+}
+
+// RunPolling получает сообщения боту через long polling getUpdates, пока
+// ctx не отменён.
+func (s *TelegramService) RunPolling(ctx context.Context) {
+	if s.client == nil {
+		return
+	}
+
+	log.Println("✈️ [TELEGRAM] Bot polling started")
+	var offset int64
+	for {
+		updates, err := s.client.GetUpdates(ctx, offset, telegramPollTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("✈️ [TELEGRAM] getUpdates failed: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(telegramRetryDelay):
+			}
+			continue
+		}
+
+		for _, u := range updates {
+			offset = u.UpdateID + 1
+			if u.Message != nil && u.Message.Text != "" {
+				s.handleMessage(ctx, u.Message)
+			}
+		}
+	}
+}
+
+func (s *TelegramService) reply(ctx context.Context, chatID int64, text string) {
+	if err := s.client.SendMessage(ctx, chatID, text); err != nil {
+		log.Printf("✈️ [TELEGRAM] Reply to chat %d failed: %v", chatID, err)
+	}
+}
+
+func generateLinkCode() (string, error) {
+	buf := make([]byte, telegramCodeLength)
+	max := big.NewInt(int64(len(telegramCodeAlphabet)))
+	for i := range buf {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate link code: %w", err)
+		}
+		buf[i] = telegramCodeAlphabet[n.Int64()]
+	}
+	return string(buf), nil
+}
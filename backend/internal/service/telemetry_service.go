@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"netiwash/internal/models"
+	"netiwash/internal/repository"
+	"netiwash/pkg/utils"
+)
+
+// TelemetryService реагирует на циклы стирки, обнаруженные по телеметрии
+// или присланные контроллерами: обновляет статус машины и передаёт
+// завершение в NotificationService. source попадает в историю статусов.
+type TelemetryService struct {
+	machineRepo         *repository.MachineRepository
+	machineService      *MachineService
+	notificationService *NotificationService
+	source              string
+}
+
+func NewTelemetryService(machineRepo *repository.MachineRepository, machineService *MachineService, notificationService *NotificationService, source string) *TelemetryService {
+	return &TelemetryService{
+		machineRepo:         machineRepo,
+		machineService:      machineService,
+		notificationService: notificationService,
+		source:              source,
+	}
+}
+
+func (s *TelemetryService) CycleStarted(ctx context.Context, machineID int, at time.Time) {
+	if err := s.machineRepo.SetRunning(ctx, machineID, true, at.In(utils.LaundryLocation())); err != nil {
+		log.Printf("📡 [TELEMETRY] %v", err)
+	}
+	s.syncStatus(ctx, machineID, models.MachineStatusFree, models.MachineStatusBusy, "Начат цикл стирки")
+	s.machineService.PublishState(ctx, machineID)
+}
+
+func (s *TelemetryService) CycleEnded(ctx context.Context, machineID int, startedAt, endedAt time.Time) {
+	if err := s.machineRepo.SetRunning(ctx, machineID, false, endedAt.In(utils.LaundryLocation())); err != nil {
+		log.Printf("📡 [TELEMETRY] %v", err)
+	}
+	s.syncStatus(ctx, machineID, models.MachineStatusBusy, models.MachineStatusFree, "Цикл стирки завершён")
+	s.notificationService.NotifyCycleCompleted(ctx, machineID, startedAt, endedAt)
+	s.machineService.PublishState(ctx, machineID)
+}
+
+// syncStatus меняет статус только из ожидаемого состояния: машину в ремонте
+// телеметрия не трогает.
+func (s *TelemetryService) syncStatus(ctx context.Context, machineID int, from, to, reason string) {
+	m, err := s.machineService.GetByID(ctx, machineID)
+	if err != nil {
+		log.Printf("📡 [TELEMETRY] Machine %d: %v", machineID, err)
+		return
+	}
+	if m.Status != from {
+		return
+	}
+	if _, err := s.machineService.ChangeStatus(ctx, machineID, to, reason, nil, s.source); err != nil {
+		log.Printf("📡 [TELEMETRY] Machine %d status change failed: %v", machineID, err)
+	}
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	"netiwash/internal/models"
+	"netiwash/internal/repository"
+	"netiwash/pkg/database"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var migrateOnce sync.Once
+
+// testDB подключается к тестовой базе из TEST_DATABASE_URL, накатывает схему
+// и очищает таблицы. Без переменной тест пропускается. Данные базы
+// удаляются, поэтому рабочую базу сюда указывать нельзя.
+func testDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	pool, err := database.ConnectDB(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+
+	var migrateErr error
+	migrateOnce.Do(func() { migrateErr = database.RunMigrations(pool) })
+	if migrateErr != nil {
+		t.Fatal(migrateErr)
+	}
+
+	_, err = pool.Exec(context.Background(), `
+		TRUNCATE users, machines, bookings, payments, wallets, wallet_transactions,
+			machine_photos, telegram_accounts, telegram_link_codes
+		RESTART IDENTITY CASCADE
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pool
+}
+
+type nopPublisher struct{}
+
+func (nopPublisher) Publish(context.Context, models.Event) {}
+
+// recordingPublisher запоминает опубликованные события.
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []models.Event
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, e models.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, e)
+}
+
+// bookingEvents возвращает виды событий из booking.status в порядке публикации
+// и очищает запись.
+func (p *recordingPublisher) bookingEvents() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var kinds []string
+	for _, e := range p.events {
+		if st, ok := e.Data.(models.BookingStatusEvent); ok && e.Type == models.EventBookingStatus {
+			kinds = append(kinds, st.Event)
+		}
+	}
+	p.events = nil
+	return kinds
+}
+
+func createTestUser(t *testing.T, pool *pgxpool.Pool, login string) *models.User {
+	t.Helper()
+	u := &models.User{
+		Email:         login + "@netiwash.test",
+		Login:         login,
+		PasswordHash:  "-",
+		Role:          "user",
+		EmailVerified: true,
+	}
+	if err := repository.NewUserRepository(pool).Create(context.Background(), u); err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func createTestMachine(t *testing.T, pool *pgxpool.Pool, name string) *models.Machine {
+	t.Helper()
+	m := &models.Machine{Name: name, Type: "washing", Status: models.MachineStatusFree, IsActive: true}
+	if err := repository.NewMachineRepository(pool).Create(context.Background(), m, nil); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
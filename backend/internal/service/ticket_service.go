@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"path"
+
+	"netiwash/internal/models"
+	"netiwash/internal/repository"
+	"netiwash/pkg/storage"
+)
+
+var (
+	ErrTicketNotFound          = errors.New("ticket not found")
+	ErrInvalidTicketTransition = errors.New("invalid ticket status transition")
+)
+
+var ticketCategoryNames = map[string]string{
+	"leak":         "протечка",
+	"not_spinning": "не отжимает",
+	"not_draining": "не сливает воду",
+	"door":         "не открывается дверца",
+	"power":        "не включается",
+	"noise":        "сильный шум",
+	"other":        "другое",
+}
+
+// ticketTransitions — рабочий процесс заявки: open → acknowledged → in_repair
+// → resolved. Ложные срабатывания можно закрыть сразу.
+var ticketTransitions = map[string][]string{
+	models.TicketStatusOpen:         {models.TicketStatusAcknowledged, models.TicketStatusInRepair, models.TicketStatusResolved},
+	models.TicketStatusAcknowledged: {models.TicketStatusInRepair, models.TicketStatusResolved},
+	models.TicketStatusInRepair:     {models.TicketStatusResolved},
+}
+
+type TicketService struct {
+	repo                *repository.TicketRepository
+	machineService      *MachineService
+	notificationService *NotificationService
+	storage             storage.Storage
+}
+
+func NewTicketService(repo *repository.TicketRepository, machineService *MachineService, notificationService *NotificationService, storage storage.Storage) *TicketService {
+	return &TicketService{
+		repo:                repo,
+		machineService:      machineService,
+		notificationService: notificationService,
+		storage:             storage,
+	}
+}
+
+// Report открывает заявку на ремонт от жильца. photo может быть nil.
+func (s *TicketService) Report(ctx context.Context, userID, machineID int, req *models.FaultReportRequest, photo []byte) (*models.RepairTicket, error) {
+	m, err := s.machineService.GetByID(ctx, machineID)
+	if err != nil {
+		return nil, err
+	}
+	if !m.IsActive {
+		return nil, ErrMachineNotFound
+	}
+
+	ticket := &models.RepairTicket{
+		MachineID:   machineID,
+		ReporterID:  &userID,
+		Category:    req.Category,
+		Description: req.Description,
+		Status:      models.TicketStatusOpen,
+	}
+
+	if len(photo) > 0 {
+		key, _, err := storePhoto(ctx, s.storage, "reports", photo)
+		if err != nil {
+			return nil, err
+		}
+		ticket.PhotoPath = key
+	}
+
+	if err := s.repo.Create(ctx, ticket); err != nil {
+		return nil, err
+	}
+
+	log.Printf("🛠️ [TICKET] Ticket %d opened for machine %d by user %d (%s)", ticket.ID, machineID, userID, req.Category)
+	return ticket, nil
+}
+
+// ReportDeviceError открывает заявку по ошибке, которую прислал контроллер
+// машины. Пока предыдущая такая заявка не закрыта, новые не создаются.
+func (s *TicketService) ReportDeviceError(ctx context.Context, machineID int, description string) (*models.RepairTicket, error) {
+	open, err := s.repo.CountUnresolvedWithoutReporter(ctx, machineID)
+	if err != nil {
+		return nil, err
+	}
+	if open > 0 {
+		return nil, nil
+	}
+
+	ticket := &models.RepairTicket{
+		MachineID:   machineID,
+		Category:    "other",
+		Description: description,
+		Status:      models.TicketStatusOpen,
+	}
+	if err := s.repo.Create(ctx, ticket); err != nil {
+		return nil, err
+	}
+
+	log.Printf("🛠️ [TICKET] Ticket %d opened for machine %d by device: %s", ticket.ID, machineID, description)
+	return ticket, nil
+}
+
+func (s *TicketService) List(ctx context.Context, status string, machineID int) ([]models.RepairTicket, error) {
+	return s.repo.List(ctx, status, machineID)
+}
+
+func (s *TicketService) GetByReporter(ctx context.Context, userID int) ([]models.RepairTicket, error) {
+	return s.repo.GetByReporter(ctx, userID)
+}
+
+func (s *TicketService) GetByID(ctx context.Context, id int) (*models.RepairTicket, error) {
+	t, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, ErrTicketNotFound
+	}
+	return t, nil
+}
+
+// UpdateStatus двигает заявку по рабочему процессу. Переход в in_repair
+// переводит машину в ремонт, закрытие последней заявки в ремонте
+// возвращает машину в free. Автор заявки получает уведомление о закрытии.
+func (s *TicketService) UpdateStatus(ctx context.Context, id, actorID int, req *models.UpdateTicketRequest) (*models.RepairTicket, error) {
+	t, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canTicketTransition(t.Status, req.Status) {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrInvalidTicketTransition, t.Status, req.Status)
+	}
+
+	m, err := s.machineService.GetByID(ctx, t.MachineID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Статус меняется условно: из двух админов, одновременно двигающих
+	// заявку, проходит один, второй получает конфликт.
+	if err := s.repo.UpdateStatus(ctx, id, t.Status, req.Status, req.Note); err != nil {
+		if errors.Is(err, repository.ErrTicketStatusChanged) {
+			return nil, fmt.Errorf("%w: ticket %d was changed by someone else", ErrInvalidTicketTransition, id)
+		}
+		return nil, err
+	}
+
+	if req.Status == models.TicketStatusInRepair && m.Status != models.MachineStatusRepair {
+		reason := fmt.Sprintf("Заявка #%d: %s", t.ID, ticketCategoryNames[t.Category])
+		if _, err := s.machineService.ChangeStatus(ctx, m.ID, models.MachineStatusRepair, reason, &actorID, models.StatusSourceAdmin); err != nil {
+			// Машину не удалось перевести в ремонт — возвращаем заявку назад.
+			if rerr := s.repo.UpdateStatus(ctx, id, req.Status, t.Status, ""); rerr != nil {
+				log.Printf("🛠️ [TICKET] Failed to revert ticket %d: %v", t.ID, rerr)
+			}
+			return nil, err
+		}
+	}
+
+	if req.Status == models.TicketStatusResolved {
+		// Жилец узнаёт, что машина снова работает, только если она и
+		// правда вернулась в строй: по ней не осталось других ремонтов и
+		// статус сменился.
+		released := false
+		if t.Status == models.TicketStatusInRepair && m.Status == models.MachineStatusRepair {
+			remaining, err := s.repo.CountInRepair(ctx, m.ID)
+			if err != nil {
+				return nil, err
+			}
+			if remaining == 0 {
+				reason := fmt.Sprintf("Заявка #%d закрыта", t.ID)
+				if _, err := s.machineService.ChangeStatus(ctx, m.ID, models.MachineStatusFree, reason, &actorID, models.StatusSourceAdmin); err != nil {
+					log.Printf("🛠️ [TICKET] Failed to release machine %d: %v", m.ID, err)
+				} else {
+					released = true
+				}
+			}
+		}
+
+		if released && t.ReporterID != nil {
+			s.notificationService.SendNotification(ctx, *t.ReporterID, models.Notification{
+				Kind:     models.NotificationKindSystem,
+				Template: tmplTicketResolved,
+				Params:   map[string]any{"Machine": m.Name, "TicketID": t.ID},
+				URL:      "main.html",
+				Tag:      fmt.Sprintf("ticket-%d", t.ID),
+			})
+		}
+	}
+
+	log.Printf("🛠️ [TICKET] Ticket %d: %s -> %s", t.ID, t.Status, req.Status)
+	return s.GetByID(ctx, id)
+}
+
+// OpenPhoto открывает фото заявки из хранилища; закрыть его должен вызывающий.
+func (s *TicketService) OpenPhoto(ctx context.Context, id int) (io.ReadCloser, string, error) {
+	t, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if t.PhotoPath == "" {
+		return nil, "", ErrTicketNotFound
+	}
+
+	f, err := s.storage.Get(ctx, t.PhotoPath)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, "", ErrTicketNotFound
+		}
+		return nil, "", err
+	}
+	return f, mime.TypeByExtension(path.Ext(t.PhotoPath)), nil
+}
+
+func canTicketTransition(from, to string) bool {
+	for _, s := range ticketTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"netiwash/internal/models"
+	"netiwash/pkg/utils"
+
+	"github.com/SherClockHolmes/webpush-go"
+)
+
+var (
+	ErrNoVAPIDKeys   = errors.New("no active VAPID key")
+	ErrStaleVAPIDKey = errors.New("subscription uses a rotated VAPID key")
+)
+
+// Пара ключей для локальной разработки. Приватный ключ лежит в открытом
+// репозитории, поэтому вне APP_ENV=development сервер её не принимает.
+const (
+	DevVAPIDPublicKey  = "BIV66_T0YefjdnM4JUXri7hD8m9cn_mRLcHYlEQJ5B4NcLo2UnPelrzJcbQd_6sTCHf9n0583IKwPzxdqqBFjM0"
+	DevVAPIDPrivateKey = "MCkShAz8ggYo3uq8j5wMHfmv0fSZgvLaRC0j0YaCaUk"
+)
+
+// vapidReloadInterval — как быстро экземпляры замечают новый ключ после
+// vapid_keys rotate.
+const vapidReloadInterval = time.Minute
+
+// vapidMissReloadInterval — как часто незнакомый ключ подписки может
+// заставить перечитать хранилище вне очереди.
+const vapidMissReloadInterval = 5 * time.Second
+
+// VAPIDKeyStore — где лежат ключи: таблица vapid_keys или JSON-файл.
+type VAPIDKeyStore interface {
+	List(ctx context.Context) ([]models.VAPIDKey, error)
+	// Add делает ключ активным, прежний активный выводит из оборота.
+	Add(ctx context.Context, key models.VAPIDKey) error
+	DeleteRetired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// VAPIDKeyring — ключи, которыми сервер подписывает пуши. Новые подписки
+// получают активный ключ; подписки на выведенный из оборота ключ
+// подписываются им же, пока браузер не переподпишется.
+type VAPIDKeyring struct {
+	store   VAPIDKeyStore // nil — ключ задан в окружении и не меняется
+	subject string
+
+	mu       sync.RWMutex
+	current  models.VAPIDKey
+	keys     map[string]models.VAPIDKey // по публичному ключу
+	loadedAt time.Time
+	missAt   time.Time // последнее внеочередное чтение из IsCurrent
+}
+
+// NewStaticVAPIDKeyring — один ключ из окружения, без ротации.
+func NewStaticVAPIDKeyring(subject, publicKey, privateKey string) *VAPIDKeyring {
+	key := models.VAPIDKey{PublicKey: publicKey, PrivateKey: privateKey, Active: true}
+	return &VAPIDKeyring{
+		subject: subject,
+		current: key,
+		keys:    map[string]models.VAPIDKey{publicKey: key},
+	}
+}
+
+// LoadVAPIDKeyring читает ключи из хранилища. ErrNoVAPIDKeys — активного
+// ключа нет, его нужно создать командой vapid_keys generate.
+func LoadVAPIDKeyring(ctx context.Context, store VAPIDKeyStore, subject string) (*VAPIDKeyring, error) {
+	k := &VAPIDKeyring{store: store, subject: subject}
+	if err := k.reload(ctx); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+func (k *VAPIDKeyring) reload(ctx context.Context) error {
+	keys, err := k.store.List(ctx)
+	if err != nil {
+		return err
+	}
+	byPublic := make(map[string]models.VAPIDKey, len(keys))
+	var current *models.VAPIDKey
+	for i := range keys {
+		byPublic[keys[i].PublicKey] = keys[i]
+		if keys[i].Active {
+			current = &keys[i]
+		}
+	}
+	if current == nil {
+		return ErrNoVAPIDKeys
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.current.PublicKey != "" && k.current.PublicKey != current.PublicKey {
+		log.Printf("[PUSH] VAPID key rotated, clients will resubscribe")
+	}
+	k.current = *current
+	k.keys = byPublic
+	k.loadedAt = time.Now()
+	return nil
+}
+
+// refresh перечитывает хранилище не чаще раза в vapidReloadInterval. При
+// ошибке остаются прежние ключи.
+func (k *VAPIDKeyring) refresh(ctx context.Context) {
+	if k.store == nil {
+		return
+	}
+	k.mu.RLock()
+	fresh := time.Since(k.loadedAt) < vapidReloadInterval
+	k.mu.RUnlock()
+	if fresh {
+		return
+	}
+	if err := k.reload(ctx); err != nil {
+		log.Printf("[PUSH] Failed to reload VAPID keys: %v", err)
+		k.mu.Lock()
+		k.loadedAt = time.Now()
+		k.mu.Unlock()
+	}
+}
+
+// Current возвращает активный ключ.
+func (k *VAPIDKeyring) Current(ctx context.Context) models.VAPIDKey {
+	k.refresh(ctx)
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.current
+}
+
+// Lookup ищет ключ, с которым создана подписка. Пустой publicKey — подписка
+// старше учёта ключей, для неё берётся активный.
+func (k *VAPIDKeyring) Lookup(ctx context.Context, publicKey string) (models.VAPIDKey, bool) {
+	k.refresh(ctx)
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if publicKey == "" {
+		return k.current, true
+	}
+	key, ok := k.keys[publicKey]
+	return key, ok
+}
+
+// IsCurrent проверяет, активен ли publicKey. Незнакомый ключ — повод
+// перечитать хранилище сразу: браузер мог получить новый ключ от
+// экземпляра, который заметил ротацию раньше этого. Такое чтение бывает не
+// чаще раза в vapidMissReloadInterval, чтобы запросы с мусорным ключом не
+// нагружали базу.
+func (k *VAPIDKeyring) IsCurrent(ctx context.Context, publicKey string) bool {
+	k.mu.Lock()
+	current := k.current.PublicKey
+	_, known := k.keys[publicKey]
+	throttled := time.Since(k.missAt) < vapidMissReloadInterval
+	if publicKey != current && !known && !throttled {
+		k.missAt = time.Now()
+	}
+	k.mu.Unlock()
+
+	if publicKey == current {
+		return true
+	}
+	// Выведенный из оборота ключ уже известен: перечитывать нечего.
+	if k.store == nil || known || throttled {
+		return false
+	}
+	if err := k.reload(ctx); err != nil {
+		log.Printf("[PUSH] Failed to reload VAPID keys: %v", err)
+		return false
+	}
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return publicKey == k.current.PublicKey
+}
+
+// GenerateVAPIDKey создаёт новую пару ключей.
+func GenerateVAPIDKey() (models.VAPIDKey, error) {
+	priv, pub, err := webpush.GenerateVAPIDKeys()
+	if err != nil {
+		return models.VAPIDKey{}, fmt.Errorf("failed to generate VAPID keys: %w", err)
+	}
+	return models.VAPIDKey{
+		PublicKey:  pub,
+		PrivateKey: priv,
+		Active:     true,
+		CreatedAt:  time.Now().In(utils.LaundryLocation()),
+	}, nil
+}
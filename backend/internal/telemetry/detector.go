@@ -0,0 +1,101 @@
+package telemetry
+
+import (
+	"sync"
+	"time"
+)
+
+type CycleEvent int
+
+const (
+	NoEvent CycleEvent = iota
+	CycleStarted
+	CycleEnded
+)
+
+// Thresholds задают, как по мощности розетки понять, что идёт стирка.
+// Цикл начинается, когда мощность держится не ниже StartWatts в течение
+// StartDelay, и заканчивается, когда она не выше StopWatts в течение
+// StopDelay. StopDelay должен перекрывать паузы машины между полосканиями.
+type Thresholds struct {
+	StartWatts float64
+	StopWatts  float64
+	StartDelay time.Duration
+	StopDelay  time.Duration
+}
+
+type machineState struct {
+	running   bool
+	startedAt time.Time
+	highSince time.Time
+	lowSince  time.Time
+}
+
+// Detector отслеживает циклы стирки по показаниям мощности для каждой машины.
+type Detector struct {
+	thresholds Thresholds
+
+	mu       sync.Mutex
+	machines map[int]*machineState
+}
+
+func NewDetector(t Thresholds) *Detector {
+	return &Detector{
+		thresholds: t,
+		machines:   make(map[int]*machineState),
+	}
+}
+
+// Observe учитывает очередное показание мощности и возвращает событие,
+// если оно произошло. Для CycleEnded также возвращается начало цикла.
+func (d *Detector) Observe(machineID int, watts float64, at time.Time) (CycleEvent, time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	st, ok := d.machines[machineID]
+	if !ok {
+		st = &machineState{}
+		d.machines[machineID] = st
+	}
+
+	if !st.running {
+		st.lowSince = time.Time{}
+		if watts < d.thresholds.StartWatts {
+			st.highSince = time.Time{}
+			return NoEvent, time.Time{}
+		}
+		if st.highSince.IsZero() {
+			st.highSince = at
+		}
+		if at.Sub(st.highSince) >= d.thresholds.StartDelay {
+			st.running = true
+			st.startedAt = st.highSince
+			st.highSince = time.Time{}
+			return CycleStarted, st.startedAt
+		}
+		return NoEvent, time.Time{}
+	}
+
+	st.highSince = time.Time{}
+	if watts > d.thresholds.StopWatts {
+		st.lowSince = time.Time{}
+		return NoEvent, time.Time{}
+	}
+	if st.lowSince.IsZero() {
+		st.lowSince = at
+	}
+	if at.Sub(st.lowSince) >= d.thresholds.StopDelay {
+		started := st.startedAt
+		st.running = false
+		st.lowSince = time.Time{}
+		return CycleEnded, started
+	}
+	return NoEvent, time.Time{}
+}
+
+func (d *Detector) Running(machineID int) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	st, ok := d.machines[machineID]
+	return ok && st.running
+}
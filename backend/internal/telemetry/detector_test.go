@@ -0,0 +1,125 @@
+package telemetry
+
+import (
+	"testing"
+	"time"
+)
+
+var testThresholds = Thresholds{
+	StartWatts: 100,
+	StopWatts:  5,
+	StartDelay: time.Minute,
+	StopDelay:  3 * time.Minute,
+}
+
+type reading struct {
+	offset time.Duration
+	watts  float64
+	want   CycleEvent
+}
+
+func observeAll(t *testing.T, d *Detector, machineID int, base time.Time, readings []reading) {
+	t.Helper()
+	for i, r := range readings {
+		got, _ := d.Observe(machineID, r.watts, base.Add(r.offset))
+		if got != r.want {
+			t.Fatalf("reading %d (%s, %.1f W): got event %d, want %d", i, r.offset, r.watts, got, r.want)
+		}
+	}
+}
+
+func TestDetectorFullCycle(t *testing.T) {
+	d := NewDetector(testThresholds)
+	base := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+
+	observeAll(t, d, 1, base, []reading{
+		{0, 1, NoEvent},
+		{10 * time.Second, 1800, NoEvent},
+		{40 * time.Second, 1800, NoEvent},
+		{70 * time.Second, 1800, CycleStarted},
+		{10 * time.Minute, 1800, NoEvent},
+		{40 * time.Minute, 2, NoEvent},
+		{42 * time.Minute, 2, NoEvent},
+	})
+	if !d.Running(1) {
+		t.Fatal("machine should still be running before StopDelay passes")
+	}
+
+	got, startedAt := d.Observe(1, 2, base.Add(43*time.Minute))
+	if got != CycleEnded {
+		t.Fatalf("got event %d, want CycleEnded", got)
+	}
+	if want := base.Add(10 * time.Second); !startedAt.Equal(want) {
+		t.Fatalf("cycle started at %s, want %s (first high reading)", startedAt, want)
+	}
+	if d.Running(1) {
+		t.Fatal("machine should be idle after the cycle ended")
+	}
+}
+
+func TestDetectorIgnoresShortSpikes(t *testing.T) {
+	d := NewDetector(testThresholds)
+	base := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+
+	// Скачок короче StartDelay прерывается низким показанием — отсчёт
+	// начинается заново.
+	observeAll(t, d, 1, base, []reading{
+		{0, 1800, NoEvent},
+		{50 * time.Second, 40, NoEvent},
+		{60 * time.Second, 1800, NoEvent},
+		{110 * time.Second, 1800, NoEvent},
+		{120 * time.Second, 1800, CycleStarted},
+	})
+}
+
+func TestDetectorDebouncesPausesInsideCycle(t *testing.T) {
+	d := NewDetector(testThresholds)
+	base := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+
+	// Пауза между полосканиями короче StopDelay не заканчивает цикл.
+	observeAll(t, d, 1, base, []reading{
+		{0, 1800, NoEvent},
+		{time.Minute, 1800, CycleStarted},
+		{20 * time.Minute, 3, NoEvent},
+		{22 * time.Minute, 3, NoEvent},
+		{23 * time.Minute, 400, NoEvent},
+		{25 * time.Minute, 3, NoEvent},
+		{27 * time.Minute, 3, NoEvent},
+		{28 * time.Minute, 3, CycleEnded},
+	})
+}
+
+func TestDetectorTracksMachinesSeparately(t *testing.T) {
+	d := NewDetector(testThresholds)
+	base := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+
+	observeAll(t, d, 1, base, []reading{
+		{0, 1800, NoEvent},
+		{time.Minute, 1800, CycleStarted},
+	})
+	observeAll(t, d, 2, base, []reading{
+		{time.Minute, 1, NoEvent},
+		{5 * time.Minute, 1, NoEvent},
+	})
+	if !d.Running(1) || d.Running(2) {
+		t.Fatalf("running: machine 1 = %v, machine 2 = %v", d.Running(1), d.Running(2))
+	}
+}
+
+func TestDetectorBetweenThresholdsKeepsState(t *testing.T) {
+	d := NewDetector(testThresholds)
+	base := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+
+	// Мощность между StopWatts и StartWatts не начинает и не заканчивает цикл.
+	observeAll(t, d, 1, base, []reading{
+		{0, 50, NoEvent},
+		{10 * time.Minute, 50, NoEvent},
+		{11 * time.Minute, 1800, NoEvent},
+		{12 * time.Minute, 1800, CycleStarted},
+		{20 * time.Minute, 50, NoEvent},
+		{40 * time.Minute, 50, NoEvent},
+	})
+	if !d.Running(1) {
+		t.Fatal("machine should still be running")
+	}
+}
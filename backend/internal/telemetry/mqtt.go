@@ -0,0 +1,108 @@
+package telemetry
+
+import (
+	"context"
+	"log"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// CycleHandler получает события начала и окончания стирки.
+type CycleHandler interface {
+	CycleStarted(ctx context.Context, machineID int, at time.Time)
+	CycleEnded(ctx context.Context, machineID int, startedAt, endedAt time.Time)
+}
+
+type MQTTConfig struct {
+	BrokerURL string
+	ClientID  string
+	Username  string
+	Password  string
+	Topics    map[string]int
+}
+
+// Ingestor подписывается на топики розеток и превращает показания
+// мощности в события циклов стирки.
+type Ingestor struct {
+	cfg      MQTTConfig
+	detector *Detector
+	handler  CycleHandler
+	client   mqtt.Client
+	ctx      context.Context
+}
+
+func NewIngestor(cfg MQTTConfig, detector *Detector, handler CycleHandler) *Ingestor {
+	return &Ingestor{
+		cfg:      cfg,
+		detector: detector,
+		handler:  handler,
+	}
+}
+
+// Run подключается к брокеру и разбирает показания, пока ctx не отменён.
+// Запускается только на экземпляре-лидере: у всех экземпляров один
+// MQTT-клиент, а каждый цикл должен учитываться один раз.
+func (i *Ingestor) Run(ctx context.Context) {
+	i.ctx = ctx
+
+	opts := mqtt.NewClientOptions().
+		AddBroker(i.cfg.BrokerURL).
+		SetClientID(i.cfg.ClientID).
+		SetUsername(i.cfg.Username).
+		SetPassword(i.cfg.Password).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetConnectRetryInterval(5 * time.Second).
+		SetOnConnectHandler(i.subscribe).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			log.Printf("📡 [TELEMETRY] Connection lost: %v", err)
+		})
+
+	i.client = mqtt.NewClient(opts)
+	token := i.client.Connect()
+	if !token.WaitTimeout(10*time.Second) || token.Error() != nil {
+		// SetConnectRetry продолжит попытки в фоне
+		log.Printf("📡 [TELEMETRY] Broker %s not reachable yet, retrying in background", i.cfg.BrokerURL)
+	}
+
+	<-ctx.Done()
+	i.client.Disconnect(250)
+}
+
+func (i *Ingestor) subscribe(c mqtt.Client) {
+	filters := make(map[string]byte, len(i.cfg.Topics))
+	for topic := range i.cfg.Topics {
+		filters[topic] = 1
+	}
+	token := c.SubscribeMultiple(filters, i.onMessage)
+	token.Wait()
+	if err := token.Error(); err != nil {
+		log.Printf("📡 [TELEMETRY] Subscribe error: %v", err)
+		return
+	}
+	log.Printf("📡 [TELEMETRY] Subscribed to %d plug topics on %s", len(filters), i.cfg.BrokerURL)
+}
+
+func (i *Ingestor) onMessage(_ mqtt.Client, msg mqtt.Message) {
+	machineID, ok := i.cfg.Topics[msg.Topic()]
+	if !ok {
+		return
+	}
+
+	watts, err := ParsePower(msg.Payload())
+	if err != nil {
+		log.Printf("📡 [TELEMETRY] %s: %v", msg.Topic(), err)
+		return
+	}
+
+	now := time.Now()
+	switch event, startedAt := i.detector.Observe(machineID, watts, now); event {
+	case CycleStarted:
+		log.Printf("📡 [TELEMETRY] Machine %d cycle started (%.1f W)", machineID, watts)
+		i.handler.CycleStarted(i.ctx, machineID, startedAt)
+	case CycleEnded:
+		log.Printf("📡 [TELEMETRY] Machine %d cycle ended after %s", machineID, now.Sub(startedAt).Round(time.Second))
+		i.handler.CycleEnded(i.ctx, machineID, startedAt, now)
+	}
+}
@@ -0,0 +1,119 @@
+package telemetry
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	mqtt "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+)
+
+type cycleRecord struct {
+	event     CycleEvent
+	machineID int
+}
+
+type recordingHandler chan cycleRecord
+
+func (h recordingHandler) CycleStarted(_ context.Context, machineID int, _ time.Time) {
+	h <- cycleRecord{CycleStarted, machineID}
+}
+
+func (h recordingHandler) CycleEnded(_ context.Context, machineID int, _, _ time.Time) {
+	h <- cycleRecord{CycleEnded, machineID}
+}
+
+// startBroker поднимает в процессе тот же брокер, что и cmd/mqtt_dev_broker,
+// на свободном порту.
+func startBroker(t *testing.T) (*mqtt.Server, string) {
+	t.Helper()
+	server := mqtt.New(&mqtt.Options{InlineClient: true, Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	if err := server.AddHook(new(auth.AllowHook), nil); err != nil {
+		t.Fatal(err)
+	}
+	tcp := listeners.NewTCP(listeners.Config{ID: "tcp", Address: "127.0.0.1:0"})
+	if err := server.AddListener(tcp); err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve()
+	t.Cleanup(func() { server.Close() })
+	return server, "tcp://" + tcp.Address()
+}
+
+func waitForSubscriber(t *testing.T, server *mqtt.Server, topic string) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for len(server.Topics.Subscribers(topic).Subscriptions) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("ingestor did not subscribe to %s", topic)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func expectCycle(t *testing.T, events <-chan cycleRecord, want cycleRecord) {
+	t.Helper()
+	select {
+	case got := <-events:
+		if got != want {
+			t.Fatalf("got %+v, want %+v", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for %+v", want)
+	}
+}
+
+func TestIngestorDetectsCycleFromBroker(t *testing.T) {
+	server, url := startBroker(t)
+
+	events := make(recordingHandler, 10)
+	ingestor := NewIngestor(MQTTConfig{
+		BrokerURL: url,
+		ClientID:  "netiwash-test",
+		Topics:    map[string]int{"plugs/washer1/power": 1, "plugs/dryer1/power": 4},
+	}, NewDetector(Thresholds{StartWatts: 100, StopWatts: 5}), events)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		ingestor.Run(ctx)
+		close(done)
+	}()
+	waitForSubscriber(t, server, "plugs/washer1/power")
+
+	publish := func(topic, payload string) {
+		t.Helper()
+		if err := server.Publish(topic, []byte(payload), false, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	publish("plugs/washer1/power", `{"power": 1800}`)
+	expectCycle(t, events, cycleRecord{CycleStarted, 1})
+
+	// Непонятные сообщения и чужие топики пропускаются.
+	publish("plugs/washer1/power", `{"voltage": 230}`)
+	publish("plugs/unknown/power", `{"power": 0}`)
+
+	publish("plugs/dryer1/power", `{"apower": 900}`)
+	expectCycle(t, events, cycleRecord{CycleStarted, 4})
+
+	publish("plugs/washer1/power", `{"ENERGY": {"Power": 1.5}}`)
+	expectCycle(t, events, cycleRecord{CycleEnded, 1})
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after ctx was cancelled")
+	}
+	select {
+	case got := <-events:
+		t.Fatalf("unexpected event %+v", got)
+	default:
+	}
+}
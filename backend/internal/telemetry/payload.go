@@ -0,0 +1,61 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParsePower достаёт мощность в ваттах из сообщения розетки. Поддерживаются
+// голое число, {"power": ...}, Shelly ({"apower": ...}) и Tasmota
+// ({"ENERGY": {"Power": ...}}).
+func ParsePower(payload []byte) (float64, error) {
+	text := strings.TrimSpace(string(payload))
+	if w, err := strconv.ParseFloat(text, 64); err == nil {
+		return w, nil
+	}
+
+	var msg struct {
+		Power  *float64 `json:"power"`
+		APower *float64 `json:"apower"`
+		Energy *struct {
+			Power *float64 `json:"Power"`
+		} `json:"ENERGY"`
+	}
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return 0, fmt.Errorf("unsupported power payload: %w", err)
+	}
+
+	switch {
+	case msg.Power != nil:
+		return *msg.Power, nil
+	case msg.APower != nil:
+		return *msg.APower, nil
+	case msg.Energy != nil && msg.Energy.Power != nil:
+		return *msg.Energy.Power, nil
+	}
+	return 0, fmt.Errorf("power value not found in payload")
+}
+
+// ParseTopicMap разбирает строку вида "plugs/washer1/power=1,plugs/dryer1/power=4"
+// в соответствие топик → machines.id.
+func ParseTopicMap(s string) (map[string]int, error) {
+	result := make(map[string]int)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		topic, idStr, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid topic mapping %q, expected topic=machine_id", pair)
+		}
+		id, err := strconv.Atoi(strings.TrimSpace(idStr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid machine id in %q: %w", pair, err)
+		}
+		result[strings.TrimSpace(topic)] = id
+	}
+	return result, nil
+}
@@ -0,0 +1,57 @@
+package telemetry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePower(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    float64
+	}{
+		{"bare number", "1834.5", 1834.5},
+		{"bare number with spaces", " 12\n", 12},
+		{"power field", `{"power": 1800.2}`, 1800.2},
+		{"shelly", `{"id": 0, "apower": 950, "voltage": 229.1}`, 950},
+		{"tasmota", `{"Time": "2026-10-19T10:00:00", "ENERGY": {"Total": 1.2, "Power": 2100}}`, 2100},
+		{"zero", `{"power": 0}`, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePower([]byte(tt.payload))
+			if err != nil {
+				t.Fatalf("ParsePower(%q): %v", tt.payload, err)
+			}
+			if got != tt.want {
+				t.Fatalf("ParsePower(%q) = %v, want %v", tt.payload, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePowerRejectsUnknownPayloads(t *testing.T) {
+	for _, payload := range []string{"", "on", `{"voltage": 230}`, `{"ENERGY": {"Total": 1.2}}`, `{"power": "high"}`} {
+		if w, err := ParsePower([]byte(payload)); err == nil {
+			t.Errorf("ParsePower(%q) = %v, want error", payload, w)
+		}
+	}
+}
+
+func TestParseTopicMap(t *testing.T) {
+	got, err := ParseTopicMap(" plugs/washer1/power=1, plugs/dryer1/power = 4 ,,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"plugs/washer1/power": 1, "plugs/dryer1/power": 4}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseTopicMap = %v, want %v", got, want)
+	}
+
+	for _, spec := range []string{"plugs/washer1/power", "plugs/washer1/power=one"} {
+		if _, err := ParseTopicMap(spec); err == nil {
+			t.Errorf("ParseTopicMap(%q) should fail", spec)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS wallets (
+    user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    balance INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS payments (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider_payment_id VARCHAR(255) UNIQUE,
+    idempotence_key VARCHAR(64) UNIQUE NOT NULL,
+    credits INT NOT NULL,
+    amount INT NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT 'RUB',
+    status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    confirmation_url TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    paid_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS wallet_transactions (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount INT NOT NULL,
+    reason VARCHAR(50) NOT NULL,
+    payment_id INT UNIQUE REFERENCES payments(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_user_id ON payments(user_id);
+CREATE INDEX IF NOT EXISTS idx_wallet_transactions_user_id ON wallet_transactions(user_id);
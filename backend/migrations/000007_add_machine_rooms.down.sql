@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_bookings_start_time;
+ALTER TABLE machines DROP COLUMN IF EXISTS room;
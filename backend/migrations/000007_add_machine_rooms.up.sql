@@ -0,0 +1,3 @@
+ALTER TABLE machines ADD COLUMN IF NOT EXISTS room VARCHAR(100) NOT NULL DEFAULT 'main';
+
+CREATE INDEX IF NOT EXISTS idx_bookings_start_time ON bookings(start_time);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS machine_status_history (
+    id SERIAL PRIMARY KEY,
+    machine_id INT NOT NULL REFERENCES machines(id) ON DELETE CASCADE,
+    from_status VARCHAR(50),
+    to_status VARCHAR(50) NOT NULL,
+    reason TEXT,
+    actor_id INT REFERENCES users(id) ON DELETE SET NULL,
+    source VARCHAR(50) NOT NULL DEFAULT 'admin',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_machine_status_history_machine_id ON machine_status_history(machine_id, created_at);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS repair_tickets (
+    id SERIAL PRIMARY KEY,
+    machine_id INT NOT NULL REFERENCES machines(id) ON DELETE CASCADE,
+    reporter_id INT REFERENCES users(id) ON DELETE SET NULL,
+    category VARCHAR(50) NOT NULL,
+    description TEXT NOT NULL,
+    photo_path TEXT,
+    status VARCHAR(50) NOT NULL DEFAULT 'open',
+    admin_note TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_repair_tickets_machine_id ON repair_tickets(machine_id);
+CREATE INDEX IF NOT EXISTS idx_repair_tickets_status ON repair_tickets(status);
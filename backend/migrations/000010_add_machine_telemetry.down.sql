@@ -0,0 +1,4 @@
+ALTER TABLE machines
+DROP COLUMN IF EXISTS is_running,
+DROP COLUMN IF EXISTS last_cycle_started_at,
+DROP COLUMN IF EXISTS last_cycle_ended_at;
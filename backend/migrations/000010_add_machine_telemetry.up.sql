@@ -0,0 +1,4 @@
+ALTER TABLE machines
+ADD COLUMN IF NOT EXISTS is_running BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN IF NOT EXISTS last_cycle_started_at TIMESTAMP,
+ADD COLUMN IF NOT EXISTS last_cycle_ended_at TIMESTAMP;
@@ -0,0 +1,3 @@
+ALTER TABLE machines DROP COLUMN IF EXISTS last_door_opened_at;
+DROP TABLE IF EXISTS device_events;
+DROP TABLE IF EXISTS devices;
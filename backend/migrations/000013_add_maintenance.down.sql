@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS maintenance_tasks;
+DROP TABLE IF EXISTS maintenance_plans;
+ALTER TABLE machines DROP COLUMN IF EXISTS cycle_count;
@@ -0,0 +1,27 @@
+ALTER TABLE machines ADD COLUMN IF NOT EXISTS cycle_count INT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS maintenance_plans (
+    id SERIAL PRIMARY KEY,
+    machine_type VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    every_cycles INT,
+    every_days INT,
+    window_minutes INT NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS maintenance_tasks (
+    id SERIAL PRIMARY KEY,
+    plan_id INT NOT NULL REFERENCES maintenance_plans(id) ON DELETE CASCADE,
+    machine_id INT NOT NULL REFERENCES machines(id) ON DELETE CASCADE,
+    status VARCHAR(50) NOT NULL DEFAULT 'open',
+    cycle_count INT NOT NULL,
+    window_start TIMESTAMP,
+    window_end TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP,
+    completed_by INT REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_tasks_machine_id ON maintenance_tasks(machine_id, status);
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS machine_photos;
+ALTER TABLE machines
+DROP COLUMN IF EXISTS attributes,
+DROP COLUMN IF EXISTS notes,
+DROP COLUMN IF EXISTS position,
+DROP COLUMN IF EXISTS floor,
+DROP COLUMN IF EXISTS brand,
+DROP COLUMN IF EXISTS capacity_kg;
@@ -0,0 +1,17 @@
+ALTER TABLE machines
+ADD COLUMN IF NOT EXISTS capacity_kg REAL,
+ADD COLUMN IF NOT EXISTS brand VARCHAR(100),
+ADD COLUMN IF NOT EXISTS floor INT,
+ADD COLUMN IF NOT EXISTS position VARCHAR(255),
+ADD COLUMN IF NOT EXISTS notes TEXT,
+ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}';
+
+CREATE TABLE IF NOT EXISTS machine_photos (
+    id SERIAL PRIMARY KEY,
+    machine_id INT NOT NULL REFERENCES machines(id) ON DELETE CASCADE,
+    storage_key TEXT NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_machine_photos_machine_id ON machine_photos(machine_id);
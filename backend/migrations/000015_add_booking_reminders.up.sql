@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS booking_reminders (
+    booking_id INT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    offset_minutes INT NOT NULL,
+    start_time TIMESTAMP NOT NULL,
+    sent_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (booking_id, offset_minutes, start_time)
+);
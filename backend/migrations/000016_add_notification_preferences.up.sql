@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    channels JSONB NOT NULL DEFAULT '{}',
+    quiet_hours_start VARCHAR(5),
+    quiet_hours_end VARCHAR(5),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS telegram_accounts (
+    user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    chat_id BIGINT UNIQUE NOT NULL,
+    username VARCHAR(255),
+    linked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS telegram_link_codes (
+    code VARCHAR(16) PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,2 @@
+ALTER TABLE push_subscriptions DROP COLUMN IF EXISTS last_success_at;
+ALTER TABLE push_subscriptions DROP COLUMN IF EXISTS user_agent;
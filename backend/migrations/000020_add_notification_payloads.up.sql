@@ -0,0 +1,2 @@
+ALTER TABLE notification_preferences ADD COLUMN IF NOT EXISTS locale VARCHAR(8) NOT NULL DEFAULT 'ru';
+ALTER TABLE notification_deliveries ADD COLUMN IF NOT EXISTS payload JSONB;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS announcement_recipients;
+DROP TABLE IF EXISTS announcements;
+ALTER TABLE machines DROP COLUMN IF EXISTS building;
@@ -0,0 +1,22 @@
+ALTER TABLE machines ADD COLUMN IF NOT EXISTS building VARCHAR(100);
+
+CREATE TABLE IF NOT EXISTS announcements (
+    id SERIAL PRIMARY KEY,
+    title VARCHAR(200) NOT NULL,
+    body TEXT NOT NULL,
+    target VARCHAR(16) NOT NULL,
+    target_value VARCHAR(100),
+    range_start TIMESTAMP,
+    range_end TIMESTAMP,
+    author_id INT REFERENCES users(id) ON DELETE SET NULL,
+    recipient_count INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS announcement_recipients (
+    announcement_id INT NOT NULL REFERENCES announcements(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (announcement_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_announcement_recipients_user_id ON announcement_recipients(user_id);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS job_leaders (
+    job VARCHAR(50) PRIMARY KEY,
+    node_id VARCHAR(100) NOT NULL,
+    acquired_at TIMESTAMP NOT NULL,
+    heartbeat_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS inbox_notifications (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(32) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    url VARCHAR(255),
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_inbox_notifications_user_id ON inbox_notifications(user_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_inbox_notifications_unread ON inbox_notifications(user_id) WHERE read_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_inbox_notifications_created_at ON inbox_notifications(created_at);
@@ -0,0 +1,2 @@
+ALTER TABLE push_subscriptions DROP COLUMN IF EXISTS vapid_public_key;
+DROP TABLE IF EXISTS vapid_keys;
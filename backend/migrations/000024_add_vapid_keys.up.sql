@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS vapid_keys (
+    id SERIAL PRIMARY KEY,
+    public_key TEXT NOT NULL UNIQUE,
+    private_key TEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL,
+    retired_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_vapid_keys_active ON vapid_keys(active) WHERE active;
+
+ALTER TABLE push_subscriptions ADD COLUMN IF NOT EXISTS vapid_public_key TEXT;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_announcement_recipients_pending;
+ALTER TABLE announcement_recipients DROP COLUMN IF EXISTS notified_at;
@@ -0,0 +1,7 @@
+-- Рассылкой объявлений занимается воркер: notified_at IS NULL — получатель
+-- ещё ждёт уведомление. Уже разосланные получатели помечаются временем
+-- миграции.
+ALTER TABLE announcement_recipients ADD COLUMN IF NOT EXISTS notified_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE announcement_recipients ALTER COLUMN notified_at DROP DEFAULT;
+
+CREATE INDEX IF NOT EXISTS idx_announcement_recipients_pending ON announcement_recipients(announcement_id) WHERE notified_at IS NULL;
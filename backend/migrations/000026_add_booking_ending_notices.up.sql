@@ -0,0 +1,8 @@
+-- Уведомление «бронь скоро закончится» с кнопкой продления уходит один раз
+-- на каждое время окончания: после переноса брони — заново.
+CREATE TABLE IF NOT EXISTS booking_ending_notices (
+    booking_id INT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    end_time TIMESTAMP NOT NULL,
+    sent_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (booking_id, end_time)
+);
@@ -93,6 +93,273 @@ func RunMigrations(pool *pgxpool.Pool) error {
             WHEN duplicate_column THEN RAISE NOTICE 'column push_sent already exists in bookings.';
         END;
     END $$;
+
+	CREATE TABLE IF NOT EXISTS wallets (
+		user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+		balance INT NOT NULL DEFAULT 0,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS payments (
+		id SERIAL PRIMARY KEY,
+		user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		provider_payment_id VARCHAR(255) UNIQUE,
+		idempotence_key VARCHAR(64) UNIQUE NOT NULL,
+		credits INT NOT NULL,
+		amount INT NOT NULL,
+		currency VARCHAR(3) NOT NULL DEFAULT 'RUB',
+		status VARCHAR(50) NOT NULL DEFAULT 'pending',
+		confirmation_url TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		paid_at TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS wallet_transactions (
+		id SERIAL PRIMARY KEY,
+		user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		amount INT NOT NULL,
+		reason VARCHAR(50) NOT NULL,
+		payment_id INT UNIQUE REFERENCES payments(id) ON DELETE SET NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_payments_user_id ON payments(user_id);
+	CREATE INDEX IF NOT EXISTS idx_wallet_transactions_user_id ON wallet_transactions(user_id);
+
+	ALTER TABLE machines ADD COLUMN IF NOT EXISTS room VARCHAR(100) NOT NULL DEFAULT 'main';
+	CREATE INDEX IF NOT EXISTS idx_bookings_start_time ON bookings(start_time);
+
+	CREATE TABLE IF NOT EXISTS machine_status_history (
+		id SERIAL PRIMARY KEY,
+		machine_id INT NOT NULL REFERENCES machines(id) ON DELETE CASCADE,
+		from_status VARCHAR(50),
+		to_status VARCHAR(50) NOT NULL,
+		reason TEXT,
+		actor_id INT REFERENCES users(id) ON DELETE SET NULL,
+		source VARCHAR(50) NOT NULL DEFAULT 'admin',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_machine_status_history_machine_id ON machine_status_history(machine_id, created_at);
+
+	CREATE TABLE IF NOT EXISTS repair_tickets (
+		id SERIAL PRIMARY KEY,
+		machine_id INT NOT NULL REFERENCES machines(id) ON DELETE CASCADE,
+		reporter_id INT REFERENCES users(id) ON DELETE SET NULL,
+		category VARCHAR(50) NOT NULL,
+		description TEXT NOT NULL,
+		photo_path TEXT,
+		status VARCHAR(50) NOT NULL DEFAULT 'open',
+		admin_note TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		resolved_at TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_repair_tickets_machine_id ON repair_tickets(machine_id);
+	CREATE INDEX IF NOT EXISTS idx_repair_tickets_status ON repair_tickets(status);
+
+	ALTER TABLE machines
+	ADD COLUMN IF NOT EXISTS is_running BOOLEAN NOT NULL DEFAULT FALSE,
+	ADD COLUMN IF NOT EXISTS last_cycle_started_at TIMESTAMP,
+	ADD COLUMN IF NOT EXISTS last_cycle_ended_at TIMESTAMP;
+
+	CREATE TABLE IF NOT EXISTS devices (
+		id SERIAL PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		machine_id INT REFERENCES machines(id) ON DELETE SET NULL,
+		key_prefix VARCHAR(16) UNIQUE NOT NULL,
+		key_hash VARCHAR(64) NOT NULL,
+		created_by INT REFERENCES users(id) ON DELETE SET NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		last_seen_at TIMESTAMP,
+		revoked_at TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS device_events (
+		id SERIAL PRIMARY KEY,
+		device_id INT NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+		machine_id INT NOT NULL REFERENCES machines(id) ON DELETE CASCADE,
+		event_type VARCHAR(50) NOT NULL,
+		data JSONB,
+		occurred_at TIMESTAMP NOT NULL,
+		received_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_device_events_machine_id ON device_events(machine_id, occurred_at);
+
+	ALTER TABLE machines ADD COLUMN IF NOT EXISTS last_door_opened_at TIMESTAMP;
+
+	ALTER TABLE bookings ADD COLUMN IF NOT EXISTS checked_in_at TIMESTAMP;
+
+	ALTER TABLE machines ADD COLUMN IF NOT EXISTS cycle_count INT NOT NULL DEFAULT 0;
+
+	CREATE TABLE IF NOT EXISTS maintenance_plans (
+		id SERIAL PRIMARY KEY,
+		machine_type VARCHAR(50) NOT NULL,
+		name VARCHAR(255) NOT NULL,
+		every_cycles INT,
+		every_days INT,
+		window_minutes INT NOT NULL DEFAULT 0,
+		is_active BOOLEAN NOT NULL DEFAULT TRUE,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS maintenance_tasks (
+		id SERIAL PRIMARY KEY,
+		plan_id INT NOT NULL REFERENCES maintenance_plans(id) ON DELETE CASCADE,
+		machine_id INT NOT NULL REFERENCES machines(id) ON DELETE CASCADE,
+		status VARCHAR(50) NOT NULL DEFAULT 'open',
+		cycle_count INT NOT NULL,
+		window_start TIMESTAMP,
+		window_end TIMESTAMP,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		completed_at TIMESTAMP,
+		completed_by INT REFERENCES users(id) ON DELETE SET NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_maintenance_tasks_machine_id ON maintenance_tasks(machine_id, status);
+
+	ALTER TABLE machines
+	ADD COLUMN IF NOT EXISTS capacity_kg REAL,
+	ADD COLUMN IF NOT EXISTS brand VARCHAR(100),
+	ADD COLUMN IF NOT EXISTS floor INT,
+	ADD COLUMN IF NOT EXISTS position VARCHAR(255),
+	ADD COLUMN IF NOT EXISTS notes TEXT,
+	ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}';
+
+	CREATE TABLE IF NOT EXISTS machine_photos (
+		id SERIAL PRIMARY KEY,
+		machine_id INT NOT NULL REFERENCES machines(id) ON DELETE CASCADE,
+		storage_key TEXT NOT NULL,
+		content_type VARCHAR(100) NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_machine_photos_machine_id ON machine_photos(machine_id);
+
+	CREATE TABLE IF NOT EXISTS booking_reminders (
+		booking_id INT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+		offset_minutes INT NOT NULL,
+		start_time TIMESTAMP NOT NULL,
+		sent_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (booking_id, offset_minutes, start_time)
+	);
+
+	CREATE TABLE IF NOT EXISTS notification_preferences (
+		user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+		channels JSONB NOT NULL DEFAULT '{}',
+		quiet_hours_start VARCHAR(5),
+		quiet_hours_end VARCHAR(5),
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS telegram_accounts (
+		user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+		chat_id BIGINT UNIQUE NOT NULL,
+		username VARCHAR(255),
+		linked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS telegram_link_codes (
+		code VARCHAR(16) PRIMARY KEY,
+		user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		expires_at TIMESTAMP NOT NULL
+	);
+
+	ALTER TABLE push_subscriptions ADD COLUMN IF NOT EXISTS user_agent TEXT;
+	ALTER TABLE push_subscriptions ADD COLUMN IF NOT EXISTS last_success_at TIMESTAMP;
+
+	CREATE TABLE IF NOT EXISTS notification_deliveries (
+		id SERIAL PRIMARY KEY,
+		user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		kind VARCHAR(32) NOT NULL,
+		channel VARCHAR(32) NOT NULL,
+		subscription_id INT REFERENCES push_subscriptions(id) ON DELETE SET NULL,
+		message TEXT NOT NULL,
+		status VARCHAR(16) NOT NULL DEFAULT 'pending',
+		attempts INT NOT NULL DEFAULT 0,
+		last_error TEXT,
+		next_attempt_at TIMESTAMP,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_notification_deliveries_due
+		ON notification_deliveries(next_attempt_at) WHERE status IN ('pending', 'failed');
+	CREATE INDEX IF NOT EXISTS idx_notification_deliveries_status ON notification_deliveries(status, updated_at);
+
+	ALTER TABLE notification_preferences ADD COLUMN IF NOT EXISTS locale VARCHAR(8) NOT NULL DEFAULT 'ru';
+	ALTER TABLE notification_deliveries ADD COLUMN IF NOT EXISTS payload JSONB;
+
+	ALTER TABLE machines ADD COLUMN IF NOT EXISTS building VARCHAR(100);
+
+	CREATE TABLE IF NOT EXISTS announcements (
+		id SERIAL PRIMARY KEY,
+		title VARCHAR(200) NOT NULL,
+		body TEXT NOT NULL,
+		target VARCHAR(16) NOT NULL,
+		target_value VARCHAR(100),
+		range_start TIMESTAMP,
+		range_end TIMESTAMP,
+		author_id INT REFERENCES users(id) ON DELETE SET NULL,
+		recipient_count INT NOT NULL DEFAULT 0,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS announcement_recipients (
+		announcement_id INT NOT NULL REFERENCES announcements(id) ON DELETE CASCADE,
+		user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		PRIMARY KEY (announcement_id, user_id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_announcement_recipients_user_id ON announcement_recipients(user_id);
+
+	CREATE TABLE IF NOT EXISTS job_leaders (
+		job VARCHAR(50) PRIMARY KEY,
+		node_id VARCHAR(100) NOT NULL,
+		acquired_at TIMESTAMP NOT NULL,
+		heartbeat_at TIMESTAMP NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS inbox_notifications (
+		id SERIAL PRIMARY KEY,
+		user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		kind VARCHAR(32) NOT NULL,
+		title VARCHAR(255) NOT NULL,
+		body TEXT NOT NULL,
+		url VARCHAR(255),
+		read_at TIMESTAMP,
+		created_at TIMESTAMP NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_inbox_notifications_user_id ON inbox_notifications(user_id, id DESC);
+	CREATE INDEX IF NOT EXISTS idx_inbox_notifications_unread ON inbox_notifications(user_id) WHERE read_at IS NULL;
+	CREATE INDEX IF NOT EXISTS idx_inbox_notifications_created_at ON inbox_notifications(created_at);
+
+	CREATE TABLE IF NOT EXISTS vapid_keys (
+		id SERIAL PRIMARY KEY,
+		public_key TEXT NOT NULL UNIQUE,
+		private_key TEXT NOT NULL,
+		active BOOLEAN NOT NULL DEFAULT FALSE,
+		created_at TIMESTAMP NOT NULL,
+		retired_at TIMESTAMP
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_vapid_keys_active ON vapid_keys(active) WHERE active;
+
+	ALTER TABLE push_subscriptions ADD COLUMN IF NOT EXISTS vapid_public_key TEXT;
+
+	ALTER TABLE announcement_recipients ADD COLUMN IF NOT EXISTS notified_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+	ALTER TABLE announcement_recipients ALTER COLUMN notified_at DROP DEFAULT;
+	CREATE INDEX IF NOT EXISTS idx_announcement_recipients_pending ON announcement_recipients(announcement_id) WHERE notified_at IS NULL;
+
+	CREATE TABLE IF NOT EXISTS booking_ending_notices (
+		booking_id INT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+		end_time TIMESTAMP NOT NULL,
+		sent_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (booking_id, end_time)
+	);
 	`
 
 	_, err := pool.Exec(ctx, schema)
@@ -108,7 +375,7 @@ func RunMigrations(pool *pgxpool.Pool) error {
 			INSERT INTO machines (name, type, status) VALUES 
 			('Машинка #1', 'washing', 'free'),
 			('Машинка #2', 'washing', 'free'),
-			('Машинка #3', 'washing', 'busy'),
+			('Машинка #3', 'washing', 'free'),
 			('Сушилка #1', 'drying', 'free')
 		`)
 		if err != nil {
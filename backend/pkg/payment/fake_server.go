@@ -0,0 +1,213 @@
+package payment
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FakeServer — локальная заглушка платёжного провайдера для разработки.
+// Реализует подмножество API ЮKassa (/v3/payments) и страницу оплаты,
+// после которой отправляет подписанный webhook на webhookURL.
+type FakeServer struct {
+	publicURL     string
+	webhookURL    string
+	webhookSecret string
+
+	mu       sync.Mutex
+	seq      int
+	payments map[string]*Payment
+	byKey    map[string]string
+	mux      *http.ServeMux
+}
+
+func NewFakeServer(publicURL, webhookURL, webhookSecret string) *FakeServer {
+	s := &FakeServer{
+		publicURL:     strings.TrimRight(publicURL, "/"),
+		webhookURL:    webhookURL,
+		webhookSecret: webhookSecret,
+		payments:      make(map[string]*Payment),
+		byKey:         make(map[string]string),
+		mux:           http.NewServeMux(),
+	}
+	s.mux.HandleFunc("POST /v3/payments", s.handleCreate)
+	s.mux.HandleFunc("GET /v3/payments/{id}", s.handleGet)
+	s.mux.HandleFunc("GET /checkout/{id}", s.handleCheckoutPage)
+	s.mux.HandleFunc("POST /checkout/{id}", s.handleCheckoutSubmit)
+	return s
+}
+
+func (s *FakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *FakeServer) handleCreate(w http.ResponseWriter, r *http.Request) {
+	if _, _, ok := r.BasicAuth(); !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"type": "error", "code": "invalid_credentials"})
+		return
+	}
+	key := r.Header.Get("Idempotence-Key")
+	if key == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"type": "error", "code": "invalid_request", "description": "Idempotence-Key header is required"})
+		return
+	}
+
+	var req struct {
+		Amount       Amount            `json:"amount"`
+		Confirmation Confirmation      `json:"confirmation"`
+		Description  string            `json:"description"`
+		Metadata     map[string]string `json:"metadata"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"type": "error", "code": "invalid_request"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id, ok := s.byKey[key]; ok {
+		writeJSON(w, http.StatusOK, s.payments[id])
+		return
+	}
+
+	s.seq++
+	id := fmt.Sprintf("fake-%d-%d", time.Now().Unix(), s.seq)
+	p := &Payment{
+		ID:     id,
+		Status: StatusPending,
+		Amount: req.Amount,
+		Confirmation: &Confirmation{
+			Type:            "redirect",
+			ReturnURL:       req.Confirmation.ReturnURL,
+			ConfirmationURL: s.publicURL + "/checkout/" + id,
+		},
+		Description: req.Description,
+		Metadata:    req.Metadata,
+		CreatedAt:   time.Now().UTC().Format(time.RFC3339),
+	}
+	s.payments[id] = p
+	s.byKey[key] = id
+
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (s *FakeServer) handleGet(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	p, ok := s.payments[r.PathValue("id")]
+	s.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"type": "error", "code": "not_found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+var checkoutPage = template.Must(template.New("checkout").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head><meta charset="utf-8"><title>Fake checkout</title></head>
+<body>
+<h1>Оплата {{.Amount.Value}} {{.Amount.Currency}}</h1>
+<p>{{.Description}}</p>
+<p>Статус: {{.Status}}</p>
+<form method="post"><button name="action" value="pay">Оплатить</button> <button name="action" value="cancel">Отменить</button></form>
+</body>
+</html>`))
+
+func (s *FakeServer) handleCheckoutPage(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	p, ok := s.payments[r.PathValue("id")]
+	var snapshot Payment
+	if ok {
+		snapshot = *p
+	}
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	checkoutPage.Execute(w, snapshot)
+}
+
+func (s *FakeServer) handleCheckoutSubmit(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	action := r.FormValue("action")
+
+	s.mu.Lock()
+	p, ok := s.payments[id]
+	if !ok {
+		s.mu.Unlock()
+		http.NotFound(w, r)
+		return
+	}
+	event := ""
+	if p.Status == StatusPending {
+		switch action {
+		case "pay":
+			p.Status = StatusSucceeded
+			p.Paid = true
+			p.CapturedAt = time.Now().UTC().Format(time.RFC3339)
+			event = "payment.succeeded"
+		case "cancel":
+			p.Status = StatusCanceled
+			event = "payment.canceled"
+		}
+	}
+	snapshot := *p
+	s.mu.Unlock()
+
+	if event != "" {
+		if err := s.sendWebhook(event, snapshot); err != nil {
+			log.Printf("[FAKE_PAY] Webhook error for %s: %v", id, err)
+		}
+	}
+
+	returnURL := ""
+	if snapshot.Confirmation != nil {
+		returnURL = snapshot.Confirmation.ReturnURL
+	}
+	if returnURL == "" {
+		returnURL = "/checkout/" + id
+	}
+	http.Redirect(w, r, returnURL, http.StatusSeeOther)
+}
+
+func (s *FakeServer) sendWebhook(event string, p Payment) error {
+	if s.webhookURL == "" {
+		return nil
+	}
+	body, err := json.Marshal(WebhookEvent{Type: "notification", Event: event, Object: p})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(body, s.webhookSecret))
+
+	resp, err := (&http.Client{Timeout: 10 * time.Second}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %d", resp.StatusCode)
+	}
+	log.Printf("[FAKE_PAY] Webhook %s delivered for %s", event, p.ID)
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
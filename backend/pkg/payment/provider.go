@@ -0,0 +1,108 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	StatusPending           = "pending"
+	StatusWaitingForCapture = "waiting_for_capture"
+	StatusSucceeded         = "succeeded"
+	StatusCanceled          = "canceled"
+)
+
+const SignatureHeader = "X-Webhook-Signature"
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+type CreatePaymentRequest struct {
+	IdempotenceKey string
+	Amount         int // в копейках
+	Currency       string
+	Description    string
+	ReturnURL      string
+	Metadata       map[string]string
+}
+
+type Payment struct {
+	ID              string            `json:"id"`
+	Status          string            `json:"status"`
+	Paid            bool              `json:"paid"`
+	Amount          Amount            `json:"amount"`
+	Confirmation    *Confirmation     `json:"confirmation,omitempty"`
+	Description     string            `json:"description,omitempty"`
+	Metadata        map[string]string `json:"metadata,omitempty"`
+	CreatedAt       string            `json:"created_at,omitempty"`
+	CapturedAt      string            `json:"captured_at,omitempty"`
+	ConfirmationURL string            `json:"-"`
+}
+
+type Amount struct {
+	Value    string `json:"value"`
+	Currency string `json:"currency"`
+}
+
+type Confirmation struct {
+	Type            string `json:"type"`
+	ReturnURL       string `json:"return_url,omitempty"`
+	ConfirmationURL string `json:"confirmation_url,omitempty"`
+}
+
+type WebhookEvent struct {
+	Type   string  `json:"type"`
+	Event  string  `json:"event"`
+	Object Payment `json:"object"`
+}
+
+// Provider описывает платёжного провайдера с API в стиле ЮKassa.
+type Provider interface {
+	CreatePayment(ctx context.Context, req CreatePaymentRequest) (*Payment, error)
+	GetPayment(ctx context.Context, id string) (*Payment, error)
+}
+
+func FormatAmount(kopecks int) string {
+	return fmt.Sprintf("%d.%02d", kopecks/100, kopecks%100)
+}
+
+func ParseAmount(value string) (int, error) {
+	rub, kop, _ := strings.Cut(value, ".")
+	r, err := strconv.Atoi(rub)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q: %w", value, err)
+	}
+	k := 0
+	if kop != "" {
+		if len(kop) == 1 {
+			kop += "0"
+		}
+		k, err = strconv.Atoi(kop[:2])
+		if err != nil {
+			return 0, fmt.Errorf("invalid amount %q: %w", value, err)
+		}
+	}
+	return r*100 + k, nil
+}
+
+func Sign(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func VerifySignature(body []byte, signature, secret string) error {
+	if secret == "" || signature == "" {
+		return ErrInvalidSignature
+	}
+	expected := Sign(body, secret)
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(signature))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
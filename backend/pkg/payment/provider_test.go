@@ -0,0 +1,129 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"event":"payment.succeeded","object":{"id":"fake-1"}}`)
+	const secret = "webhook-secret"
+	signature := Sign(body, secret)
+
+	if err := VerifySignature(body, signature, secret); err != nil {
+		t.Fatalf("valid signature rejected: %v", err)
+	}
+	if err := VerifySignature(body, strings.ToUpper(signature), secret); err != nil {
+		t.Fatalf("upper-case signature rejected: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		body      []byte
+		signature string
+		secret    string
+	}{
+		{"tampered body", []byte(`{"event":"payment.succeeded","object":{"id":"fake-2"}}`), signature, secret},
+		{"wrong secret", body, signature, "other-secret"},
+		{"missing signature", body, "", secret},
+		{"secret not configured", body, Sign(body, ""), ""},
+		{"garbage", body, "not-hex", secret},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifySignature(tt.body, tt.signature, tt.secret)
+			if !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("VerifySignature = %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := map[string]int{"150.00": 15000, "150": 15000, "99.9": 9990, "0.05": 5, "1.234": 123}
+	for value, want := range tests {
+		got, err := ParseAmount(value)
+		if err != nil || got != want {
+			t.Errorf("ParseAmount(%q) = %d, %v; want %d", value, got, err, want)
+		}
+	}
+	if got := FormatAmount(9905); got != "99.05" {
+		t.Errorf("FormatAmount(9905) = %q, want 99.05", got)
+	}
+	for _, value := range []string{"", "abc", "1.x0"} {
+		if _, err := ParseAmount(value); err == nil {
+			t.Errorf("ParseAmount(%q) should fail", value)
+		}
+	}
+}
+
+// TestYooKassaProviderAgainstFakeServer проверяет клиент на заглушке из
+// cmd/fake_payment_provider: создание, идемпотентность, оплата и webhook.
+func TestYooKassaProviderAgainstFakeServer(t *testing.T) {
+	const secret = "dev-webhook-secret"
+	webhooks := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		webhooks <- r
+		bodies <- body
+	}))
+	t.Cleanup(hook.Close)
+
+	fake := httptest.NewServer(NewFakeServer("", hook.URL, secret))
+	t.Cleanup(fake.Close)
+
+	provider := NewYooKassaProvider(fake.URL+"/v3", "shop", "key")
+	ctx := context.Background()
+	req := CreatePaymentRequest{
+		IdempotenceKey: "key-1",
+		Amount:         15000,
+		Currency:       "RUB",
+		Description:    "NETI WASH: 1 стирок",
+		ReturnURL:      "http://localhost/wallet",
+		Metadata:       map[string]string{"payment_id": "1"},
+	}
+	created, err := provider.CreatePayment(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Status != StatusPending || created.Amount.Value != "150.00" || created.ConfirmationURL == "" {
+		t.Fatalf("created payment = %+v", created)
+	}
+
+	again, err := provider.CreatePayment(ctx, req)
+	if err != nil || again.ID != created.ID {
+		t.Fatalf("repeated CreatePayment = %+v, %v; want the same payment %s", again, err, created.ID)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.PostForm(fake.URL+"/checkout/"+created.ID, url.Values{"action": {"pay"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	r := <-webhooks
+	body := <-bodies
+	if err := VerifySignature(body, r.Header.Get(SignatureHeader), secret); err != nil {
+		t.Fatalf("webhook signature: %v", err)
+	}
+
+	paid, err := provider.GetPayment(ctx, created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paid.Status != StatusSucceeded || !paid.Paid {
+		t.Fatalf("payment after checkout = %+v", paid)
+	}
+
+	if _, err := provider.GetPayment(ctx, "missing"); err == nil {
+		t.Fatal("GetPayment for an unknown id should fail")
+	}
+}
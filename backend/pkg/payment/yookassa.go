@@ -0,0 +1,103 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type YooKassaProvider struct {
+	baseURL    string
+	shopID     string
+	secretKey  string
+	httpClient *http.Client
+}
+
+func NewYooKassaProvider(baseURL, shopID, secretKey string) *YooKassaProvider {
+	return &YooKassaProvider{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		shopID:     shopID,
+		secretKey:  secretKey,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *YooKassaProvider) CreatePayment(ctx context.Context, req CreatePaymentRequest) (*Payment, error) {
+	body := map[string]interface{}{
+		"amount": Amount{
+			Value:    FormatAmount(req.Amount),
+			Currency: req.Currency,
+		},
+		"capture": true,
+		"confirmation": Confirmation{
+			Type:      "redirect",
+			ReturnURL: req.ReturnURL,
+		},
+		"description": req.Description,
+		"metadata":    req.Metadata,
+	}
+
+	var res Payment
+	if err := p.do(ctx, http.MethodPost, "/payments", req.IdempotenceKey, body, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (p *YooKassaProvider) GetPayment(ctx context.Context, id string) (*Payment, error) {
+	var res Payment
+	if err := p.do(ctx, http.MethodGet, "/payments/"+url.PathEscape(id), "", nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (p *YooKassaProvider) do(ctx context.Context, method, path, idempotenceKey string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode payment request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(p.shopID, p.secretKey)
+	req.Header.Set("Content-Type", "application/json")
+	if idempotenceKey != "" {
+		req.Header.Set("Idempotence-Key", idempotenceKey)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("payment provider request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read payment provider response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("payment provider returned %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode payment provider response: %w", err)
+	}
+	if pay, ok := out.(*Payment); ok && pay.Confirmation != nil {
+		pay.ConfirmationURL = pay.Confirmation.ConfirmationURL
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+// Package storage хранит загруженные файлы (фото машин, фото к заявкам).
+// Ключи — относительные пути вида "machines/<name>.jpg".
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+var ErrNotFound = errors.New("file not found")
+
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get возвращает содержимое файла; закрыть его должен вызывающий.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// validKey отсекает ключи, которые могут выйти за пределы хранилища.
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
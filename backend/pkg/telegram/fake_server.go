@@ -0,0 +1,174 @@
+package telegram
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FakeServer — локальная заглушка Bot API для разработки и тестов.
+// Реализует getUpdates (с long polling) и sendMessage. Сообщения «от
+// пользователя» подкладываются через POST /fake/updates, отправленные ботом
+// читаются через GET /fake/messages.
+type FakeServer struct {
+	token string
+
+	mu       sync.Mutex
+	updateID int64
+	updates  []Update
+	sent     []Message
+	wake     chan struct{}
+	mux      *http.ServeMux
+}
+
+func NewFakeServer(token string) *FakeServer {
+	s := &FakeServer{
+		token: token,
+		wake:  make(chan struct{}),
+		mux:   http.NewServeMux(),
+	}
+	s.mux.HandleFunc("POST /{bot}/{method}", s.handleMethod)
+	s.mux.HandleFunc("POST /fake/updates", s.handleInject)
+	s.mux.HandleFunc("GET /fake/messages", s.handleMessages)
+	return s
+}
+
+func (s *FakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Inject добавляет входящее сообщение, как будто его написал пользователь.
+func (s *FakeServer) Inject(chatID int64, username, text string) Update {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.updateID++
+	u := Update{
+		UpdateID: s.updateID,
+		Message: &Message{
+			MessageID: s.updateID,
+			From:      &User{ID: chatID, Username: username},
+			Chat:      Chat{ID: chatID, Type: "private"},
+			Date:      time.Now().Unix(),
+			Text:      text,
+		},
+	}
+	s.updates = append(s.updates, u)
+	close(s.wake)
+	s.wake = make(chan struct{})
+	return u
+}
+
+// Sent возвращает сообщения, отправленные ботом в чат (0 — во все чаты).
+func (s *FakeServer) Sent(chatID int64) []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []Message
+	for _, m := range s.sent {
+		if chatID == 0 || m.Chat.ID == chatID {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func (s *FakeServer) handleMethod(w http.ResponseWriter, r *http.Request) {
+	if r.PathValue("bot") != "bot"+s.token {
+		writeResult(w, http.StatusUnauthorized, apiResponse{ErrorCode: 401, Description: "Unauthorized"})
+		return
+	}
+
+	var params struct {
+		Offset  int64  `json:"offset"`
+		Timeout int    `json:"timeout"`
+		ChatID  int64  `json:"chat_id"`
+		Text    string `json:"text"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		writeResult(w, http.StatusBadRequest, apiResponse{ErrorCode: 400, Description: "Bad Request: invalid JSON"})
+		return
+	}
+
+	switch r.PathValue("method") {
+	case "getUpdates":
+		s.getUpdates(w, r, params.Offset, params.Timeout)
+	case "sendMessage":
+		if params.ChatID == 0 || params.Text == "" {
+			writeResult(w, http.StatusBadRequest, apiResponse{ErrorCode: 400, Description: "Bad Request: chat_id and text are required"})
+			return
+		}
+		s.mu.Lock()
+		s.updateID++
+		msg := Message{MessageID: s.updateID, Chat: Chat{ID: params.ChatID, Type: "private"}, Date: time.Now().Unix(), Text: params.Text}
+		s.sent = append(s.sent, msg)
+		s.mu.Unlock()
+		writeOK(w, msg)
+	default:
+		writeResult(w, http.StatusNotFound, apiResponse{ErrorCode: 404, Description: "Not Found: method not found"})
+	}
+}
+
+func (s *FakeServer) getUpdates(w http.ResponseWriter, r *http.Request, offset int64, timeout int) {
+	deadline := time.After(time.Duration(timeout) * time.Second)
+	for {
+		s.mu.Lock()
+		// Как и настоящий API, offset подтверждает всё, что было до него.
+		kept := s.updates[:0]
+		for _, u := range s.updates {
+			if u.UpdateID >= offset {
+				kept = append(kept, u)
+			}
+		}
+		s.updates = kept
+		pending := append([]Update{}, s.updates...)
+		wake := s.wake
+		s.mu.Unlock()
+
+		if len(pending) > 0 || timeout <= 0 {
+			writeOK(w, pending)
+			return
+		}
+
+		select {
+		case <-wake:
+		case <-deadline:
+			writeOK(w, []Update{})
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (s *FakeServer) handleInject(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ChatID   int64  `json:"chat_id"`
+		Username string `json:"username"`
+		Text     string `json:"text"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ChatID == 0 || strings.TrimSpace(req.Text) == "" {
+		http.Error(w, "chat_id and text are required", http.StatusBadRequest)
+		return
+	}
+	writeOK(w, s.Inject(req.ChatID, req.Username, req.Text))
+}
+
+func (s *FakeServer) handleMessages(w http.ResponseWriter, r *http.Request) {
+	chatID, _ := strconv.ParseInt(r.URL.Query().Get("chat_id"), 10, 64)
+	writeOK(w, s.Sent(chatID))
+}
+
+func writeOK(w http.ResponseWriter, result interface{}) {
+	data, _ := json.Marshal(result)
+	writeResult(w, http.StatusOK, apiResponse{OK: true, Result: data})
+}
+
+func writeResult(w http.ResponseWriter, status int, res apiResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(res)
+}
@@ -10,21 +10,34 @@ import (
 )
 
 type EmailService struct {
-	smtpHost string
-	smtpPort string
-	from     string
-	password string
-	appURL   string
+	smtpHost  string
+	smtpPort  string
+	from      string
+	password  string
+	appURL    string
+	templates *EmailTemplates
 }
 
-func NewEmailService() *EmailService {
-	return &EmailService{
-		smtpHost: getEnv("SMTP_HOST", "smtp.gmail.com"),
-		smtpPort: getEnv("SMTP_PORT", "587"),
-		from:     getEnv("SMTP_FROM", "noreply@netiwash.local"),
-		password: getEnv("SMTP_PASSWORD", ""),
-		appURL:   getEnv("APP_URL", "http://localhost:3000"),
+// NewEmailService читает настройки SMTP и шаблоны писем; EMAIL_TEMPLATES_DIR
+// — каталог, файлы которого заменяют встроенные шаблоны.
+func NewEmailService() (*EmailService, error) {
+	templates, err := LoadEmailTemplates(getEnv("EMAIL_TEMPLATES_DIR", ""))
+	if err != nil {
+		return nil, err
 	}
+	return &EmailService{
+		smtpHost:  getEnv("SMTP_HOST", "smtp.gmail.com"),
+		smtpPort:  getEnv("SMTP_PORT", "587"),
+		from:      getEnv("SMTP_FROM", "noreply@netiwash.local"),
+		password:  getEnv("SMTP_PASSWORD", ""),
+		appURL:    getEnv("APP_URL", "http://localhost:3000"),
+		templates: templates,
+	}, nil
+}
+
+// From — адрес отправителя писем.
+func (e *EmailService) From() string {
+	return e.from
 }
 
 func getEnv(key, defaultValue string) string {
@@ -34,64 +47,47 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// SendEmail отправляет простое текстовое письмо.
 func (e *EmailService) SendEmail(to, subject, body string) error {
 	if e.password == "" {
 		fmt.Printf("📧 [EMAIL] To: %s\nSubject: %s\nBody:\n%s\n\n", to, subject, body)
 		return nil
 	}
 
-	msg := []byte(fmt.Sprintf("From: %s\r\n"+
-		"To: %s\r\n"+
-		"Subject: %s\r\n"+
-		"\r\n"+
-		"%s\r\n", e.from, to, subject, body))
-
-	auth := smtp.PlainAuth("", e.from, e.password, e.smtpHost)
-	addr := e.smtpHost + ":" + e.smtpPort
-
-	return smtp.SendMail(addr, auth, e.from, []string{to}, msg)
+	msg, err := buildTextMessage(e.from, to, subject, body)
+	if err != nil {
+		return err
+	}
+	return e.send(to, msg)
 }
 
-func (e *EmailService) SendVerificationEmail(to, token string) error {
-	subject := "NETI WASH - Подтверждение email"
-
-	verifyURL := fmt.Sprintf("%s/verify-email.html?token=%s", e.appURL, token)
-
-	body := fmt.Sprintf(`Привет!
-
-Спасибо за регистрацию в NETI WASH.
-
-Пожалуйста, подтвердите ваш email, перейдя по ссылке:
-%s
-
-Если вы не регистрировались в NETI WASH, проигнорируйте это письмо.
-
-С уважением,
-Команда NETI WASH`, verifyURL)
-
-	return e.SendEmail(to, subject, body)
+// SendTemplate отправляет письмо из шаблона name на языке locale с
+// текстовой и HTML-версией. В шаблонах доступен .AppURL, если data не
+// задаёт его сама.
+func (e *EmailService) SendTemplate(to, name, locale string, data map[string]any, attachments ...EmailAttachment) error {
+	vars := map[string]any{"AppURL": e.appURL}
+	for k, v := range data {
+		vars[k] = v
+	}
+	mail, err := e.templates.Render(name, locale, vars)
+	if err != nil {
+		return err
+	}
+	return e.SendMultipartEmail(to, mail.Subject, mail.Text, mail.HTML, attachments)
 }
 
-func (e *EmailService) SendPasswordResetEmail(to, token string) error {
-	subject := "NETI WASH - Восстановление пароля"
-
-	resetURL := fmt.Sprintf("%s/reset-password.html?token=%s", e.appURL, token)
-
-	body := fmt.Sprintf(`Привет!
-
-Вы запросили восстановление пароля для вашего аккаунта NETI WASH.
-
-Перейдите по ссылке для сброса пароля:
-%s
-
-Ссылка действительна в течение 1 часа.
-
-Если вы не запрашивали восстановление пароля, проигнорируйте это письмо.
+func (e *EmailService) SendVerificationEmail(to, locale, token string) error {
+	return e.SendTemplate(to, "verification", locale, map[string]any{"Token": token})
+}
 
-С уважением,
-Команда NETI WASH`, resetURL)
+func (e *EmailService) SendPasswordResetEmail(to, locale, token string) error {
+	return e.SendTemplate(to, "password_reset", locale, map[string]any{"Token": token})
+}
 
-	return e.SendEmail(to, subject, body)
+func (e *EmailService) send(to string, msg []byte) error {
+	auth := smtp.PlainAuth("", e.from, e.password, e.smtpHost)
+	addr := e.smtpHost + ":" + e.smtpPort
+	return smtp.SendMail(addr, auth, e.from, []string{to}, msg)
 }
 
 func GenerateSecureToken() (string, error) {
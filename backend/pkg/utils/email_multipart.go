@@ -0,0 +1,155 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"time"
+)
+
+// EmailAttachment — вложение письма.
+type EmailAttachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// SendMultipartEmail отправляет письмо с текстовой и HTML-версией и
+// вложениями. Без SMTP_PASSWORD письмо печатается в лог, как и SendEmail.
+func (e *EmailService) SendMultipartEmail(to, subject, text, html string, attachments []EmailAttachment) error {
+	if e.password == "" {
+		fmt.Printf("📧 [EMAIL] To: %s\nSubject: %s\nBody:\n%s\n", to, subject, text)
+		for _, a := range attachments {
+			fmt.Printf("📎 %s (%s, %d bytes)\n", a.Filename, a.ContentType, len(a.Data))
+		}
+		fmt.Println()
+		return nil
+	}
+
+	msg, err := buildMultipartMessage(e.from, to, subject, text, html, attachments)
+	if err != nil {
+		return err
+	}
+	return e.send(to, msg)
+}
+
+// emailSenderName — имя отправителя в заголовке From.
+const emailSenderName = "NETI WASH"
+
+// writeHeaders пишет заголовки письма. Не-ASCII в теме и имени
+// отправителя кодируется по RFC 2047.
+func writeHeaders(buf *bytes.Buffer, from, to, subject, contentType string) {
+	fmt.Fprintf(buf, "From: %s\r\n", (&mail.Address{Name: emailSenderName, Address: from}).String())
+	fmt.Fprintf(buf, "To: %s\r\n", (&mail.Address{Address: to}).String())
+	fmt.Fprintf(buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(buf, "Content-Type: %s\r\n", contentType)
+}
+
+// buildTextMessage собирает письмо из одной текстовой части.
+func buildTextMessage(from, to, subject, text string) ([]byte, error) {
+	var buf bytes.Buffer
+	writeHeaders(&buf, from, to, subject, "text/plain; charset=utf-8")
+	fmt.Fprintf(&buf, "Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	qp := quotedprintable.NewWriter(&buf)
+	if _, err := qp.Write([]byte(text)); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// buildMultipartMessage собирает multipart/alternative (text/plain +
+// text/html), а при вложениях — multipart/mixed из него и вложений в base64.
+func buildMultipartMessage(from, to, subject, text, html string, attachments []EmailAttachment) ([]byte, error) {
+	var buf bytes.Buffer
+
+	var altBuf bytes.Buffer
+	alt := multipart.NewWriter(&altBuf)
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	} {
+		w, err := alt.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := alt.Close(); err != nil {
+		return nil, err
+	}
+
+	if len(attachments) == 0 {
+		writeHeaders(&buf, from, to, subject, fmt.Sprintf("multipart/alternative; boundary=%q", alt.Boundary()))
+		buf.WriteString("\r\n")
+		buf.Write(altBuf.Bytes())
+		return buf.Bytes(), nil
+	}
+
+	mixed := multipart.NewWriter(&buf)
+	writeHeaders(&buf, from, to, subject, fmt.Sprintf("multipart/mixed; boundary=%q", mixed.Boundary()))
+	buf.WriteString("\r\n")
+
+	w, err := mixed.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {fmt.Sprintf("multipart/alternative; boundary=%q", alt.Boundary())},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(altBuf.Bytes()); err != nil {
+		return nil, err
+	}
+
+	for _, a := range attachments {
+		w, err := mixed.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {a.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64Lines(w, a.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeBase64Lines пишет base64 строками по 76 символов (RFC 2045).
+func writeBase64Lines(w interface{ Write([]byte) (int, error) }, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 0 {
+		n := 76
+		if len(encoded) < n {
+			n = len(encoded)
+		}
+		if _, err := fmt.Fprintf(w, "%s\r\n", encoded[:n]); err != nil {
+			return err
+		}
+		encoded = encoded[n:]
+	}
+	return nil
+}
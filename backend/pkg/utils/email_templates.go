@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"strings"
+	texttemplate "text/template"
+)
+
+// defaultEmailTemplates — шаблоны писем по умолчанию. Файлы называются
+// <письмо>.<язык>.txt и <письмо>.<язык>.html; layout.<язык>.* — общая
+// обёртка. Текстовый шаблон задаёт блоки subject и content, HTML — content;
+// оба могут переопределить footer.
+//
+//go:embed email_templates/*
+var defaultEmailTemplates embed.FS
+
+// defaultEmailLocale — язык, на который откатывается письмо без перевода.
+const defaultEmailLocale = "ru"
+
+var ErrEmailTemplateNotFound = errors.New("email template not found")
+
+// RenderedEmail — письмо, готовое к отправке.
+type RenderedEmail struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+type emailTemplate struct {
+	text *texttemplate.Template
+	html *template.Template
+}
+
+// EmailTemplates — разобранные шаблоны писем по имени и языку.
+type EmailTemplates struct {
+	templates map[string]*emailTemplate // "<письмо>.<язык>"
+}
+
+// LoadEmailTemplates разбирает шаблоны. Файл из dir заменяет одноимённый
+// встроенный, так можно переписать любое письмо или layout, не пересобирая
+// сервер. Пустой dir — только встроенные шаблоны.
+func LoadEmailTemplates(dir string) (*EmailTemplates, error) {
+	embedded, err := fs.Sub(defaultEmailTemplates, "email_templates")
+	if err != nil {
+		return nil, err
+	}
+	sources := []fs.FS{embedded}
+	if dir != "" {
+		if _, err := os.Stat(dir); err != nil {
+			return nil, fmt.Errorf("email templates dir: %w", err)
+		}
+		sources = append([]fs.FS{os.DirFS(dir)}, sources...)
+	}
+
+	read := func(name string) (string, error) {
+		for _, src := range sources {
+			data, err := fs.ReadFile(src, name)
+			if err == nil {
+				return string(data), nil
+			}
+			if !errors.Is(err, fs.ErrNotExist) {
+				return "", err
+			}
+		}
+		return "", fmt.Errorf("%w: %s", ErrEmailTemplateNotFound, name)
+	}
+
+	// Письмо существует, если есть его текстовая версия хотя бы в одном источнике.
+	names := map[string]bool{}
+	for _, src := range sources {
+		files, err := fs.Glob(src, "*.txt")
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			name := strings.TrimSuffix(f, ".txt")
+			if !strings.HasPrefix(name, "layout.") && strings.Count(name, ".") == 1 {
+				names[name] = true
+			}
+		}
+	}
+
+	t := &EmailTemplates{templates: make(map[string]*emailTemplate, len(names))}
+	for name := range names {
+		locale := name[strings.Index(name, ".")+1:]
+		parsed := &emailTemplate{}
+
+		text := texttemplate.New(name).Option("missingkey=error")
+		for _, f := range []string{"layout." + locale + ".txt", name + ".txt"} {
+			src, err := read(f)
+			if err != nil {
+				return nil, err
+			}
+			if text, err = text.Parse(src); err != nil {
+				return nil, fmt.Errorf("email template %s: %w", f, err)
+			}
+		}
+		parsed.text = text
+
+		html := template.New(name).Option("missingkey=error")
+		for _, f := range []string{"layout." + locale + ".html", name + ".html"} {
+			src, err := read(f)
+			if err != nil {
+				return nil, err
+			}
+			if html, err = html.Parse(src); err != nil {
+				return nil, fmt.Errorf("email template %s: %w", f, err)
+			}
+		}
+		parsed.html = html
+
+		t.templates[name] = parsed
+	}
+	return t, nil
+}
+
+// Render собирает письмо name на языке locale; письма без перевода уходят
+// на языке по умолчанию. Шаблонам доступны data и .Subject.
+func (t *EmailTemplates) Render(name, locale string, data map[string]any) (*RenderedEmail, error) {
+	tmpl, ok := t.templates[name+"."+locale]
+	if !ok {
+		tmpl, ok = t.templates[name+"."+defaultEmailLocale]
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrEmailTemplateNotFound, name)
+	}
+
+	vars := make(map[string]any, len(data)+1)
+	for k, v := range data {
+		vars[k] = v
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", vars); err != nil {
+		return nil, fmt.Errorf("email %s: %w", name, err)
+	}
+	vars["Subject"] = strings.Join(strings.Fields(subject.String()), " ")
+	if err := tmpl.text.ExecuteTemplate(&text, "layout", vars); err != nil {
+		return nil, fmt.Errorf("email %s: %w", name, err)
+	}
+	if err := tmpl.html.ExecuteTemplate(&html, "layout", vars); err != nil {
+		return nil, fmt.Errorf("email %s: %w", name, err)
+	}
+
+	return &RenderedEmail{
+		Subject: vars["Subject"].(string),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		HTML:    html.String(),
+	}, nil
+}
@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CalendarEvent — событие для iCalendar-вложения (RFC 5545).
+type CalendarEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	// Organizer и Attendee — адреса почты; без них календари не применяют
+	// METHOD:REQUEST/CANCEL к уже добавленному событию.
+	Organizer string
+	Attendee  string
+	Start     time.Time
+	End       time.Time
+	// Sequence должен расти при каждом изменении события, иначе календарь
+	// не применит перенос или отмену.
+	Sequence  int
+	Cancelled bool
+}
+
+// ICSMethod — METHOD календаря для события: REQUEST или CANCEL.
+func (e CalendarEvent) ICSMethod() string {
+	if e.Cancelled {
+		return "CANCEL"
+	}
+	return "REQUEST"
+}
+
+// BuildICS собирает VCALENDAR с одним VEVENT.
+func BuildICS(e CalendarEvent) []byte {
+	const stamp = "20060102T150405Z"
+	status := "CONFIRMED"
+	if e.Cancelled {
+		status = "CANCELLED"
+	}
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//NETI WASH//Bookings//RU",
+		"CALSCALE:GREGORIAN",
+		"METHOD:" + e.ICSMethod(),
+		"BEGIN:VEVENT",
+		"UID:" + e.UID,
+		"DTSTAMP:" + time.Now().UTC().Format(stamp),
+		"DTSTART:" + e.Start.UTC().Format(stamp),
+		"DTEND:" + e.End.UTC().Format(stamp),
+		fmt.Sprintf("SEQUENCE:%d", e.Sequence),
+		"STATUS:" + status,
+		"SUMMARY:" + escapeICS(e.Summary),
+	}
+	if e.Organizer != "" {
+		lines = append(lines, "ORGANIZER;CN=NETI WASH:mailto:"+e.Organizer)
+	}
+	if e.Attendee != "" {
+		lines = append(lines, "ATTENDEE;ROLE=REQ-PARTICIPANT;PARTSTAT=ACCEPTED:mailto:"+e.Attendee)
+	}
+	if e.Description != "" {
+		lines = append(lines, "DESCRIPTION:"+escapeICS(e.Description))
+	}
+	if e.Location != "" {
+		lines = append(lines, "LOCATION:"+escapeICS(e.Location))
+	}
+	lines = append(lines, "END:VEVENT", "END:VCALENDAR")
+
+	var b strings.Builder
+	for _, l := range lines {
+		b.WriteString(foldICSLine(l))
+		b.WriteString("\r\n")
+	}
+	return []byte(b.String())
+}
+
+func escapeICS(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// foldICSLine переносит строки длиннее 75 байт, не разрывая символы UTF-8.
+func foldICSLine(line string) string {
+	if len(line) <= 75 {
+		return line
+	}
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > 75 {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
+}
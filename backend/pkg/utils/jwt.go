@@ -7,6 +7,13 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// streamAudience помечает билеты на поток событий, чтобы их нельзя было
+// предъявить вместо обычного токена.
+const streamAudience = "events-stream"
+
+// StreamTicketTTL — сколько живёт билет на подключение к потоку событий.
+const StreamTicketTTL = time.Minute
+
 type Claims struct {
 	UserID int    `json:"user_id"`
 	Role   string `json:"role"`
@@ -27,13 +34,47 @@ func GenerateToken(userID int, role string, secret string) (string, error) {
 	return token.SignedString([]byte(secret))
 }
 
+// GenerateStreamTicket выпускает билет для EventSource: браузер не умеет
+// передавать заголовки, и билет уходит в URL, а значит и в логи. Поэтому он
+// живёт минуту и годится только для подключения к потоку.
+func GenerateStreamTicket(userID int, role string, secret string) (string, error) {
+	claims := Claims{
+		UserID: userID,
+		Role:   role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{streamAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(StreamTicketTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(secret))
+}
+
+// ParseToken принимает только обычные токены входа, билеты на поток — нет.
 func ParseToken(tokenString string, secret string) (*Claims, error) {
+	claims, err := parseClaims(tokenString, secret)
+	if err != nil {
+		return nil, err
+	}
+	if len(claims.Audience) > 0 {
+		return nil, fmt.Errorf("invalid token")
+	}
+	return claims, nil
+}
+
+func ParseStreamTicket(ticket string, secret string) (*Claims, error) {
+	return parseClaims(ticket, secret, jwt.WithAudience(streamAudience))
+}
+
+func parseClaims(tokenString string, secret string, opts ...jwt.ParserOption) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return []byte(secret), nil
-	})
+	}, opts...)
 
 	if err != nil {
 		return nil, err
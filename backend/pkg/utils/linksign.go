@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// SigningKey — секрет с идентификатором. Идентификатор попадает в ссылку,
+// чтобы при проверке выбрать нужный ключ.
+type SigningKey struct {
+	ID     string
+	Secret string
+}
+
+// LinkSigner подписывает ссылки на машины (QR-коды) и кнопки в уведомлениях. Подписывает всегда
+// первый ключ, проверка принимает любой из списка — так ключ можно
+// сменить, не перепечатывая сразу все наклейки.
+type LinkSigner struct {
+	keys []SigningKey
+}
+
+// ParseSigningKeys разбирает строку вида "v2:secret2,v1:secret1".
+func ParseSigningKeys(spec string) ([]SigningKey, error) {
+	var keys []SigningKey
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		id, secret, ok := strings.Cut(pair, ":")
+		if !ok || id == "" || secret == "" {
+			return nil, errors.New("signing key must look like id:secret")
+		}
+		keys = append(keys, SigningKey{ID: id, Secret: secret})
+	}
+	return keys, nil
+}
+
+func NewLinkSigner(keys []SigningKey) (*LinkSigner, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("at least one signing key is required")
+	}
+	return &LinkSigner{keys: keys}, nil
+}
+
+// SignMachine возвращает идентификатор ключа и подпись для машины.
+func (s *LinkSigner) SignMachine(machineID int) (keyID, signature string) {
+	return s.Sign("machine:" + strconv.Itoa(machineID))
+}
+
+func (s *LinkSigner) VerifyMachine(machineID int, keyID, signature string) bool {
+	return s.Verify("machine:"+strconv.Itoa(machineID), keyID, signature)
+}
+
+// Sign подписывает произвольную строку текущим ключом. Вызывающий сам
+// добавляет к данным префикс, чтобы подписи разного назначения не
+// подходили друг к другу.
+func (s *LinkSigner) Sign(data string) (keyID, sig string) {
+	k := s.keys[0]
+	return k.ID, hmacSignature(k.Secret, data)
+}
+
+func (s *LinkSigner) Verify(data, keyID, sig string) bool {
+	for _, k := range s.keys {
+		if k.ID == keyID {
+			return hmac.Equal([]byte(hmacSignature(k.Secret, data)), []byte(sig))
+		}
+	}
+	return false
+}
+
+func hmacSignature(secret, data string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(data))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+}
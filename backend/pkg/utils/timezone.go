@@ -0,0 +1,19 @@
+package utils
+
+import "time"
+
+// LaundryLocation возвращает часовой пояс общежитий. Время броней хранится
+// в БД без зоны, как локальное время Новосибирска.
+func LaundryLocation() *time.Location {
+	loc, err := time.LoadLocation("Asia/Novosibirsk")
+	if err != nil {
+		return time.FixedZone("UTC+7", 7*60*60)
+	}
+	return loc
+}
+
+// InLaundryLocation переносит время, прочитанное из колонки TIMESTAMP
+// (pgx отдаёт его как UTC), в часовой пояс общежитий без сдвига часов.
+func InLaundryLocation(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), LaundryLocation())
+}
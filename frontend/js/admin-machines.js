@@ -53,7 +53,7 @@ async function loadMachinesList() {
         machines.forEach(m => {
             const el = document.createElement('div');
             el.className = 'bg-white rounded-2xl p-5 shadow-card border border-gray-100 flex flex-col items-center text-center hover:shadow-lg transition-all';
-            const info = statusInfo[m.status] || statusInfo.free;
+            const info = statusInfo[m.operational_status] || statusInfo.free;
 
             el.innerHTML = `
                 <div class="w-20 h-20 ${info.iconBg} rounded-full flex items-center justify-center text-2xl mb-4">
@@ -64,10 +64,14 @@ async function loadMachinesList() {
                     <span class="w-2 h-2 rounded-full bg-current mr-2 opacity-70"></span>${info.label}
                 </div>
                 <div class="flex gap-3 w-full mt-auto">
-                    <button onclick="openEditMachineModal(${m.id}, '${m.name}', '${m.status}')"
+                    <button onclick="openEditMachineModal(${m.id}, '${m.name}', '${m.operational_status}')"
                         class="flex-1 py-3 rounded-xl bg-primary text-white text-sm font-bold shadow-lg shadow-primary/20 hover:bg-[#06965a] flex items-center justify-center gap-2 transition-transform active:scale-95">
                         Изм.
                     </button>
+                    <button onclick="openMachineQR(${m.id})"
+                        class="py-3 px-4 rounded-xl bg-white text-dark border border-gray-200 text-sm font-bold hover:bg-gray-50 flex items-center justify-center transition-transform active:scale-95">
+                        QR
+                    </button>
                     <button onclick="deleteMachine(${m.id})"
                         class="flex-1 py-3 rounded-xl bg-white text-accent border border-accent/20 text-sm font-bold hover:bg-red-50 flex items-center justify-center gap-2 transition-transform active:scale-95">
                         Удалить
@@ -95,6 +99,7 @@ window.openMachineModal = (isEdit = false, id = null, name = '', status = 'free'
     document.getElementById('machineId').value = id || '';
     document.getElementById('machineName').value = name;
     document.getElementById('machineStatus').value = status;
+    document.getElementById('machineStatus').dataset.original = isEdit ? status : '';
 
     modalTitle.innerText = isEdit ? 'Редактировать машинку' : 'Новая машинка';
 
@@ -123,16 +128,27 @@ window.saveMachine = async (e) => {
 
     const id = document.getElementById('machineId').value;
     const name = document.getElementById('machineName').value;
-    const status = document.getElementById('machineStatus').value;
+    const statusSelect = document.getElementById('machineStatus');
+    const status = statusSelect.value;
 
     const isEdit = !!id;
 
+    // Причину спрашиваем, только если статус действительно меняется на ремонт.
+    let reason = '';
+    if (status === 'repair' && status !== statusSelect.dataset.original) {
+        reason = (prompt('Причина ремонта') || '').trim();
+        if (!reason) {
+            showToast('Укажите причину перевода в ремонт', true);
+            return;
+        }
+    }
+
     try {
         if (isEdit) {
-            await api.put(`/machines/${id}`, { name, status });
+            await api.put(`/machines/${id}`, { name, status, reason });
             showToast('Машинка обновлена');
         } else {
-            await api.post('/machines', { name, status });
+            await api.post('/machines', { name, status, reason });
             showToast('Машинка создана');
         }
 
@@ -145,22 +161,35 @@ window.saveMachine = async (e) => {
 
 window.deleteMachine = async (id) => {
     if (typeof showModal !== 'undefined') {
-        showModal('Удаление', `Удалить машинку #${id}?`, async () => {
+        showModal('Вывод из эксплуатации', `Вывести машинку #${id} из эксплуатации? Будущие брони будут отменены.`, async () => {
             try {
                 await api.delete(`/machines/${id}`);
-                showToast('Машинка удалена');
+                showToast('Машинка выведена из эксплуатации');
                 loadMachinesList();
             } catch (e) {
                 showToast(e.message, true);
             }
         });
-    } else if (confirm(`Удалить машинку #${id}?`)) {
+    } else if (confirm(`Вывести машинку #${id} из эксплуатации? Будущие брони будут отменены.`)) {
         try {
             await api.delete(`/machines/${id}`);
-            showToast('Машинка удалена');
+            showToast('Машинка выведена из эксплуатации');
             loadMachinesList();
         } catch (e) {
             showToast(e.message, true);
         }
     }
 }
+
+window.openMachineQR = async (id) => {
+    try {
+        const response = await fetch(`/api/admin/machines/${id}/qr.png`, {
+            headers: { 'Authorization': `Bearer ${api.getToken()}` }
+        });
+        if (!response.ok) throw new Error(`Error ${response.status}`);
+        const blob = await response.blob();
+        window.open(URL.createObjectURL(blob), '_blank');
+    } catch (e) {
+        alert('Не удалось получить QR-код: ' + e.message);
+    }
+};
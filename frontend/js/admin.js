@@ -34,7 +34,7 @@ async function loadStatistics() {
             const date = new Date(b.start_time);
             return date > new Date() && b.status !== 'completed';
         }).length;
-        const machinesInRepair = machines.filter(m => m.status === 'repair').length;
+        const machinesInRepair = machines.filter(m => m.operational_status === 'repair').length;
         const today = new Date();
         today.setHours(0, 0, 0, 0);
         const todayBookings = bookings.filter(b => {
@@ -101,7 +101,7 @@ async function loadMachines() {
             const el = document.createElement('div');
             el.className = 'flex justify-between items-center bg-gray-50 p-4 rounded-xl border border-gray-200 hover:border-primary transition-colors';
 
-            const info = statusInfo[m.status] || statusInfo.free;
+            const info = statusInfo[m.operational_status] || statusInfo.free;
 
             el.innerHTML = `
                 <div class="flex items-center gap-3">
@@ -114,9 +114,9 @@ async function loadMachines() {
                 <div class="flex gap-2">
                     <select onchange="toggleMachineStatus(${m.id}, this.value)" class="text-xs font-semibold border border-gray-300 px-3 py-1.5 rounded-lg focus:outline-none focus:border-primary">
                         <option value="">Изменить...</option>
-                        ${m.status !== 'free' ? '<option value="free">Свободна</option>' : ''}
-                        ${m.status !== 'busy' ? '<option value="busy">Занята</option>' : ''}
-                        ${m.status !== 'repair' ? '<option value="repair">Ремонт</option>' : ''}
+                        ${m.operational_status !== 'free' ? '<option value="free">Свободна</option>' : ''}
+                        ${m.operational_status !== 'busy' ? '<option value="busy">Занята</option>' : ''}
+                        ${m.operational_status !== 'repair' ? '<option value="repair">Ремонт</option>' : ''}
                     </select>
                 </div>
             `;
@@ -159,7 +159,9 @@ function handleLoginSuccess(response) {
                 name: response.user.name
             });
         }
-        window.location.href = 'main.html';
+        const afterLogin = sessionStorage.getItem('netiwash_after_login');
+        sessionStorage.removeItem('netiwash_after_login');
+        window.location.href = afterLogin || 'main.html';
     } else {
         throw new Error('Token not received');
     }
@@ -98,28 +98,13 @@ document.addEventListener('DOMContentLoaded', async () => {
     }
 
     if (window.location.pathname.includes('main.html')) {
-        try {
-            const bookings = await api.get('/bookings');
-            const activeBookings = bookings.filter(b => b.status === 'active');
-            const activeCountEl = document.querySelector('p.text-sm span.font-bold.text-primary');
-            if (activeCountEl) {
-                activeCountEl.innerText = activeBookings.length;
-            }
-        } catch (error) {
-            console.error('Failed to load bookings count:', error);
-        }
-    }
+        await loadActiveBookingsCount();
 
-    if (window.location.pathname.includes('main.html')) {
         const machinesContainer = document.querySelector('.grid.grid-cols-2');
         if (machinesContainer) {
             machinesContainer.innerHTML = '<div class="col-span-2 text-center py-4 text-gray-400">Загрузка...</div>';
-            try {
-                const machines = await api.get('/machines');
-                renderMachines(machines, machinesContainer);
-            } catch (error) {
-                machinesContainer.innerHTML = '<div class="col-span-2 text-center py-4 text-red-500">Ошибка загрузки.</div>';
-            }
+            await loadMachines(machinesContainer);
+            subscribeToEvents(machinesContainer);
         }
     }
 
@@ -127,6 +112,96 @@ document.addEventListener('DOMContentLoaded', async () => {
 });
 
 
+let currentMachines = [];
+
+async function loadActiveBookingsCount() {
+    try {
+        const bookings = await api.get('/bookings');
+        const activeBookings = bookings.filter(b => b.status === 'active');
+        const activeCountEl = document.querySelector('p.text-sm span.font-bold.text-primary');
+        if (activeCountEl) {
+            activeCountEl.innerText = activeBookings.length;
+        }
+    } catch (error) {
+        console.error('Failed to load bookings count:', error);
+    }
+}
+
+async function loadMachines(container) {
+    try {
+        currentMachines = await api.get('/machines');
+        renderMachines(currentMachines, container);
+    } catch (error) {
+        container.innerHTML = '<div class="col-span-2 text-center py-4 text-red-500">Ошибка загрузки.</div>';
+    }
+}
+
+// Живые обновления: состояние машины приходит целиком, остальные события
+// (брони, списание машин, reset) — повод перезапросить список.
+function subscribeToEvents(container) {
+    if (!window.EventSource) return;
+
+    let reloadTimer = null;
+    const scheduleReload = () => {
+        clearTimeout(reloadTimer);
+        reloadTimer = setTimeout(() => {
+            loadMachines(container);
+            loadActiveBookingsCount();
+        }, 300);
+    };
+
+    // Токен в URL попал бы в логи, поэтому поток открывается по билету на
+    // минуту. Браузер переподключается с тем же URL сам; когда билет истёк,
+    // соединение закрывается, и мы открываем его заново с новым билетом.
+    let source = null;
+    let lastEventId = '';
+    const track = (handler) => (e) => {
+        if (e.lastEventId) lastEventId = e.lastEventId;
+        handler(e);
+    };
+
+    const connect = async () => {
+        let ticket;
+        try {
+            ({ ticket } = await api.post('/events/ticket'));
+        } catch (error) {
+            setTimeout(connect, 5000);
+            return;
+        }
+
+        const params = new URLSearchParams({ ticket });
+        if (lastEventId) params.set('lastEventId', lastEventId);
+        source = new EventSource('/api/events/stream?' + params.toString());
+
+        source.addEventListener('machine.updated', track((e) => {
+            const machine = JSON.parse(e.data).data;
+            const idx = currentMachines.findIndex(m => m.id === machine.id);
+            if (idx === -1) {
+                currentMachines.push(machine);
+            } else {
+                currentMachines[idx] = machine;
+            }
+            renderMachines(currentMachines, container);
+        }));
+
+        ['machine.removed', 'booking.created', 'booking.rescheduled', 'booking.extended', 'booking.cancelled', 'booking.completed', 'booking.status', 'reset']
+            .forEach(type => source.addEventListener(type, track(scheduleReload)));
+
+        if (typeof NotificationManager !== 'undefined') {
+            source.addEventListener('notification.created', track(() => NotificationManager.refresh()));
+        }
+
+        source.onerror = () => {
+            if (source.readyState === EventSource.CLOSED) {
+                setTimeout(connect, 1000);
+            }
+        };
+    };
+
+    connect();
+    window.addEventListener('beforeunload', () => source && source.close());
+}
+
 function renderMachines(machines, container) {
     container.innerHTML = '';
     machines.forEach(machine => {
@@ -134,12 +209,14 @@ function renderMachines(machines, container) {
 
         let statusConfig = {
             free: { color: 'green', text: 'Свободна', bg: '#E8F8F3', iconColor: 'text-primary', btn: 'BgPrimary' },
-            busy: { color: 'red', text: 'Занята', bg: 'bg-red-50', iconColor: 'text-accent', btn: 'Disabled' },
+            reserved: { color: 'red', text: 'Забронирована', bg: 'bg-red-50', iconColor: 'text-accent', btn: 'Disabled' },
+            in_use: { color: 'red', text: 'Идёт стирка', bg: 'bg-red-50', iconColor: 'text-accent', btn: 'Disabled' },
+            awaiting_pickup: { color: 'red', text: 'Ждёт владельца', bg: 'bg-red-50', iconColor: 'text-accent', btn: 'Disabled' },
             repair: { color: 'gray', text: 'Ремонт', bg: 'bg-gray-100', iconColor: 'text-gray-400', btn: 'Disabled' }
         };
 
         const config = statusConfig[machine.status] || statusConfig.free;
-        const opacity = machine.status !== 'free' ? (machine.status === 'busy' ? 'opacity-70' : 'opacity-60 grayscale') : '';
+        const opacity = machine.status !== 'free' ? (machine.status !== 'repair' ? 'opacity-70' : 'opacity-60 grayscale') : '';
         const statusHex = config.color === 'green' ? '#07AB66' : (config.color === 'red' ? '#DE093B' : '#9C9C9C');
 
         let btnContent = `<div class="w-full py-2.5 rounded-xl bg-primary text-white text-sm font-bold shadow-lg shadow-primary/20">Занять</div>`;
@@ -160,6 +237,8 @@ function renderMachines(machines, container) {
                 <span class="w-1.5 h-1.5 rounded-full" style="background-color: ${statusHex}"></span>
                 <span class="text-xs font-bold" style="color: ${statusHex}">${config.text}</span>
             </div>
+            ${machineDetails(machine) ? `<div class="text-[11px] text-gray-400 -mt-3 mb-3">${machineDetails(machine)}</div>` : ''}
+            ${machine.next_free_at ? `<div class="text-[11px] text-gray-400 -mt-3 mb-3">освободится через ${formatCountdown(machine.next_free_at)}</div>` : ''}
             ${btnContent}
         `;
 
@@ -171,6 +250,20 @@ function renderMachines(machines, container) {
     });
 }
 
+function machineDetails(machine) {
+    const parts = [];
+    if (machine.capacity_kg) parts.push(`${machine.capacity_kg} кг`);
+    if (machine.floor !== null && machine.floor !== undefined) parts.push(`${machine.floor} этаж`);
+    if (machine.position) parts.push(machine.position);
+    return parts.join(' · ');
+}
+
+function formatCountdown(iso) {
+    const minutes = Math.max(0, Math.ceil((new Date(iso) - new Date()) / 60000));
+    if (minutes < 60) return `${minutes} мин`;
+    return `${Math.floor(minutes / 60)} ч ${minutes % 60} мин`;
+}
+
 
 function initBookingPickers() {
     const sheet = document.getElementById('bookingSheet');
@@ -6,33 +6,17 @@ const NotificationManager = {
         BOOKING: 'booking'
     },
 
-    getNotifications() {
-        const stored = localStorage.getItem('netiwash_notifications');
-        if (stored) {
-            return JSON.parse(stored);
-        }
-        return [
-            {
-                id: 1,
-                type: 'success',
-                title: 'Стирка завершена!',
-                message: 'Машинка #2 закончила работу. Заберите вещи.',
-                time: Date.now() - 10 * 60 * 1000,
-                read: false
-            },
-            {
-                id: 2,
-                type: 'warning',
-                title: 'Технические работы',
-                message: 'Завтра с 10:00 до 12:00 прачечная закрыта.',
-                time: Date.now() - 24 * 60 * 60 * 1000,
-                read: false
-            }
-        ];
-    },
-
-    saveNotifications(notifications) {
-        localStorage.setItem('netiwash_notifications', JSON.stringify(notifications));
+    // Входящие хранятся на сервере: GET /api/notifications
+    notifications: [],
+    unread: 0,
+
+    KIND_TYPES: {
+        completion: 'success',
+        reminder: 'booking',
+        booking: 'booking',
+        waitlist: 'info',
+        announcement: 'warning',
+        system: 'warning'
     },
 
     isEnabled() {
@@ -45,48 +29,60 @@ const NotificationManager = {
     },
 
     getUnreadCount() {
-        const notifications = this.getNotifications();
-        return notifications.filter(n => !n.read).length;
+        return this.unread;
     },
 
-    markAsRead(id) {
-        const notifications = this.getNotifications();
-        const notification = notifications.find(n => n.id === id);
-        if (notification) {
-            notification.read = true;
-            this.saveNotifications(notifications);
+    async refresh() {
+        if (typeof api === 'undefined' || !api.getToken()) return;
+
+        try {
+            const page = await api.get('/notifications');
+            this.notifications = Array.isArray(page.notifications) ? page.notifications : [];
+            this.unread = page.unread || 0;
             this.updateBadge();
             this.renderNotifications();
+        } catch (error) {
+            console.error('[Notifications] Error:', error);
         }
     },
 
-    markAllAsRead() {
-        const notifications = this.getNotifications();
-        notifications.forEach(n => n.read = true);
-        this.saveNotifications(notifications);
-        this.updateBadge();
-        this.renderNotifications();
+    async markAsRead(id) {
+        const notification = this.notifications.find(n => n.id === id);
+        if (!notification || notification.read_at) return;
+
+        try {
+            await api.post(`/notifications/${id}/read`, {});
+            notification.read_at = new Date().toISOString();
+            this.unread = Math.max(0, this.unread - 1);
+            this.updateBadge();
+            this.renderNotifications();
+        } catch (error) {
+            console.error('[Notifications] Error:', error);
+        }
     },
 
-    addNotification(type, title, message) {
-        if (!this.isEnabled()) return;
-
-        const notifications = this.getNotifications();
-        const newNotification = {
-            id: Date.now(),
-            type: type,
-            title: title,
-            message: message,
-            time: Date.now(),
-            read: false
-        };
-        notifications.unshift(newNotification);
-        if (notifications.length > 20) {
-            notifications.pop();
+    async markAllAsRead() {
+        try {
+            await api.post('/notifications/read-all', {});
+            await this.refresh();
+        } catch (error) {
+            console.error('[Notifications] Error:', error);
+        }
+    },
+
+    async clearAll() {
+        try {
+            await api.delete('/notifications');
+            await this.refresh();
+        } catch (error) {
+            console.error('[Notifications] Error:', error);
         }
-        this.saveNotifications(notifications);
-        this.updateBadge();
-        this.renderNotifications();
+    },
+
+    escapeHtml(text) {
+        const div = document.createElement('div');
+        div.textContent = text == null ? '' : String(text);
+        return div.innerHTML;
     },
 
     updateBadge() {
@@ -146,7 +142,7 @@ const NotificationManager = {
         const container = document.getElementById('notificationsContainer');
         if (!container) return;
 
-        const notifications = this.getNotifications();
+        const notifications = this.notifications;
 
         if (notifications.length === 0) {
             container.innerHTML = `
@@ -162,12 +158,12 @@ const NotificationManager = {
         }
 
         container.innerHTML = notifications.map(n => {
-            const config = this.getTypeConfig(n.type);
-            const readClass = n.read ? 'opacity-60' : '';
+            const config = this.getTypeConfig(this.KIND_TYPES[n.kind]);
+            const readClass = n.read_at ? 'opacity-60' : '';
 
             return `
                 <div class="flex items-start gap-4 p-4 ${config.bg} rounded-2xl border ${config.border} ${readClass} cursor-pointer transition-opacity hover:opacity-80"
-                     onclick="NotificationManager.markAsRead(${n.id})">
+                     onclick="NotificationManager.markAsRead(${Number(n.id)})">
                     <div class="h-10 w-10 bg-white rounded-full flex items-center justify-center ${config.iconBg} shadow-sm flex-shrink-0">
                         <svg width="20" height="20" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round">
                             ${config.icon}
@@ -175,11 +171,11 @@ const NotificationManager = {
                     </div>
                     <div class="flex-1">
                         <div class="flex justify-between items-start">
-                            <h4 class="font-bold text-dark text-sm">${n.title}</h4>
-                            ${!n.read ? '<div class="w-2 h-2 bg-accent rounded-full flex-shrink-0 mt-1"></div>' : ''}
+                            <h4 class="font-bold text-dark text-sm">${this.escapeHtml(n.title)}</h4>
+                            ${!n.read_at ? '<div class="w-2 h-2 bg-accent rounded-full flex-shrink-0 mt-1"></div>' : ''}
                         </div>
-                        <p class="text-xs text-gray-sec mt-1">${n.message}</p>
-                        <span class="text-[10px] text-gray-400 mt-2 block">${this.formatTimeAgo(n.time)}</span>
+                        <p class="text-xs text-gray-sec mt-1">${this.escapeHtml(n.body)}</p>
+                        <span class="text-[10px] text-gray-400 mt-2 block">${this.formatTimeAgo(Date.parse(n.created_at))}</span>
                     </div>
                 </div>
             `;
@@ -189,6 +185,7 @@ const NotificationManager = {
     init() {
         this.updateBadge();
         this.renderNotifications();
+        this.refresh();
     }
 };
 
@@ -305,7 +302,7 @@ const BookingWatcher = {
         const machineName = booking.machine_name || `Машинка #${booking.machine_id}`;
         const title = 'Стирка завершена! 🧺';
         const message = `${machineName} закончила работу. Заберите вещи!`;
-        NotificationManager.addNotification('success', title, message);
+        NotificationManager.refresh();
         if (typeof PWAManager !== 'undefined' && 'Notification' in window) {
             try {
                 await PWAManager.sendLocalNotification(title, message, '/bookings.html');
@@ -336,22 +333,27 @@ const BookingWatcher = {
             if (!keyRes || !keyRes.publicKey) return;
             const convertedKey = this.urlBase64ToUint8Array(keyRes.publicKey);
 
-            const existingSub = await reg.pushManager.getSubscription();
-            if (existingSub) {
-                console.log('[WebPush] Unsubscribing old subscription...');
-                await existingSub.unsubscribe();
+            // Подписка на тот же ключ просто обновляется на сервере; после
+            // ротации ключа сервер отдаёт новый, и браузер переподписывается
+            let sub = await reg.pushManager.getSubscription();
+            if (sub && !this.sameKey(sub.options.applicationServerKey, convertedKey)) {
+                console.log('[WebPush] VAPID key changed, resubscribing...');
+                await api.request('/subscribe', 'DELETE', { endpoint: sub.endpoint }).catch(() => {});
+                await sub.unsubscribe();
+                sub = null;
+            }
+            if (!sub) {
+                sub = await reg.pushManager.subscribe({
+                    userVisibleOnly: true,
+                    applicationServerKey: convertedKey
+                });
             }
-
-            const sub = await reg.pushManager.subscribe({
-                userVisibleOnly: true,
-                applicationServerKey: convertedKey
-            });
-
 
             const subJSON = JSON.parse(JSON.stringify(sub));
             await api.post('/subscribe', {
                 endpoint: subJSON.endpoint,
-                keys: subJSON.keys
+                keys: subJSON.keys,
+                applicationServerKey: keyRes.publicKey
             });
 
             console.log('[WebPush] Subscribed successfully');
@@ -360,6 +362,12 @@ const BookingWatcher = {
         }
     },
 
+    sameKey(buffer, key) {
+        if (!buffer) return false;
+        const current = new Uint8Array(buffer);
+        return current.length === key.length && current.every((b, i) => b === key[i]);
+    },
+
     urlBase64ToUint8Array(base64String) {
         const padding = '='.repeat((4 - base64String.length % 4) % 4);
         const base64 = (base64String + padding)
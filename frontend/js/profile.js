@@ -42,8 +42,58 @@ document.addEventListener('DOMContentLoaded', async () => {
         });
     }
 
+    initTelegramLink();
 });
 
+async function initTelegramLink() {
+    const statusEl = document.getElementById('telegramStatus');
+    const btn = document.getElementById('telegramBtn');
+    const codeEl = document.getElementById('telegramCode');
+    if (!statusEl || !btn || !codeEl) return;
+
+    let linked = false;
+    const refresh = async () => {
+        try {
+            const res = await api.get('/me/telegram');
+            linked = res.linked;
+            statusEl.textContent = linked
+                ? 'Привязан' + (res.account.username ? ' (@' + res.account.username + ')' : '')
+                : 'Не привязан';
+            btn.textContent = linked ? 'Отвязать' : 'Привязать Telegram';
+        } catch (error) {
+            console.error('Failed to load Telegram link:', error);
+        }
+    };
+
+    btn.addEventListener('click', async () => {
+        try {
+            if (linked) {
+                await api.delete('/me/telegram');
+                codeEl.classList.add('hidden');
+                await refresh();
+                return;
+            }
+            const code = await api.post('/me/telegram/link-code');
+            codeEl.textContent = '';
+            codeEl.append('Отправьте боту команду /start ' + code.code + ' — код действует 15 минут.');
+            if (code.deep_link) {
+                const a = document.createElement('a');
+                a.href = code.deep_link;
+                a.target = '_blank';
+                a.className = 'block mt-1 font-bold text-primary';
+                a.textContent = 'Открыть бота';
+                codeEl.append(a);
+            }
+            codeEl.classList.remove('hidden');
+        } catch (error) {
+            codeEl.textContent = error.message || 'Не удалось получить код';
+            codeEl.classList.remove('hidden');
+        }
+    });
+
+    await refresh();
+}
+
 function updateToggleStyle(toggle) {
     const label = toggle.nextElementSibling;
     if (label) {
@@ -0,0 +1,66 @@
+document.addEventListener('DOMContentLoaded', async () => {
+    const params = new URLSearchParams(window.location.search);
+    const link = {
+        machine_id: parseInt(params.get('m'), 10),
+        key_id: params.get('k') || '',
+        signature: params.get('s') || ''
+    };
+
+    if (!api.getToken()) {
+        sessionStorage.setItem('netiwash_after_login', window.location.pathname.slice(1) + window.location.search);
+        window.location.replace('index.html');
+        return;
+    }
+
+    const icon = document.getElementById('scanIcon');
+    const title = document.getElementById('scanTitle');
+    const message = document.getElementById('scanMessage');
+    const action = document.getElementById('scanAction');
+
+    const show = (emoji, heading, text) => {
+        icon.classList.remove('animate-pulse');
+        icon.querySelector('span').textContent = emoji;
+        title.textContent = heading;
+        message.textContent = text;
+    };
+
+    const formatTime = (iso) => new Date(iso).toLocaleTimeString('ru-RU', { hour: '2-digit', minute: '2-digit' });
+
+    let result;
+    try {
+        result = await api.get(`/scan?${params.toString()}`);
+    } catch (e) {
+        show('❌', 'Не получилось', e.message);
+        return;
+    }
+
+    const machine = result.machine;
+    if (result.action === 'check_in') {
+        show('👋', machine.name, `Ваша бронь до ${formatTime(result.booking.end_time)}. Отметьтесь, что вы на месте.`);
+        action.textContent = 'Я на месте';
+    } else if (result.action === 'book') {
+        show('🧺', machine.name, 'Машинка свободна. Можно занять её прямо сейчас на час.');
+        action.textContent = 'Занять сейчас';
+    } else {
+        const until = machine.next_free_at ? ` Освободится около ${formatTime(machine.next_free_at)}.` : '';
+        show('⛔', machine.name, `Сейчас машинка недоступна.${until}`);
+        return;
+    }
+
+    action.classList.remove('hidden');
+    action.onclick = async () => {
+        action.disabled = true;
+        try {
+            const done = await api.post('/scan/check-in', link);
+            action.classList.add('hidden');
+            if (result.action === 'check_in') {
+                show('✅', 'Готово', 'Вы отметились. Хорошей стирки!');
+            } else {
+                show('✅', 'Забронировано', `Машинка ваша до ${formatTime(done.booking.end_time)}.`);
+            }
+        } catch (e) {
+            action.disabled = false;
+            show('❌', 'Не получилось', e.message);
+        }
+    };
+});
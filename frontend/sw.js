@@ -85,16 +85,24 @@ self.addEventListener('push', function (event) {
 
         console.log('[SW] Push Received:', payload);
 
+        const actions = payload.actions || [];
         const options = {
             body: payload.body || 'Новое уведомление',
-            icon: '/icons/icon-192x192.png',
+            icon: payload.icon || '/icons/icon-192x192.png',
             badge: '/icons/icon-192x192.png',
             vibrate: [100, 50, 100],
+            actions: actions.map((a) => ({ action: a.action, title: a.title })),
             data: {
                 dateOfArrival: Date.now(),
-                url: payload.url || 'bookings.html'
+                url: payload.url || 'bookings.html',
+                // Токены кнопок: по ним бэкенд выполняет действие без JWT
+                tokens: Object.fromEntries(actions.map((a) => [a.action, a.token]))
             }
         };
+        if (payload.tag) {
+            options.tag = payload.tag;
+            options.renotify = true;
+        }
 
         const title = payload.title || 'NETI WASH';
 
@@ -105,9 +113,26 @@ self.addEventListener('push', function (event) {
 });
 
 self.addEventListener('notificationclick', function (event) {
-    console.log('[SW] Notification click Received.');
+    console.log('[SW] Notification click Received:', event.action);
     event.notification.close();
+
+    const data = event.notification.data || {};
+    const url = data.url || 'bookings.html';
+    const token = event.action && data.tokens ? data.tokens[event.action] : null;
+
+    if (!token) {
+        event.waitUntil(clients.openWindow(url));
+        return;
+    }
+
     event.waitUntil(
-        clients.openWindow('bookings.html')
+        fetch('/api/notifications/actions', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ token })
+        }).then((res) => {
+            // Если действие не удалось, показываем страницу с бронями
+            if (!res.ok) return clients.openWindow(url);
+        }).catch(() => clients.openWindow(url))
     );
 });